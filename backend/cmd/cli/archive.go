@@ -0,0 +1,97 @@
+package main
+
+import (
+	"algolearn/internal/service"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+// newStorageService connects to the bucket course media is kept in.
+func newStorageService() service.StorageService {
+	storage, err := service.NewStorageService(
+		os.Getenv("SPACES_ACCESS_KEY"),
+		os.Getenv("SPACES_SECRET_KEY"),
+		os.Getenv("SPACES_REGION"),
+		os.Getenv("SPACES_ENDPOINT"),
+		os.Getenv("SPACES_BUCKET_NAME"),
+		os.Getenv("SPACES_CDN_URL"),
+	)
+	if err != nil {
+		log.Fatalf("failed to initialize storage service: %v", err)
+	}
+	return storage
+}
+
+// exportCourse writes a course archive to a file.
+//
+//	export-course COURSE_ID FILE
+func exportCourse(db *sql.DB, args []string) {
+	if len(args) != 2 {
+		log.Fatal("Usage: export-course COURSE_ID FILE")
+	}
+	courseID, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil || courseID <= 0 {
+		log.Fatal("Invalid course ID argument")
+	}
+
+	f, err := os.Create(args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	archives := service.NewCourseArchiveService(db, newStorageService())
+	if err := archives.ExportCourse(context.Background(), int32(courseID), f); err != nil {
+		f.Close()
+		os.Remove(args[1])
+		log.Fatalf("failed to export course %d: %v", courseID, err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Exported course %d to %s\n", courseID, args[1])
+}
+
+// importCourse creates a draft course from an archive file and prints the
+// result, including the new IDs of everything imported.
+//
+//	import-course [-dry-run] -author USER_ID FILE
+func importCourse(db *sql.DB, args []string) {
+	fs := flag.NewFlagSet("import-course", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "validate the archive without importing it")
+	authorID := fs.Int("author", 0, "ID of the user the course is authored by")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *authorID <= 0 {
+		log.Fatal("Usage: import-course [-dry-run] -author USER_ID FILE")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	archives := service.NewCourseArchiveService(db, newStorageService())
+	result, err := archives.ImportCourse(context.Background(), f, info.Size(), int32(*authorID), *dryRun)
+	if err != nil {
+		log.Fatalf("failed to import %s: %v", fs.Arg(0), err)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+}
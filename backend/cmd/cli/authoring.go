@@ -0,0 +1,63 @@
+package main
+
+import (
+	"algolearn/internal/authoring"
+	"algolearn/internal/service"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// importMarkdown imports a directory of Markdown module files into a unit,
+// or only checks them with -check.
+//
+//	import-markdown [-check] -unit UNIT_ID DIR
+func importMarkdown(db *sql.DB, args []string) {
+	fs := flag.NewFlagSet("import-markdown", flag.ExitOnError)
+	check := fs.Bool("check", false, "report errors in the files without importing them")
+	unitID := fs.Int64("unit", 0, "ID of the unit the modules belong to")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || (!*check && *unitID <= 0) {
+		log.Fatal("Usage: import-markdown [-check] -unit UNIT_ID DIR")
+	}
+	dir := os.DirFS(fs.Arg(0))
+
+	if *check {
+		modules, err := authoring.ParseDir(dir)
+		if err != nil {
+			exitWithAuthoringErrors(err)
+		}
+		fmt.Printf("%d modules are valid\n", len(modules))
+		return
+	}
+
+	courses := service.NewCourseService(db, nil)
+	modules := service.NewModuleService(db, nil, nil)
+	results, err := authoring.Import(context.Background(), courses, modules, *unitID, dir)
+	if err != nil {
+		exitWithAuthoringErrors(err)
+	}
+
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+}
+
+// exitWithAuthoringErrors prints one error per line, in the file:line form
+// editors can jump to.
+func exitWithAuthoringErrors(err error) {
+	var errs authoring.Errors
+	if errors.As(err, &errs) {
+		fmt.Fprintln(os.Stderr, errs.Error())
+		os.Exit(1)
+	}
+	log.Fatal(err)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"algolearn/internal/service"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+)
+
+// gcMedia deletes the media objects in storage that nothing in the database
+// refers to and prints what was found. Objects modified within the grace
+// period are kept, as their content may not have been saved yet.
+//
+//	gc-media [-dry-run] [-grace DURATION]
+func gcMedia(db *sql.DB, args []string) {
+	fs := flag.NewFlagSet("gc-media", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report orphaned media without deleting it")
+	grace := fs.Duration("grace", 24*time.Hour, "how long to keep orphaned media after it was last modified")
+	fs.Parse(args)
+
+	if fs.NArg() != 0 || *grace < 0 {
+		log.Fatal("Usage: gc-media [-dry-run] [-grace DURATION]")
+	}
+
+	collector := service.NewMediaGCService(db, newStorageService())
+	result, err := collector.CollectOrphanedMedia(context.Background(), *grace, *dryRun)
+	if err != nil {
+		log.Fatalf("failed to collect orphaned media: %v", err)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+}
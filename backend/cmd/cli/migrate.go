@@ -31,6 +31,10 @@ func main() {
 		fmt.Println("\tstatus\t\tDump the migration status")
 		fmt.Println("\tversion\t\tPrint the current version")
 		fmt.Println("\tcreate NAME\tCreate new migration file")
+		fmt.Println("\texport-course COURSE_ID FILE\t\t\tExport a course archive")
+		fmt.Println("\timport-course [-dry-run] -author USER_ID FILE\tImport a course archive")
+		fmt.Println("\timport-markdown [-check] -unit UNIT_ID DIR\tImport Markdown module files into a unit")
+		fmt.Println("\tgc-media [-dry-run] [-grace DURATION]\t\tDelete media no longer referenced by the DB")
 	}
 
 	flag.Parse()
@@ -126,6 +130,14 @@ func main() {
 		if err := goose.Version(db, migrationsDir); err != nil {
 			log.Fatal(err)
 		}
+	case "export-course":
+		exportCourse(db, args)
+	case "import-course":
+		importCourse(db, args)
+	case "import-markdown":
+		importMarkdown(db, args)
+	case "gc-media":
+		gcMedia(db, args)
 	default:
 		flag.Usage()
 		os.Exit(1)
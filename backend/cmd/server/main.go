@@ -6,7 +6,10 @@ import (
 	"algolearn/internal/router"
 	"algolearn/internal/service"
 	"algolearn/pkg/logger"
+	"algolearn/pkg/mailer"
 	"algolearn/pkg/middleware"
+	"algolearn/pkg/sandbox"
+	"algolearn/pkg/security"
 	"context"
 	"database/sql"
 	"fmt"
@@ -21,11 +24,17 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func setupRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
+func setupRouter(cfg *config.Config, db *sql.DB, storageService service.StorageService, streakRepo service.StreakService, sessionRepo service.SessionService, loginAttemptRepo service.LoginAttemptService, trashRepo service.TrashService, notifBroker service.NotificationBroker, rateLimiter *middleware.RateLimiter) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 
 	r := gin.New()
 
+	// Client IPs key sign-in lockouts and rate limits, so X-Forwarded-For is
+	// only believed when it comes from our own proxies.
+	if err := r.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Recovery middleware
 	r.Use(gin.Recovery())
 
@@ -33,43 +42,53 @@ func setupRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowOrigins = []string{"*"}
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "Last-Event-ID", "X-Device-Name"}
+	corsConfig.ExposeHeaders = []string{"Retry-After", "RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"}
 	r.Use(cors.New(corsConfig))
 
 	// Custom middleware
 	r.Use(middleware.Logger())
-	r.Use(middleware.Timeout(10 * time.Second))
+	// Event streams stay open for as long as the client is connected, and
+	// course archives can take minutes to move.
+	r.Use(middleware.Timeout(10*time.Second,
+		"/api/v1/notifications/stream",
+		"/api/v1/courses/:courseId/export",
+		"/api/v1/courses/import"))
 
 	// Initialize repositories
 	userRepo := service.NewUserService(db)
+	accountRepo := service.NewAccountService(db, newMailer(cfg.Mail), cfg.Mail.LinkBaseURL)
+	oauthStateRepo := service.NewOAuthStateService(db)
+	identityRepo := service.NewIdentityService(db)
+	mfaRepo := service.NewMFAService(db)
 	notifRepo := service.NewNotificationsService(db)
-	courseRepo := service.NewCourseService(db)
-	unitRepo := service.NewUnitService(db)
-	moduleRepo := service.NewModuleService(db)
-	achievementsRepo := service.NewAchievementsService(db)
 
-	storageService, err := service.NewStorageService(
-		cfg.Storage.SpacesAccessKey,
-		cfg.Storage.SpacesSecretKey,
-		cfg.Storage.SpacesRegion,
-		cfg.Storage.SpacesEndpoint,
-		cfg.Storage.SpacesBucketName,
-		cfg.Storage.SpacesCDNUrl,
-	)
-	if err != nil {
-		log.Fatalf("Failed to initialize storage service: %v", err)
-	}
+	courseRepo := service.NewCourseService(db, storageService)
+	courseVersionRepo := service.NewCourseVersionService(db, storageService)
+	unitRepo := service.NewUnitService(db, storageService)
+	moduleRepo := service.NewModuleService(db, newCodeRunner(cfg.CodeRunner), storageService)
+	achievementsRepo := service.NewAchievementsService(db)
+	reviewRepo := service.NewReviewService(db)
+	courseArchiveRepo := service.NewCourseArchiveService(db, storageService)
 
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userRepo)
-	oauthHandler := handlers.NewOauthHandler(userRepo)
-	notifHandler := handlers.NewNotificationsHandler(notifRepo)
-	courseHandler := handlers.NewCourseHandler(courseRepo, userRepo)
-	unitHandler := handlers.NewUnitHandler(unitRepo)
-	moduleHandler := handlers.NewModuleHandler(moduleRepo, userRepo)
+	userHandler := handlers.NewUserHandler(userRepo, sessionRepo, accountRepo, loginAttemptRepo, mfaRepo, rateLimiter)
+	oauthHandler := handlers.NewOauthHandler(userRepo, oauthStateRepo, identityRepo, mfaRepo)
+	identityHandler := handlers.NewIdentityHandler(identityRepo, oauthStateRepo)
+	mfaHandler := handlers.NewMFAHandler(mfaRepo)
+	notifHandler := handlers.NewNotificationsHandler(notifRepo, notifBroker)
+	courseHandler := handlers.NewCourseHandler(courseRepo, userRepo, courseVersionRepo, rateLimiter)
+	unitHandler := handlers.NewUnitHandler(unitRepo, courseRepo, courseVersionRepo)
+	moduleHandler := handlers.NewModuleHandler(moduleRepo, courseRepo, rateLimiter)
 	achievementsHandler := handlers.NewAchievementsHandler(achievementsRepo)
+	streakHandler := handlers.NewStreakHandler(streakRepo)
+	reviewHandler := handlers.NewReviewHandler(reviewRepo)
 	adminHandler, err := handlers.NewAdminHandler(userRepo, courseRepo)
-	uploadHandler := handlers.NewUploadHandler(storageService)
+	uploadHandler := handlers.NewUploadHandler(storageService, courseRepo, rateLimiter)
+	courseArchiveHandler := handlers.NewCourseArchiveHandler(courseArchiveRepo)
+	courseVersionHandler := handlers.NewCourseVersionHandler(courseVersionRepo)
+	trashHandler := handlers.NewTrashHandler(trashRepo)
+	jwksHandler := handlers.NewJWKSHandler(security.GetKeySet())
 	if err != nil {
 		log.Fatalf("Failed to initialize admin handler: %v", err)
 	}
@@ -80,15 +99,39 @@ func setupRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 		unitHandler,
 		moduleHandler,
 		oauthHandler,
+		identityHandler,
+		mfaHandler,
 		notifHandler,
 		achievementsHandler,
+		streakHandler,
+		reviewHandler,
 		adminHandler,
 		uploadHandler,
+		courseArchiveHandler,
+		courseVersionHandler,
+		trashHandler,
+		jwksHandler,
 	)
 
 	return r
 }
 
+func newMailer(cfg config.MailConfig) mailer.Mailer {
+	if cfg.Driver == "smtp" {
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	}
+	return mailer.NewFileMailer(cfg.OutboxDir, cfg.From)
+}
+
+func newCodeRunner(cfg config.CodeRunnerConfig) sandbox.Runner {
+	if cfg.Driver == "local" {
+		logger.Get().WithBaseFields(logger.Main, "newCodeRunner").Warn(
+			"CODE_RUNNER=local runs learners' code as the server's user without isolation; never use it outside development")
+		return sandbox.NewLocalRunner()
+	}
+	return nil
+}
+
 func main() {
 	log := logger.Get().WithBaseFields(logger.Main, "main")
 	log.Info("Starting application...")
@@ -107,6 +150,10 @@ func main() {
 		}
 	}()
 
+	if err := security.InitKeySet(cfg.Auth.JWTKeysDir, cfg.Auth.JWTSigningKeyID); err != nil {
+		log.Fatalf("failed to load JWT keys: %v", err)
+	}
+
 	config.InitOAuth(cfg.OAuth)
 	config.InitS3(cfg.Storage)
 	migrator, err := config.NewMigrator(&cfg.Database)
@@ -118,8 +165,51 @@ func main() {
 		log.Fatalf("Failed to apply migrations: %v", err)
 	}
 
+	storageService, err := service.NewStorageService(
+		cfg.Storage.SpacesAccessKey,
+		cfg.Storage.SpacesSecretKey,
+		cfg.Storage.SpacesRegion,
+		cfg.Storage.SpacesEndpoint,
+		cfg.Storage.SpacesBucketName,
+		cfg.Storage.SpacesCDNUrl,
+	)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage service: %v", err)
+	}
+
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	streakRepo := service.NewStreakService(config.GetDB())
+	go streakRepo.RunCloseBrokenStreaksJob(jobsCtx, time.Hour)
+
+	sessionRepo := service.NewSessionService(config.GetDB())
+	go sessionRepo.RunPurgeExpiredRefreshTokensJob(jobsCtx, 24*time.Hour)
+
+	loginAttemptRepo := service.NewLoginAttemptService(config.GetDB(), cfg.Auth.MaxLoginFailures, cfg.Auth.MaxIPLoginFailures, cfg.Auth.LoginLockout)
+	go loginAttemptRepo.RunPurgeStaleLoginAttemptsJob(jobsCtx, time.Hour)
+
+	trashRepo := service.NewTrashService(config.GetDB(), storageService, cfg.Trash.Retention)
+	go trashRepo.RunPurgeTrashJob(jobsCtx, time.Hour)
+
+	var rateLimitStore middleware.RateLimitStore = middleware.NewMemoryRateLimitStore()
+	if cfg.RateLimit.Store == "postgres" {
+		rateLimitRepo := service.NewRateLimitService(config.GetDB())
+		go rateLimitRepo.RunPurgeFullRateLimitBucketsJob(jobsCtx, time.Hour)
+		rateLimitStore = rateLimitRepo
+	}
+	rateLimiter := middleware.NewRateLimiter(rateLimitStore)
+
+	notifBroker := service.NewNotificationBroker(config.GetDB())
+	go func() {
+		if err := notifBroker.Listen(jobsCtx, cfg.Database.ConnString()); err != nil {
+			log.WithError(err).Error("notification broker stopped")
+		}
+	}()
+
 	// Setup router
-	r := setupRouter(cfg, config.GetDB())
+	r := setupRouter(cfg, config.GetDB(), storageService, streakRepo, sessionRepo, loginAttemptRepo, trashRepo, notifBroker, rateLimiter)
 
 	// Create server with timeouts
 	addr := fmt.Sprintf(":%s", cfg.Port)
@@ -144,6 +234,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Info("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
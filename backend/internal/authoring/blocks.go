@@ -0,0 +1,97 @@
+package authoring
+
+import "algolearn/internal/models"
+
+// question is a question block. Fields are named as in the JSON API; type
+// defaults to multiple_choice.
+//
+//	```question
+//	question: What does binary search need?
+//	options:
+//	  - content: A sorted array
+//	    isCorrect: true
+//	  - content: A linked list
+//	    feedback: Linked lists cannot be indexed in constant time.
+//	explanation: Each step halves a sorted range.
+//	tags: [searching]
+//	```
+type question struct {
+	Type        models.QuestionType   `yaml:"type"`
+	Question    string                `yaml:"question"`
+	Options     []option              `yaml:"options"`
+	Blanks      []blank               `yaml:"blanks"`
+	Numeric     *models.NumericAnswer `yaml:"numeric"`
+	Explanation string                `yaml:"explanation"`
+	Tags        []string              `yaml:"tags"`
+}
+
+type option struct {
+	Content   string `yaml:"content"`
+	IsCorrect bool   `yaml:"isCorrect"`
+	Feedback  string `yaml:"feedback"`
+}
+
+type blank struct {
+	AcceptedAnswers []string `yaml:"acceptedAnswers"`
+	Patterns        []string `yaml:"patterns"`
+	CaseSensitive   bool     `yaml:"caseSensitive"`
+}
+
+func (q *question) content() *models.QuestionContent {
+	content := &models.QuestionContent{
+		Question:    q.Question,
+		Type:        q.Type,
+		Options:     make([]models.Option, len(q.Options)),
+		Numeric:     q.Numeric,
+		Explanation: q.Explanation,
+		Tags:        q.Tags,
+	}
+	if content.Type == "" {
+		content.Type = models.QuestionTypeMultipleChoice
+	}
+	if content.Tags == nil {
+		content.Tags = []string{}
+	}
+	for i, opt := range q.Options {
+		content.Options[i] = models.Option{
+			Content:   opt.Content,
+			IsCorrect: opt.IsCorrect,
+			Feedback:  opt.Feedback,
+		}
+	}
+	for _, b := range q.Blanks {
+		content.Blanks = append(content.Blanks, models.Blank{
+			AcceptedAnswers: b.AcceptedAnswers,
+			Patterns:        b.Patterns,
+			CaseSensitive:   b.CaseSensitive,
+		})
+	}
+	return content
+}
+
+type image struct {
+	URL      string `yaml:"url"`
+	Width    int    `yaml:"width"`
+	Height   int    `yaml:"height"`
+	AltText  string `yaml:"altText"`
+	Headline string `yaml:"headline"`
+	Caption  string `yaml:"caption"`
+	Source   string `yaml:"source"`
+}
+
+type video struct {
+	URL string `yaml:"url"`
+}
+
+// lottie is a lottie block. Speed defaults to 1.
+type lottie struct {
+	Caption     string  `yaml:"caption"`
+	Description string  `yaml:"description"`
+	Width       int     `yaml:"width"`
+	Height      int     `yaml:"height"`
+	AltText     string  `yaml:"altText"`
+	FallbackURL string  `yaml:"fallbackUrl"`
+	Autoplay    bool    `yaml:"autoplay"`
+	Loop        bool    `yaml:"loop"`
+	Speed       float32 `yaml:"speed"`
+}
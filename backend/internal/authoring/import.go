@@ -0,0 +1,78 @@
+package authoring
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/google/uuid"
+)
+
+// ErrSnapshotUnit is returned when importing into a unit of a published
+// version of a course, which is never edited.
+var ErrSnapshotUnit = errors.New("unit belongs to a published version of a course")
+
+// ImportResult is what happened to one module file.
+type ImportResult struct {
+	File         string `json:"file"`
+	ModuleID     int64  `json:"moduleId"`
+	ModuleNumber int16  `json:"moduleNumber"`
+	Sections     int    `json:"sections"`
+	Created      bool   `json:"created"`
+}
+
+// Import reads the modules in fsys into a unit. Modules whose number is not
+// in the unit yet are created; existing ones get the file's name and
+// description and their sections are updated in place where the file has a
+// section of the same type at the same position, so learner progress through
+// them is kept. Nothing is written if any file has errors or the unit belongs
+// to a published version. Each module is written in its own transaction, so
+// an error from the database can leave earlier modules imported.
+func Import(ctx context.Context, courses service.CourseService, modules service.ModuleService, unitID int64, fsys fs.FS) ([]ImportResult, error) {
+	parsed, err := ParseDir(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	isSnapshot, err := courses.IsUnitSnapshot(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+	if isSnapshot {
+		return nil, ErrSnapshotUnit
+	}
+
+	results := make([]ImportResult, 0, len(parsed))
+	for _, m := range parsed {
+		result := ImportResult{
+			File:         m.File,
+			ModuleNumber: m.Module.ModuleNumber,
+			Sections:     len(m.Sections),
+		}
+
+		existing, err := modules.GetModuleByNumber(ctx, unitID, int32(m.Module.ModuleNumber))
+		switch {
+		case errors.Is(err, httperr.ErrNotFound):
+			created, err := modules.CreateModuleWithContent(ctx, unitID, m.Module.Name, m.Module.Description, int32(m.Module.ModuleNumber), uuid.NullUUID{}, uuid.NullUUID{}, m.Sections)
+			if err != nil {
+				return results, fmt.Errorf("%s: %w", m.File, err)
+			}
+			result.ModuleID = created.ID
+			result.Created = true
+		case err != nil:
+			return results, fmt.Errorf("%s: %w", m.File, err)
+		default:
+			if _, err := modules.ReplaceModuleContent(ctx, existing.ID, m.Module.Name, m.Module.Description, m.Sections); err != nil {
+				return results, fmt.Errorf("%s: %w", m.File, err)
+			}
+			result.ModuleID = existing.ID
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
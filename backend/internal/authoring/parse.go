@@ -0,0 +1,373 @@
+// Package authoring reads modules written as Markdown files.
+//
+// A unit is a directory with one file per module, named after the module
+// number, such as 01-two-pointers.md. Files that do not start with a number
+// are ignored. Each file opens with YAML front matter:
+//
+//	---
+//	name: Two pointers
+//	description: Walking an array from both ends
+//	---
+//
+// The rest of the file becomes the module's sections, in order. Fenced blocks
+// whose info string is question, image, video or lottie hold the YAML content
+// of that kind of section, and fenced blocks with any other info string are
+// code sections in that language. Text between blocks, including fenced
+// blocks without an info string, becomes markdown sections.
+package authoring
+
+import (
+	"algolearn/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a problem at a line of a module file.
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Errors are the problems found in a directory, in file and line order.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Module is a module read from a file, with its sections numbered from 1.
+// Only the name, description and module number of Module are set.
+type Module struct {
+	File     string
+	Module   models.Module
+	Sections []models.Section
+}
+
+var (
+	moduleFileRe = regexp.MustCompile(`^(\d+)[^/]*\.md$`)
+	fenceRe      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^\\s`]*)")
+	yamlLineRe   = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownKeyRe = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// ParseDir reads the module files at the root of fsys. All files are read
+// before it returns, so the Errors it returns cover the whole directory.
+func ParseDir(fsys fs.FS) ([]Module, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		modules []Module
+		errs    Errors
+		numbers = map[int32]string{}
+	)
+	for _, entry := range entries {
+		m := moduleFileRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+
+		number, err := strconv.ParseInt(m[1], 10, 16)
+		if err != nil || number < 1 {
+			errs = append(errs, &Error{File: entry.Name(), Line: 1, Msg: "module number must be between 1 and 32767"})
+			continue
+		}
+		if other, ok := numbers[int32(number)]; ok {
+			errs = append(errs, &Error{File: entry.Name(), Line: 1, Msg: fmt.Sprintf("module number %d is also used by %s", number, other)})
+			continue
+		}
+		numbers[int32(number)] = entry.Name()
+
+		src, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		module, fileErrs := ParseFile(entry.Name(), src)
+		if len(fileErrs) > 0 {
+			errs = append(errs, fileErrs...)
+			continue
+		}
+		module.Module.ModuleNumber = int16(number)
+		modules = append(modules, *module)
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool {
+			if errs[i].File != errs[j].File {
+				return errs[i].File < errs[j].File
+			}
+			return errs[i].Line < errs[j].Line
+		})
+		return nil, errs
+	}
+	return modules, nil
+}
+
+// ParseFile reads one module file. The module number is left for the caller
+// to set.
+func ParseFile(name string, src []byte) (*Module, Errors) {
+	p := &parser{file: name, lines: strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")}
+
+	var front frontMatter
+	body := p.parseFrontMatter(&front)
+	if body < 0 {
+		return nil, p.errs
+	}
+	if strings.TrimSpace(front.Name) == "" {
+		p.errorf(2, "name is required")
+	}
+
+	p.parseBody(body)
+	if len(p.errs) > 0 {
+		return nil, p.errs
+	}
+
+	return &Module{
+		File: name,
+		Module: models.Module{
+			Name:        front.Name,
+			Description: front.Description,
+		},
+		Sections: p.sections,
+	}, nil
+}
+
+type frontMatter struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+type parser struct {
+	file     string
+	lines    []string
+	sections []models.Section
+	errs     Errors
+}
+
+func (p *parser) errorf(line int, format string, args ...any) {
+	p.errs = append(p.errs, &Error{File: p.file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// parseFrontMatter decodes the front matter into front and returns the index
+// of the first line after it, or -1 if there is none.
+func (p *parser) parseFrontMatter(front *frontMatter) int {
+	if strings.TrimSpace(p.lines[0]) != "---" {
+		p.errorf(1, "file must start with front matter between --- lines")
+		return -1
+	}
+	for i := 1; i < len(p.lines); i++ {
+		if strings.TrimSpace(p.lines[i]) == "---" {
+			p.decodeYAML(p.lines[1:i], 2, front)
+			return i + 1
+		}
+	}
+	p.errorf(1, "front matter is not closed by a --- line")
+	return -1
+}
+
+// parseBody turns the lines from start on into sections.
+func (p *parser) parseBody(start int) {
+	var prose []string
+	proseStart := start + 1
+
+	flushProse := func() {
+		text := strings.TrimSpace(strings.Join(prose, "\n"))
+		if text != "" {
+			p.addSection(proseStart, models.SectionTypeMarkdown, models.MarkdownContent{Markdown: text})
+		}
+		prose = nil
+	}
+
+	for i := start; i < len(p.lines); i++ {
+		m := fenceRe.FindStringSubmatch(p.lines[i])
+		if m == nil {
+			if len(prose) == 0 {
+				proseStart = i + 1
+			}
+			prose = append(prose, p.lines[i])
+			continue
+		}
+
+		end := closingFence(p.lines, i+1, m[1])
+		if end < 0 {
+			p.errorf(i+1, "code fence is not closed")
+			return
+		}
+
+		info := m[2]
+		if info == "" {
+			if len(prose) == 0 {
+				proseStart = i + 1
+			}
+			prose = append(prose, p.lines[i:end+1]...)
+			i = end
+			continue
+		}
+
+		flushProse()
+		p.parseBlock(i+1, info, p.lines[i+1:end])
+		i = end
+	}
+	flushProse()
+
+	if len(p.sections) == 0 && len(p.errs) == 0 {
+		p.errorf(len(p.lines), "module has no sections")
+	}
+}
+
+// closingFence returns the index of the line closing a fence opened with
+// fence, looking from line from on, or -1.
+func closingFence(lines []string, from int, fence string) int {
+	for i := from; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " ")
+		if len(lines[i])-len(line) > 3 {
+			continue
+		}
+		trimmed := strings.TrimRight(line, " \t")
+		if len(trimmed) >= len(fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseBlock turns a fenced block opened at line into a section.
+func (p *parser) parseBlock(line int, info string, body []string) {
+	first := line + 1
+
+	switch info {
+	case "question":
+		var q question
+		if !p.decodeYAML(body, first, &q) {
+			return
+		}
+		content := q.content()
+		if err := content.Validate(); err != nil {
+			p.errorf(line, "%s", err)
+			return
+		}
+		p.addSection(line, models.SectionTypeQuestion, content)
+
+	case "image":
+		var img image
+		if !p.decodeYAML(body, first, &img) {
+			return
+		}
+		if img.URL == "" {
+			p.errorf(line, "image url is required")
+			return
+		}
+		p.addSection(line, models.SectionTypeImage, models.ImageContent{
+			URL:      img.URL,
+			Width:    img.Width,
+			Height:   img.Height,
+			AltText:  img.AltText,
+			Headline: img.Headline,
+			Caption:  img.Caption,
+			Source:   img.Source,
+		})
+
+	case "video":
+		var v video
+		if !p.decodeYAML(body, first, &v) {
+			return
+		}
+		if v.URL == "" {
+			p.errorf(line, "video url is required")
+			return
+		}
+		p.addSection(line, models.SectionTypeVideo, models.VideoContent{URL: v.URL})
+
+	case "lottie":
+		var l lottie
+		if !p.decodeYAML(body, first, &l) {
+			return
+		}
+		if l.FallbackURL == "" {
+			p.errorf(line, "lottie fallbackUrl is required")
+			return
+		}
+		if l.Speed == 0 {
+			l.Speed = 1
+		}
+		p.addSection(line, models.SectionTypeLottie, models.LottieContent{
+			Caption:     l.Caption,
+			Description: l.Description,
+			Width:       l.Width,
+			Height:      l.Height,
+			AltText:     l.AltText,
+			FallbackURL: l.FallbackURL,
+			Autoplay:    l.Autoplay,
+			Loop:        l.Loop,
+			Speed:       l.Speed,
+		})
+
+	default:
+		p.addSection(line, models.SectionTypeCode, models.CodeContent{
+			Code:     strings.Join(body, "\n"),
+			Language: info,
+		})
+	}
+}
+
+func (p *parser) addSection(line int, sectionType models.SectionType, content any) {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		p.errorf(line, "failed to encode section: %s", err)
+		return
+	}
+	p.sections = append(p.sections, models.Section{
+		Type:     sectionType,
+		Position: int16(len(p.sections) + 1),
+		Content:  raw,
+	})
+}
+
+// decodeYAML decodes lines, the first of which is line first of the file,
+// into v. Unknown fields are errors. It reports whether decoding succeeded.
+func (p *parser) decodeYAML(lines []string, first int, v any) bool {
+	dec := yaml.NewDecoder(strings.NewReader(strings.Join(lines, "\n")))
+	dec.KnownFields(true)
+
+	err := dec.Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	msgs := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	}
+	for _, msg := range msgs {
+		line := first
+		if m := yamlLineRe.FindStringSubmatch(msg); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			line, msg = first+n-1, m[2]
+		}
+		msg = unknownKeyRe.ReplaceAllString(msg, "unknown field $1")
+		p.errorf(line, "%s", strings.TrimPrefix(msg, "yaml: "))
+	}
+	return false
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -111,16 +113,61 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("SPACES_CDN_URL environment variable is required")
 	}
 
-	jwtSecretKey := os.Getenv("JWT_SECRET_KEY")
-	if jwtSecretKey == "" {
-		return nil, fmt.Errorf("JWT_SECRET_KEY environment variable is required")
+	jwtKeysDir := os.Getenv("JWT_KEYS_DIR")
+	if jwtKeysDir == "" {
+		return nil, fmt.Errorf("JWT_KEYS_DIR environment variable is required")
+	}
+
+	jwtSigningKeyID := os.Getenv("JWT_SIGNING_KEY_ID")
+	if jwtSigningKeyID == "" {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_ID environment variable is required")
+	}
+
+	mailDriver := getEnv("MAIL_DRIVER", "file")
+	if mailDriver != "smtp" && mailDriver != "file" {
+		return nil, fmt.Errorf("MAIL_DRIVER must be either smtp or file")
+	}
+
+	maxLoginFailures := getEnvAsInt("LOGIN_MAX_FAILURES", 5)
+	maxIPLoginFailures := getEnvAsInt("LOGIN_MAX_IP_FAILURES", 50)
+	loginLockoutMinutes := getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15)
+	if maxLoginFailures < 1 || maxIPLoginFailures < 1 || loginLockoutMinutes < 1 {
+		return nil, fmt.Errorf("LOGIN_MAX_FAILURES, LOGIN_MAX_IP_FAILURES and LOGIN_LOCKOUT_MINUTES must be positive")
+	}
+
+	rateLimitStore := getEnv("RATE_LIMIT_STORE", "memory")
+	if rateLimitStore != "memory" && rateLimitStore != "postgres" {
+		return nil, fmt.Errorf("RATE_LIMIT_STORE must be either memory or postgres")
+	}
+
+	trashRetentionDays := getEnvAsInt("TRASH_RETENTION_DAYS", 30)
+	if trashRetentionDays < 1 {
+		return nil, fmt.Errorf("TRASH_RETENTION_DAYS must be positive")
+	}
+
+	codeRunner := getEnv("CODE_RUNNER", "none")
+	if codeRunner != "local" && codeRunner != "none" {
+		return nil, fmt.Errorf("CODE_RUNNER must be either local or none")
+	}
+
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+
+	smtpHost := os.Getenv("SMTP_HOST")
+	if mailDriver == "smtp" && smtpHost == "" {
+		return nil, fmt.Errorf("SMTP_HOST environment variable is required when MAIL_DRIVER is smtp")
 	}
 
 	cfg := &Config{
 		Port: port,
 		App: AppConfig{
-			Environment: os.Getenv("ENVIRONMENT"),
-			LogLevel:    os.Getenv("LOG_LEVEL"),
+			Environment:    os.Getenv("ENVIRONMENT"),
+			LogLevel:       os.Getenv("LOG_LEVEL"),
+			TrustedProxies: trustedProxies,
 		},
 		Database: DatabaseConfig{
 			Host:          dbHost,
@@ -153,22 +200,48 @@ func Load() (*Config, error) {
 			SpacesCDNUrl:     spacesCDNUrl,
 		},
 		Auth: AuthConfig{
-			JWTSecretKey: jwtSecretKey,
+			JWTKeysDir:         jwtKeysDir,
+			JWTSigningKeyID:    jwtSigningKeyID,
+			MaxLoginFailures:   maxLoginFailures,
+			MaxIPLoginFailures: maxIPLoginFailures,
+			LoginLockout:       time.Duration(loginLockoutMinutes) * time.Minute,
+		},
+		Mail: MailConfig{
+			Driver:       mailDriver,
+			From:         getEnv("MAIL_FROM", "AlgoLearn <no-reply@algolearn.app>"),
+			SMTPHost:     smtpHost,
+			SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername: os.Getenv("SMTP_USERNAME"),
+			SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+			OutboxDir:    os.Getenv("MAIL_OUTBOX_DIR"),
+			LinkBaseURL:  getEnv("MAIL_LINK_BASE_URL", "app.algolearn://"),
+		},
+		RateLimit: RateLimitConfig{
+			Store: rateLimitStore,
+		},
+		CodeRunner: CodeRunnerConfig{
+			Driver: codeRunner,
+		},
+		Trash: TrashConfig{
+			Retention: time.Duration(trashRetentionDays) * 24 * time.Hour,
 		},
 	}
 
 	return cfg, nil
 }
 
+// ConnString returns the lib/pq connection string for the database.
+func (cfg DatabaseConfig) ConnString() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
+	)
+}
+
 func InitDB(cfg DatabaseConfig) {
 	log := logger.Get()
 	var err error
 
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
-	)
-
-	db, err = sql.Open("postgres", connStr)
+	db, err = sql.Open("postgres", cfg.ConnString())
 	if err != nil {
 		log.Fatalf("Error opening database: %v\n", err)
 	}
@@ -1,9 +1,14 @@
 package config
 
+import "time"
+
 // AppConfig holds application-level settings
 type AppConfig struct {
 	Environment string
 	LogLevel    string
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies whose
+	// X-Forwarded-For headers name the client; none are trusted when empty
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds database connection settings
@@ -41,16 +46,66 @@ type StorageConfig struct {
 	SpacesCDNUrl     string
 }
 
+// MailConfig holds outgoing email settings
+type MailConfig struct {
+	// Driver is "smtp" or "file"; the file driver is for local development
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// OutboxDir is where the file driver writes messages; empty only logs them
+	OutboxDir string
+	// LinkBaseURL prefixes the links sent in emails, e.g. the app's URL scheme
+	LinkBaseURL string
+}
+
+// RateLimitConfig holds request rate limiting settings
+type RateLimitConfig struct {
+	// Store is "memory" for a single node or "postgres" to share limits
+	// across nodes
+	Store string
+}
+
+// CodeRunnerConfig holds settings for running learners' exercise code
+type CodeRunnerConfig struct {
+	// Driver is "none", the default, to turn exercise submissions off or
+	// "local" to run code as child processes, which is only safe for
+	// development
+	Driver string
+}
+
+// TrashConfig holds settings for deleted course content
+type TrashConfig struct {
+	// Retention is how long deleted courses, units and modules can be
+	// restored before they are purged
+	Retention time.Duration
+}
+
 // Config holds all application configuration
 type Config struct {
-	Port     string
-	App      AppConfig
-	Database DatabaseConfig
-	OAuth    OAuthConfig
-	Storage  StorageConfig
-	Auth     AuthConfig
+	Port       string
+	App        AppConfig
+	Database   DatabaseConfig
+	OAuth      OAuthConfig
+	Storage    StorageConfig
+	Auth       AuthConfig
+	Mail       MailConfig
+	RateLimit  RateLimitConfig
+	CodeRunner CodeRunnerConfig
+	Trash      TrashConfig
 }
 
 type AuthConfig struct {
-	JWTSecretKey string
+	// JWTKeysDir holds one <kid>.pem file per JWT key
+	JWTKeysDir string
+	// JWTSigningKeyID names the key in JWTKeysDir that signs new tokens
+	JWTSigningKeyID string
+	// MaxLoginFailures is how many failed sign-ins an account may have before
+	// it is locked; MaxIPLoginFailures is the same limit per client IP
+	MaxLoginFailures   int
+	MaxIPLoginFailures int
+	// LoginLockout is how long a locked account or IP has to wait
+	LoginLockout time.Duration
 }
@@ -7,22 +7,112 @@ package gen
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const addUserCpus = `-- name: AddUserCpus :exec
+UPDATE users
+SET cpus = cpus + $1::int
+WHERE id = $2::int
+`
+
+type AddUserCpusParams struct {
+	Points int32 `json:"points"`
+	UserID int32 `json:"userId"`
+}
+
+func (q *Queries) AddUserCpus(ctx context.Context, arg AddUserCpusParams) error {
+	_, err := q.db.ExecContext(ctx, addUserCpus, arg.Points, arg.UserID)
+	return err
+}
+
+const countCompletedCourses = `-- name: CountCompletedCourses :one
+SELECT COUNT(*)
+FROM user_courses uc
+JOIN courses c ON c.id = uc.course_id
+WHERE uc.user_id = $1::int
+    AND uc.progress >= 100
+    AND c.deleted_at IS NULL
+`
+
+func (q *Queries) CountCompletedCourses(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompletedCourses, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCompletedModules = `-- name: CountCompletedModules :one
+SELECT COUNT(*)
+FROM user_module_progress ump
+JOIN modules m ON m.id = ump.module_id
+JOIN units u ON u.id = m.unit_id
+JOIN courses c ON c.id = u.course_id
+WHERE ump.user_id = $1::int
+    AND ump.status = 'completed'
+    AND m.deleted_at IS NULL
+    AND u.deleted_at IS NULL
+    AND c.deleted_at IS NULL
+`
+
+func (q *Queries) CountCompletedModules(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompletedModules, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPerfectQuizzes = `-- name: CountPerfectQuizzes :one
+SELECT COUNT(*)
+FROM (
+    SELECT ump.id
+    FROM user_module_progress ump
+    JOIN modules m ON m.id = ump.module_id
+    JOIN units u ON u.id = m.unit_id
+    JOIN courses c ON c.id = u.course_id
+    JOIN sections s ON s.module_id = ump.module_id AND s.type = 'question'
+    JOIN question_sections qs ON qs.section_id = s.id
+    LEFT JOIN user_question_answers uqa ON uqa.user_module_progress_id = ump.id
+        AND uqa.question_id = qs.question_id
+    WHERE ump.user_id = $1::int
+        AND m.deleted_at IS NULL
+        AND u.deleted_at IS NULL
+        AND c.deleted_at IS NULL
+    GROUP BY ump.id
+    HAVING COUNT(qs.question_id) = COUNT(CASE WHEN uqa.is_correct THEN 1 END)
+) perfect_modules
+`
+
+func (q *Queries) CountPerfectQuizzes(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPerfectQuizzes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAchievement = `-- name: CreateAchievement :one
 INSERT INTO
-    achievements (name, description, points)
-VALUES ($1, $2, $3) RETURNING id, created_at, updated_at, draft, name, description, points
+    achievements (name, description, points, criteria, threshold)
+VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at, draft, name, description, points, criteria, threshold
 `
 
 type CreateAchievementParams struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Points      int32  `json:"points"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Points      int32          `json:"points"`
+	Criteria    sql.NullString `json:"criteria"`
+	Threshold   int32          `json:"threshold"`
 }
 
 func (q *Queries) CreateAchievement(ctx context.Context, arg CreateAchievementParams) (Achievement, error) {
-	row := q.db.QueryRowContext(ctx, createAchievement, arg.Name, arg.Description, arg.Points)
+	row := q.db.QueryRowContext(ctx, createAchievement,
+		arg.Name,
+		arg.Description,
+		arg.Points,
+		arg.Criteria,
+		arg.Threshold,
+	)
 	var i Achievement
 	err := row.Scan(
 		&i.ID,
@@ -32,6 +122,8 @@ func (q *Queries) CreateAchievement(ctx context.Context, arg CreateAchievementPa
 		&i.Name,
 		&i.Description,
 		&i.Points,
+		&i.Criteria,
+		&i.Threshold,
 	)
 	return i, err
 }
@@ -46,7 +138,7 @@ func (q *Queries) DeleteAchievement(ctx context.Context, id int32) error {
 }
 
 const getAchievementByID = `-- name: GetAchievementByID :one
-SELECT id, created_at, updated_at, draft, name, description, points FROM achievements WHERE id = $1 LIMIT 1
+SELECT id, created_at, updated_at, draft, name, description, points, criteria, threshold FROM achievements WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetAchievementByID(ctx context.Context, id int32) (Achievement, error) {
@@ -60,6 +152,8 @@ func (q *Queries) GetAchievementByID(ctx context.Context, id int32) (Achievement
 		&i.Name,
 		&i.Description,
 		&i.Points,
+		&i.Criteria,
+		&i.Threshold,
 	)
 	return i, err
 }
@@ -76,7 +170,7 @@ func (q *Queries) GetAchievementsCount(ctx context.Context) (int64, error) {
 }
 
 const getAllAchievements = `-- name: GetAllAchievements :many
-SELECT id, created_at, updated_at, draft, name, description, points FROM achievements
+SELECT id, created_at, updated_at, draft, name, description, points, criteria, threshold FROM achievements
 `
 
 func (q *Queries) GetAllAchievements(ctx context.Context) ([]Achievement, error) {
@@ -96,6 +190,8 @@ func (q *Queries) GetAllAchievements(ctx context.Context) ([]Achievement, error)
 			&i.Name,
 			&i.Description,
 			&i.Points,
+			&i.Criteria,
+			&i.Threshold,
 		); err != nil {
 			return nil, err
 		}
@@ -110,22 +206,165 @@ func (q *Queries) GetAllAchievements(ctx context.Context) ([]Achievement, error)
 	return items, nil
 }
 
+const getUnearnedAchievements = `-- name: GetUnearnedAchievements :many
+SELECT a.id, a.created_at, a.updated_at, a.draft, a.name, a.description, a.points, a.criteria, a.threshold
+FROM achievements a
+WHERE a.criteria IS NOT NULL
+    AND NOT EXISTS (
+        SELECT 1
+        FROM user_achievements ua
+        WHERE ua.achievement_id = a.id
+            AND ua.user_id = $1::int
+    )
+ORDER BY a.id
+`
+
+func (q *Queries) GetUnearnedAchievements(ctx context.Context, userID int32) ([]Achievement, error) {
+	rows, err := q.db.QueryContext(ctx, getUnearnedAchievements, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Achievement{}
+	for rows.Next() {
+		var i Achievement
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Draft,
+			&i.Name,
+			&i.Description,
+			&i.Points,
+			&i.Criteria,
+			&i.Threshold,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserAchievements = `-- name: GetUserAchievements :many
+SELECT
+    ua.id,
+    ua.user_id,
+    ua.achievement_id,
+    ua.achieved_at,
+    a.name,
+    a.description,
+    a.points
+FROM user_achievements ua
+JOIN achievements a ON a.id = ua.achievement_id
+WHERE ua.user_id = $1::int
+ORDER BY ua.achieved_at DESC
+`
+
+type GetUserAchievementsRow struct {
+	ID            int32     `json:"id"`
+	UserID        int32     `json:"userId"`
+	AchievementID int32     `json:"achievementId"`
+	AchievedAt    time.Time `json:"achievedAt"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	Points        int32     `json:"points"`
+}
+
+func (q *Queries) GetUserAchievements(ctx context.Context, userID int32) ([]GetUserAchievementsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserAchievements, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserAchievementsRow{}
+	for rows.Next() {
+		var i GetUserAchievementsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.AchievementID,
+			&i.AchievedAt,
+			&i.Name,
+			&i.Description,
+			&i.Points,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserStreakDays = `-- name: GetUserStreakDays :one
+SELECT streak FROM users WHERE id = $1::int
+`
+
+func (q *Queries) GetUserStreakDays(ctx context.Context, userID int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getUserStreakDays, userID)
+	var streak int32
+	err := row.Scan(&streak)
+	return streak, err
+}
+
+const grantUserAchievement = `-- name: GrantUserAchievement :one
+INSERT INTO user_achievements (user_id, achievement_id)
+VALUES ($1::int, $2::int)
+ON CONFLICT ON CONSTRAINT uniq_user_achievement DO NOTHING
+RETURNING id, created_at, updated_at, user_id, achievement_id, achieved_at
+`
+
+type GrantUserAchievementParams struct {
+	UserID        int32 `json:"userId"`
+	AchievementID int32 `json:"achievementId"`
+}
+
+func (q *Queries) GrantUserAchievement(ctx context.Context, arg GrantUserAchievementParams) (UserAchievement, error) {
+	row := q.db.QueryRowContext(ctx, grantUserAchievement, arg.UserID, arg.AchievementID)
+	var i UserAchievement
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.AchievementID,
+		&i.AchievedAt,
+	)
+	return i, err
+}
+
 const updateAchievement = `-- name: UpdateAchievement :one
 UPDATE achievements
 SET
     name = $1,
     description = $2,
     points = $3,
+    criteria = $4,
+    threshold = $5,
     updated_at = CURRENT_TIMESTAMP
 WHERE
-    id = $4 RETURNING id, created_at, updated_at, draft, name, description, points
+    id = $6 RETURNING id, created_at, updated_at, draft, name, description, points, criteria, threshold
 `
 
 type UpdateAchievementParams struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Points      int32  `json:"points"`
-	ID          int32  `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Points      int32          `json:"points"`
+	Criteria    sql.NullString `json:"criteria"`
+	Threshold   int32          `json:"threshold"`
+	ID          int32          `json:"id"`
 }
 
 func (q *Queries) UpdateAchievement(ctx context.Context, arg UpdateAchievementParams) (Achievement, error) {
@@ -133,6 +372,8 @@ func (q *Queries) UpdateAchievement(ctx context.Context, arg UpdateAchievementPa
 		arg.Name,
 		arg.Description,
 		arg.Points,
+		arg.Criteria,
+		arg.Threshold,
 		arg.ID,
 	)
 	var i Achievement
@@ -144,6 +385,8 @@ func (q *Queries) UpdateAchievement(ctx context.Context, arg UpdateAchievementPa
 		&i.Name,
 		&i.Description,
 		&i.Points,
+		&i.Criteria,
+		&i.Threshold,
 	)
 	return i, err
 }
@@ -185,7 +185,7 @@ func (q *Queries) DeleteUserCourse(ctx context.Context, arg DeleteUserCoursePara
 const getAllCoursesWithOptionalProgress = `-- name: GetAllCoursesWithOptionalProgress :many
 WITH user_progress AS (
     SELECT
-        uc.course_id,
+        COALESCE(cv.course_id, uc.course_id) AS course_id,
         u.id as unit_id,
         u.created_at as unit_created_at,
         u.updated_at as unit_updated_at,
@@ -207,8 +207,9 @@ WITH user_progress AS (
         ump.progress as module_progress,
         ump.status as module_status
     FROM user_courses uc
-             JOIN units u ON u.course_id = uc.course_id
-             JOIN modules m ON m.unit_id = u.id
+             LEFT JOIN course_versions cv ON cv.snapshot_course_id = uc.course_id
+             JOIN units u ON u.course_id = uc.course_id AND u.deleted_at IS NULL
+             JOIN modules m ON m.unit_id = u.id AND m.deleted_at IS NULL
              LEFT JOIN user_module_progress ump ON ump.module_id = m.id
         AND ump.user_id = $5::int
     WHERE uc.user_id = $5::int
@@ -244,10 +245,14 @@ SELECT
     up.module_description,
     COALESCE(up.module_progress, 0) as module_progress,
     COALESCE(up.module_status, 'uninitiated') as module_status,
-   (SELECT COUNT(*) FROM courses) as total_count
+   (SELECT COUNT(*) FROM courses c2
+    WHERE c2.deleted_at IS NULL
+        AND NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c2.id)) as total_count
 FROM (
     SELECT id
-    FROM courses
+    FROM courses c
+    WHERE c.deleted_at IS NULL
+        AND NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id)
     ORDER BY id
     LIMIT $2::int
     OFFSET $1::int
@@ -464,6 +469,7 @@ SELECT
 FROM courses
 WHERE
     id = $1::int
+    AND deleted_at IS NULL
 `
 
 type GetCourseByIDRow struct {
@@ -510,6 +516,7 @@ WITH current_unit_id AS (
     SELECT u.id
     FROM units u
     WHERE u.course_id = $2::int
+        AND u.deleted_at IS NULL
     ORDER BY u.updated_at DESC
     LIMIT 1
 ),
@@ -517,6 +524,7 @@ current_module_id AS (
     SELECT m.id
     FROM modules m
     WHERE m.unit_id = (SELECT id FROM current_unit_id)
+        AND m.deleted_at IS NULL
     ORDER BY m.updated_at DESC
     LIMIT 1
 )
@@ -562,7 +570,7 @@ FROM courses c
          LEFT JOIN modules m ON m.id = cmi.id
          LEFT JOIN user_courses uc ON uc.course_id = c.id AND uc.user_id = $1::int
          LEFT JOIN user_module_progress ump ON ump.module_id = cmi.id AND ump.user_id = $1::int
-WHERE c.id = $2::int
+WHERE c.id = $2::int AND c.deleted_at IS NULL
 `
 
 type GetCourseProgressSummaryBaseParams struct {
@@ -695,6 +703,7 @@ SELECT
 FROM units
 WHERE
     course_id = $1::int
+    AND deleted_at IS NULL
 ORDER BY unit_number
 `
 
@@ -746,7 +755,11 @@ func (q *Queries) GetCourseUnits(ctx context.Context, courseID int32) ([]GetCour
 }
 
 const getCoursesCount = `-- name: GetCoursesCount :one
-SELECT COUNT(*) FROM courses
+SELECT COUNT(*)
+FROM courses c
+WHERE c.deleted_at IS NULL AND NOT EXISTS (
+    SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id
+)
 `
 
 func (q *Queries) GetCoursesCount(ctx context.Context) (int64, error) {
@@ -766,6 +779,8 @@ WITH latest_module_progress AS (
     JOIN units u ON u.id = m.unit_id
     WHERE ump.user_id = $1 
     AND u.course_id = $2
+    AND m.deleted_at IS NULL
+    AND u.deleted_at IS NULL
     ORDER BY ump.updated_at DESC NULLS LAST
     LIMIT 1
 )
@@ -857,6 +872,7 @@ WITH enrolled_count AS (
     SELECT COUNT(*) as total
     FROM courses c
     JOIN user_courses uc ON uc.course_id = c.id AND uc.user_id = $1::int
+    WHERE c.deleted_at IS NULL
 ),
 latest_progress AS (
     SELECT
@@ -882,15 +898,16 @@ latest_progress AS (
         ump.progress as module_progress,
         ump.status as module_status
     FROM units u
-    JOIN modules m ON m.unit_id = u.id
+    JOIN modules m ON m.unit_id = u.id AND m.deleted_at IS NULL
     JOIN user_module_progress ump ON ump.module_id = m.id
         AND ump.user_id = $1::int
         AND (ump.status = 'uninitiated' OR ump.status = 'in_progress')
+    WHERE u.deleted_at IS NULL
     ORDER BY ump.updated_at DESC NULLS LAST
 ),
 enrolled_courses AS (
     SELECT 
-        c.id, c.folder_object_key, c.created_at, c.updated_at, c.draft, c.name, c.description, c.img_key, c.media_ext, c.requirements, c.what_you_learn, c.background_color, c.duration, c.difficulty_level, c.rating,
+        c.id, c.folder_object_key, c.created_at, c.updated_at, c.draft, c.name, c.description, c.img_key, c.media_ext, c.requirements, c.what_you_learn, c.background_color, c.duration, c.difficulty_level, c.rating, c.deleted_at,
         uc.progress as course_progress,
         (SELECT total FROM enrolled_count) as total_count,
         lp.unit_id,
@@ -917,6 +934,7 @@ enrolled_courses AS (
     JOIN user_courses uc ON uc.course_id = c.id 
         AND uc.user_id = $1::int
     LEFT JOIN latest_progress lp ON lp.course_id = c.id
+    WHERE c.deleted_at IS NULL
     ORDER BY c.created_at DESC
     LIMIT $3::int
     OFFSET $2::int
@@ -1074,8 +1092,9 @@ SELECT
     u.id as unit_id,
     m.id as module_id
 FROM units u
-JOIN modules m ON m.unit_id = u.id
+JOIN modules m ON m.unit_id = u.id AND m.deleted_at IS NULL
 WHERE u.course_id = $1::int
+    AND u.deleted_at IS NULL
 ORDER BY u.unit_number ASC, m.module_number ASC
 LIMIT 1
 `
@@ -1163,6 +1182,7 @@ FROM
     AND ump.user_id = $1::int
 WHERE
     m.unit_id = $2::int
+    AND m.deleted_at IS NULL
 ORDER BY m.module_number
 `
 
@@ -1301,14 +1321,14 @@ SELECT
     q.id,
     q.question,
     q.type,
+    jsonb_array_length(COALESCE(q.answer_schema->'blanks', '[]'::jsonb))::int AS blank_count,
     object_key as object_key,
     media_ext as media_ext,
     COALESCE(
         json_agg(
             json_build_object(
                 'id', qo.id,
-                'content', qo.content,
-                'is_correct', qo.is_correct
+                'content', qo.content
             ) ORDER BY qo.id
         ),
         '[]'::json
@@ -1317,13 +1337,14 @@ FROM question_sections qs
 JOIN questions q ON q.id = qs.question_id
 LEFT JOIN question_options qo ON qo.question_id = q.id
 WHERE qs.section_id = $1::int
-GROUP BY q.id, q.question, q.type
+GROUP BY q.id, q.question, q.type, q.answer_schema
 `
 
 type GetQuestionSectionRow struct {
 	ID              int32          `json:"id"`
 	Question        string         `json:"question"`
-	Type            string         `json:"type"`
+	Type            QuestionType   `json:"type"`
+	BlankCount      int32          `json:"blankCount"`
 	ObjectKey       uuid.NullUUID  `json:"objectKey"`
 	MediaExt        sql.NullString `json:"mediaExt"`
 	QuestionOptions interface{}    `json:"questionOptions"`
@@ -1344,6 +1365,7 @@ func (q *Queries) GetQuestionSection(ctx context.Context, sectionID int32) (GetQ
 		&i.ID,
 		&i.Question,
 		&i.Type,
+		&i.BlankCount,
 		&i.ObjectKey,
 		&i.MediaExt,
 		&i.QuestionOptions,
@@ -1379,12 +1401,12 @@ SELECT
                 'mediaExt', media_ext,
                 'question', q.question,
                 'type', q.type,
+                'blankCount', jsonb_array_length(COALESCE(q.answer_schema->'blanks', '[]'::jsonb)),
                 'options', (
                     SELECT jsonb_agg(jsonb_build_object(
                         'id', qo.id,
-                        'content', qo.content,
-                        'isCorrect', qo.is_correct
-                    ))
+                        'content', qo.content
+                    ) ORDER BY qo.id)
                     FROM question_options qo
                     WHERE qo.question_id = q.id
                 )
@@ -1460,6 +1482,7 @@ SELECT
 FROM modules
 WHERE
     unit_id = $1::int
+    AND deleted_at IS NULL
 ORDER BY module_number
 `
 
@@ -1580,10 +1603,66 @@ func (q *Queries) InsertCourseTag(ctx context.Context, arg InsertCourseTagParams
 	return err
 }
 
+const isCourseAuthor = `-- name: IsCourseAuthor :one
+SELECT EXISTS (
+    SELECT 1
+    FROM course_authors ca
+    WHERE ca.course_id = $1::int
+        AND ca.user_id = $2::int
+        AND EXISTS (
+            SELECT 1
+            FROM courses c
+            WHERE c.id = ca.course_id
+                AND c.deleted_at IS NULL
+        )
+        AND (
+            $3::int IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM units u
+                WHERE u.id = $3::int
+                    AND u.course_id = ca.course_id
+                    AND u.deleted_at IS NULL
+            )
+        )
+        AND (
+            $4::int IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM modules m
+                    JOIN units u ON u.id = m.unit_id
+                WHERE m.id = $4::int
+                    AND u.course_id = ca.course_id
+                    AND m.deleted_at IS NULL
+                    AND ($3::int IS NULL OR m.unit_id = $3::int)
+            )
+        )
+)::boolean
+`
+
+type IsCourseAuthorParams struct {
+	CourseID int32         `json:"courseId"`
+	UserID   int32         `json:"userId"`
+	UnitID   sql.NullInt32 `json:"unitId"`
+	ModuleID sql.NullInt32 `json:"moduleId"`
+}
+
+func (q *Queries) IsCourseAuthor(ctx context.Context, arg IsCourseAuthorParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isCourseAuthor,
+		arg.CourseID,
+		arg.UserID,
+		arg.UnitID,
+		arg.ModuleID,
+	)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const publishCourse = `-- name: PublishCourse :exec
 UPDATE courses
 SET draft = FALSE
-WHERE id = $1::int
+WHERE id = $1::int AND deleted_at IS NULL
 `
 
 func (q *Queries) PublishCourse(ctx context.Context, courseID int32) error {
@@ -1671,6 +1750,8 @@ SELECT
     COUNT(*) OVER() as total_count
 FROM courses c
 WHERE 
+    c.deleted_at IS NULL AND
+    NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id) AND
     (LOWER(c.name) LIKE LOWER($1::text) OR
      LOWER(c.description) LIKE LOWER($1::text) OR
      EXISTS (
@@ -1775,6 +1856,8 @@ SELECT
     ) as rank
 FROM courses c
 WHERE 
+    c.deleted_at IS NULL AND
+    NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id) AND
     to_tsvector('english', c.name) ||
     to_tsvector('english', COALESCE(c.description, '')) ||
     to_tsvector('english', COALESCE(c.requirements, '')) ||
@@ -1848,6 +1931,46 @@ func (q *Queries) SearchCoursesFullText(ctx context.Context, arg SearchCoursesFu
 	return items, nil
 }
 
+const softDeleteCourse = `-- name: SoftDeleteCourse :one
+WITH deleted_courses AS (
+    UPDATE courses c
+    SET deleted_at = NOW()
+    WHERE c.deleted_at IS NULL
+        AND (
+            (c.id = $1::int AND NOT EXISTS (
+                SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id
+            ))
+            OR c.id IN (SELECT snapshot_course_id FROM course_versions WHERE course_id = $1::int)
+        )
+    RETURNING c.id, c.deleted_at
+),
+deleted_units AS (
+    UPDATE units u
+    SET deleted_at = dc.deleted_at
+    FROM deleted_courses dc
+    WHERE u.course_id = dc.id
+        AND u.deleted_at IS NULL
+    RETURNING u.id, u.deleted_at
+),
+deleted_modules AS (
+    UPDATE modules m
+    SET deleted_at = du.deleted_at
+    FROM deleted_units du
+    WHERE m.unit_id = du.id
+        AND m.deleted_at IS NULL
+)
+SELECT COUNT(*) FROM deleted_courses
+`
+
+// Stamps the course, its snapshots and everything in them with the same
+// deleted_at, and returns how many courses that was.
+func (q *Queries) SoftDeleteCourse(ctx context.Context, courseID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteCourse, courseID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const startCourseUserCourses = `-- name: StartCourseUserCourses :exec
 INSERT INTO user_courses 
     (user_id, course_id)
@@ -1913,7 +2036,7 @@ SET
         WHEN $11::float < 0 THEN rating 
         ELSE $11::float 
     END
-WHERE id = $12::int
+WHERE id = $12::int AND deleted_at IS NULL
 `
 
 type UpdateCourseParams struct {
@@ -7,6 +7,7 @@ package gen
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -101,6 +102,52 @@ func (ns NullModuleProgressStatus) Value() (driver.Value, error) {
 	return string(ns.ModuleProgressStatus), nil
 }
 
+type QuestionType string
+
+const (
+	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
+	QuestionTypeMultiSelect    QuestionType = "multi_select"
+	QuestionTypeTrueFalse      QuestionType = "true_false"
+	QuestionTypeFillBlank      QuestionType = "fill_blank"
+	QuestionTypeOrdering       QuestionType = "ordering"
+	QuestionTypeNumeric        QuestionType = "numeric"
+)
+
+func (e *QuestionType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = QuestionType(s)
+	case string:
+		*e = QuestionType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for QuestionType: %T", src)
+	}
+	return nil
+}
+
+type NullQuestionType struct {
+	QuestionType QuestionType `json:"questionType"`
+	Valid        bool         `json:"valid"` // Valid is true if QuestionType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullQuestionType) Scan(value interface{}) error {
+	if value == nil {
+		ns.QuestionType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.QuestionType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullQuestionType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.QuestionType), nil
+}
+
 type SectionType string
 
 const (
@@ -110,6 +157,7 @@ const (
 	SectionTypeVideo    SectionType = "video"
 	SectionTypeLottie   SectionType = "lottie"
 	SectionTypeImage    SectionType = "image"
+	SectionTypeExercise SectionType = "exercise"
 )
 
 func (e *SectionType) Scan(src interface{}) error {
@@ -191,13 +239,15 @@ func (ns NullUserRole) Value() (driver.Value, error) {
 }
 
 type Achievement struct {
-	ID          int32     `json:"id"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	Draft       bool      `json:"draft"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Points      int32     `json:"points"`
+	ID          int32          `json:"id"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	Draft       bool           `json:"draft"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Points      int32          `json:"points"`
+	Criteria    sql.NullString `json:"criteria"`
+	Threshold   int32          `json:"threshold"`
 }
 
 type CodeSection struct {
@@ -224,6 +274,7 @@ type Course struct {
 	Duration        sql.NullInt32       `json:"duration"`
 	DifficultyLevel NullDifficultyLevel `json:"difficultyLevel"`
 	Rating          sql.NullFloat64     `json:"rating"`
+	DeletedAt       sql.NullTime        `json:"deletedAt"`
 }
 
 type CourseAuthor struct {
@@ -231,11 +282,51 @@ type CourseAuthor struct {
 	UserID   int32 `json:"userId"`
 }
 
+type CourseVersion struct {
+	ID               int32         `json:"id"`
+	CourseID         int32         `json:"courseId"`
+	Version          int32         `json:"version"`
+	SnapshotCourseID int32         `json:"snapshotCourseId"`
+	PublishedBy      sql.NullInt32 `json:"publishedBy"`
+	PublishedAt      time.Time     `json:"publishedAt"`
+}
+
 type CourseTag struct {
 	CourseID int32 `json:"courseId"`
 	TagID    int32 `json:"tagId"`
 }
 
+type ExerciseSection struct {
+	SectionID     int32          `json:"sectionId"`
+	Prompt        string         `json:"prompt"`
+	Language      string         `json:"language"`
+	StarterCode   string         `json:"starterCode"`
+	TimeLimitMs   int32          `json:"timeLimitMs"`
+	MemoryLimitKb int32          `json:"memoryLimitKb"`
+	ObjectKey     uuid.NullUUID  `json:"objectKey"`
+	MediaExt      sql.NullString `json:"mediaExt"`
+}
+
+type ExerciseSubmission struct {
+	ID          int32     `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UserID      int32     `json:"userId"`
+	SectionID   int32     `json:"sectionId"`
+	Code        string    `json:"code"`
+	Passed      bool      `json:"passed"`
+	PassedCount int32     `json:"passedCount"`
+	TotalCount  int32     `json:"totalCount"`
+}
+
+type ExerciseTestCase struct {
+	ID             int32  `json:"id"`
+	SectionID      int32  `json:"sectionId"`
+	Position       int16  `json:"position"`
+	Input          string `json:"input"`
+	ExpectedOutput string `json:"expectedOutput"`
+	Hidden         bool   `json:"hidden"`
+}
+
 type ImageSection struct {
 	SectionID int32          `json:"sectionId"`
 	ObjectKey uuid.NullUUID  `json:"objectKey"`
@@ -270,6 +361,14 @@ type MarkdownSection struct {
 	Markdown  string         `json:"markdown"`
 }
 
+type LoginAttempt struct {
+	Scope        string       `json:"scope"`
+	Key          string       `json:"key"`
+	FailedCount  int32        `json:"failedCount"`
+	LastFailedAt time.Time    `json:"lastFailedAt"`
+	LockedUntil  sql.NullTime `json:"lockedUntil"`
+}
+
 type Module struct {
 	ID              int32          `json:"id"`
 	CreatedAt       time.Time      `json:"createdAt"`
@@ -282,6 +381,8 @@ type Module struct {
 	Description     string         `json:"description"`
 	FolderObjectKey uuid.NullUUID  `json:"folderObjectKey"`
 	ImgKey          uuid.NullUUID  `json:"imgKey"`
+	SourceModuleID  sql.NullInt32  `json:"sourceModuleId"`
+	DeletedAt       sql.NullTime   `json:"deletedAt"`
 }
 
 type ModuleQuestion struct {
@@ -300,13 +401,25 @@ type Notification struct {
 	Read      bool      `json:"read"`
 }
 
+type OauthState struct {
+	ID           uuid.UUID     `json:"id"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	Provider     string        `json:"provider"`
+	CodeVerifier string        `json:"codeVerifier"`
+	ClientState  string        `json:"clientState"`
+	ExpiresAt    time.Time     `json:"expiresAt"`
+	LinkUserID   sql.NullInt32 `json:"linkUserId"`
+}
+
 type Question struct {
 	ID              int32               `json:"id"`
 	CreatedAt       time.Time           `json:"createdAt"`
 	UpdatedAt       time.Time           `json:"updatedAt"`
-	Type            string              `json:"type"`
+	Type            QuestionType        `json:"type"`
 	Question        string              `json:"question"`
 	DifficultyLevel NullDifficultyLevel `json:"difficultyLevel"`
+	AnswerSchema    json.RawMessage     `json:"answerSchema"`
+	Explanation     string              `json:"explanation"`
 }
 
 type QuestionOption struct {
@@ -314,6 +427,21 @@ type QuestionOption struct {
 	QuestionID int32  `json:"questionId"`
 	Content    string `json:"content"`
 	IsCorrect  bool   `json:"isCorrect"`
+	Feedback   string `json:"feedback"`
+	Position   int16  `json:"position"`
+}
+
+type QuestionReview struct {
+	UserID       int32     `json:"userId"`
+	QuestionID   int32     `json:"questionId"`
+	EaseFactor   float64   `json:"easeFactor"`
+	IntervalDays int32     `json:"intervalDays"`
+	Repetitions  int32     `json:"repetitions"`
+	Lapses       int32     `json:"lapses"`
+	DueAt        time.Time `json:"dueAt"`
+	ReviewedAt   time.Time `json:"reviewedAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
 }
 
 type QuestionSection struct {
@@ -328,13 +456,38 @@ type QuestionTag struct {
 	TagID      int32 `json:"tagId"`
 }
 
+type RateLimitBucket struct {
+	Key        string    `json:"key"`
+	Capacity   float64   `json:"capacity"`
+	RefillRate float64   `json:"refillRate"`
+	Tokens     float64   `json:"tokens"`
+	Allowed    bool      `json:"allowed"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type RefreshToken struct {
+	ID          int32          `json:"id"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UserID      int32          `json:"userId"`
+	FamilyID    uuid.UUID      `json:"familyId"`
+	TokenHash   string         `json:"tokenHash"`
+	ExpiresAt   time.Time      `json:"expiresAt"`
+	UsedAt      sql.NullTime   `json:"usedAt"`
+	RevokedAt   sql.NullTime   `json:"revokedAt"`
+	DeviceName  sql.NullString `json:"deviceName"`
+	UserAgent   sql.NullString `json:"userAgent"`
+	IpAddress   sql.NullString `json:"ipAddress"`
+	MfaVerified bool           `json:"mfaVerified"`
+}
+
 type Section struct {
-	ID        int32       `json:"id"`
-	CreatedAt time.Time   `json:"createdAt"`
-	UpdatedAt time.Time   `json:"updatedAt"`
-	ModuleID  int32       `json:"moduleId"`
-	Type      SectionType `json:"type"`
-	Position  int32       `json:"position"`
+	ID              int32         `json:"id"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	UpdatedAt       time.Time     `json:"updatedAt"`
+	ModuleID        int32         `json:"moduleId"`
+	Type            SectionType   `json:"type"`
+	Position        int32         `json:"position"`
+	SourceSectionID sql.NullInt32 `json:"sourceSectionId"`
 }
 
 type Streak struct {
@@ -365,6 +518,8 @@ type Unit struct {
 	Description     string         `json:"description"`
 	FolderObjectKey uuid.NullUUID  `json:"folderObjectKey"`
 	ImgKey          uuid.NullUUID  `json:"imgKey"`
+	SourceUnitID    sql.NullInt32  `json:"sourceUnitId"`
+	DeletedAt       sql.NullTime   `json:"deletedAt"`
 }
 
 type User struct {
@@ -390,6 +545,7 @@ type User struct {
 	FolderObjectKey   uuid.NullUUID  `json:"folderObjectKey"`
 	ImgKey            uuid.NullUUID  `json:"imgKey"`
 	MediaExt          sql.NullString `json:"mediaExt"`
+	VerifiedEmail     sql.NullString `json:"verifiedEmail"`
 }
 
 type UserAchievement struct {
@@ -401,6 +557,15 @@ type UserAchievement struct {
 	AchievedAt    time.Time `json:"achievedAt"`
 }
 
+type UserActionToken struct {
+	ID        uuid.UUID    `json:"id"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UserID    int32        `json:"userId"`
+	Purpose   string       `json:"purpose"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+	UsedAt    sql.NullTime `json:"usedAt"`
+}
+
 type UserCourse struct {
 	ID               int32         `json:"id"`
 	CreatedAt        time.Time     `json:"createdAt"`
@@ -411,6 +576,23 @@ type UserCourse struct {
 	FurthestModuleID sql.NullInt32 `json:"furthestModuleId"`
 }
 
+type UserIdentity struct {
+	ID        int32          `json:"id"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UserID    int32          `json:"userId"`
+	Provider  string         `json:"provider"`
+	Subject   string         `json:"subject"`
+	Email     sql.NullString `json:"email"`
+}
+
+type UserMfa struct {
+	UserID       int32        `json:"userId"`
+	TotpSecret   string       `json:"totpSecret"`
+	LastUsedStep int64        `json:"lastUsedStep"`
+	EnabledAt    sql.NullTime `json:"enabledAt"`
+	CreatedAt    time.Time    `json:"createdAt"`
+}
+
 type UserModuleProgress struct {
 	ID                   int32                `json:"id"`
 	CreatedAt            time.Time            `json:"createdAt"`
@@ -433,15 +615,25 @@ type UserPreference struct {
 }
 
 type UserQuestionAnswer struct {
-	ID                   int32     `json:"id"`
-	CreatedAt            time.Time `json:"createdAt"`
-	UpdatedAt            time.Time `json:"updatedAt"`
-	UserModuleProgressID int32     `json:"userModuleProgressId"`
-	QuestionID           int32     `json:"questionId"`
-	OptionID             int32     `json:"optionId"`
-	AnsweredAt           time.Time `json:"answeredAt"`
-	IsCorrect            bool      `json:"isCorrect"`
-	Progress             float64   `json:"progress"`
+	ID                   int32           `json:"id"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
+	UserModuleProgressID int32           `json:"userModuleProgressId"`
+	QuestionID           int32           `json:"questionId"`
+	OptionID             sql.NullInt32   `json:"optionId"`
+	AnsweredAt           time.Time       `json:"answeredAt"`
+	IsCorrect            bool            `json:"isCorrect"`
+	Progress             float64         `json:"progress"`
+	Answer               json.RawMessage `json:"answer"`
+	Attempts             int32           `json:"attempts"`
+}
+
+type UserRecoveryCode struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"userId"`
+	CodeHash  string       `json:"codeHash"`
+	UsedAt    sql.NullTime `json:"usedAt"`
+	CreatedAt time.Time    `json:"createdAt"`
 }
 
 type UserSectionProgress struct {
@@ -29,6 +29,8 @@ LEFT JOIN user_module_progress ump
     ON ump.module_id = m.id
     AND ump.user_id = $1
 WHERE u.course_id = $2
+    AND u.deleted_at IS NULL
+    AND m.deleted_at IS NULL
 `
 
 type CalculateCourseProgressParams struct {
@@ -51,6 +53,14 @@ SELECT
             COUNT(CASE
                 WHEN s.type = 'question' THEN
                     CASE WHEN uqa.is_correct THEN 1 END
+                WHEN s.type = 'exercise' THEN
+                    CASE WHEN EXISTS (
+                        SELECT 1
+                        FROM exercise_submissions sub
+                        WHERE sub.section_id = s.id
+                            AND sub.user_id = $1
+                            AND sub.passed
+                    ) THEN 1 END
                 ELSE
                     CASE WHEN usp.has_seen THEN 1 END
             END)::FLOAT / COUNT(*)::FLOAT
@@ -81,11 +91,21 @@ func (q *Queries) CalculateModuleProgress(ctx context.Context, arg CalculateModu
 	return progress, err
 }
 
+const clearSectionPositions = `-- name: ClearSectionPositions :exec
+UPDATE sections SET position = -id WHERE module_id = $1::int
+`
+
+func (q *Queries) ClearSectionPositions(ctx context.Context, moduleID int32) error {
+	_, err := q.db.ExecContext(ctx, clearSectionPositions, moduleID)
+	return err
+}
+
 const createModule = `-- name: CreateModule :one
 WITH new_module AS (
     SELECT COALESCE(MAX(module_number), 0) + 1 as next_number
     FROM modules
     WHERE unit_id = $1::int
+        AND deleted_at IS NULL
 )
 INSERT INTO modules (
     module_number,
@@ -104,7 +124,7 @@ INSERT INTO modules (
     COALESCE($5::UUID, NULL),
     COALESCE($6::text, '')
 )
-RETURNING id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key
+RETURNING id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key, source_module_id, deleted_at
 `
 
 type CreateModuleParams struct {
@@ -138,6 +158,8 @@ func (q *Queries) CreateModule(ctx context.Context, arg CreateModuleParams) (Mod
 		&i.Description,
 		&i.FolderObjectKey,
 		&i.ImgKey,
+		&i.SourceModuleID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -151,12 +173,74 @@ func (q *Queries) DeleteModule(ctx context.Context, moduleID int32) error {
 	return err
 }
 
+const deleteQuestionOption = `-- name: DeleteQuestionOption :exec
+DELETE FROM question_options WHERE id = $1::int
+`
+
+func (q *Queries) DeleteQuestionOption(ctx context.Context, optionID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteQuestionOption, optionID)
+	return err
+}
+
+const deleteQuestionTags = `-- name: DeleteQuestionTags :exec
+DELETE FROM question_tags WHERE question_id = $1::int
+`
+
+func (q *Queries) DeleteQuestionTags(ctx context.Context, questionID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteQuestionTags, questionID)
+	return err
+}
+
+const deleteSection = `-- name: DeleteSection :exec
+DELETE FROM sections WHERE id = $1::int
+`
+
+func (q *Queries) DeleteSection(ctx context.Context, sectionID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSection, sectionID)
+	return err
+}
+
+const deleteSectionContent = `-- name: DeleteSectionContent :exec
+WITH
+    deleted_markdown AS (
+        DELETE FROM markdown_sections WHERE section_id = $1::int
+    ),
+    deleted_code AS (
+        DELETE FROM code_sections WHERE section_id = $1::int
+    ),
+    deleted_video AS (
+        DELETE FROM video_sections WHERE section_id = $1::int
+    ),
+    deleted_lottie AS (
+        DELETE FROM lottie_sections WHERE section_id = $1::int
+    )
+DELETE FROM image_sections WHERE section_id = $1::int
+`
+
+func (q *Queries) DeleteSectionContent(ctx context.Context, sectionID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSectionContent, sectionID)
+	return err
+}
+
+const deleteSectionQuestion = `-- name: DeleteSectionQuestion :exec
+DELETE FROM questions
+WHERE id IN (
+    SELECT question_id FROM question_sections WHERE section_id = $1::int
+)
+`
+
+func (q *Queries) DeleteSectionQuestion(ctx context.Context, sectionID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteSectionQuestion, sectionID)
+	return err
+}
+
 const getCourseAndUnitIDs = `-- name: GetCourseAndUnitIDs :one
 SELECT u.course_id, m.unit_id
 FROM modules m
     JOIN units u ON m.unit_id = u.id
 WHERE
     m.id = $1
+    AND m.deleted_at IS NULL
 `
 
 type GetCourseAndUnitIDsRow struct {
@@ -171,10 +255,50 @@ func (q *Queries) GetCourseAndUnitIDs(ctx context.Context, id int32) (GetCourseA
 	return i, err
 }
 
+const getCourseModules = `-- name: GetCourseModules :many
+SELECT m.id, m.unit_id, m.module_number
+FROM modules m
+JOIN units u ON u.id = m.unit_id
+WHERE u.course_id = $1::int
+    AND u.deleted_at IS NULL
+    AND m.deleted_at IS NULL
+ORDER BY u.unit_number, m.module_number
+`
+
+type GetCourseModulesRow struct {
+	ID           int32 `json:"id"`
+	UnitID       int32 `json:"unitId"`
+	ModuleNumber int32 `json:"moduleNumber"`
+}
+
+func (q *Queries) GetCourseModules(ctx context.Context, courseID int32) ([]GetCourseModulesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCourseModules, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCourseModulesRow{}
+	for rows.Next() {
+		var i GetCourseModulesRow
+		if err := rows.Scan(&i.ID, &i.UnitID, &i.ModuleNumber); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFirstModuleIdInUnit = `-- name: GetFirstModuleIdInUnit :one
 SELECT id
 FROM modules
 WHERE unit_id = $1::int
+  AND deleted_at IS NULL
 ORDER BY module_number ASC
 LIMIT 1
 `
@@ -206,21 +330,22 @@ func (q *Queries) GetFurthestModuleID(ctx context.Context, arg GetFurthestModule
 }
 
 const getLastModuleNumber = `-- name: GetLastModuleNumber :one
-SELECT COALESCE(MAX(module_number), 0) as last_number
+SELECT COALESCE(MAX(module_number), 0)::int as last_number
 FROM modules
 WHERE
     unit_id = $1::int
+    AND deleted_at IS NULL
 `
 
-func (q *Queries) GetLastModuleNumber(ctx context.Context, unitID int32) (interface{}, error) {
+func (q *Queries) GetLastModuleNumber(ctx context.Context, unitID int32) (int32, error) {
 	row := q.db.QueryRowContext(ctx, getLastModuleNumber, unitID)
-	var last_number interface{}
+	var last_number int32
 	err := row.Scan(&last_number)
 	return last_number, err
 }
 
 const getModuleByID = `-- name: GetModuleByID :one
-SELECT id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key FROM modules WHERE id = $1::int
+SELECT id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key, source_module_id, deleted_at FROM modules WHERE id = $1::int AND deleted_at IS NULL
 `
 
 func (q *Queries) GetModuleByID(ctx context.Context, id int32) (Module, error) {
@@ -238,12 +363,83 @@ func (q *Queries) GetModuleByID(ctx context.Context, id int32) (Module, error) {
 		&i.Description,
 		&i.FolderObjectKey,
 		&i.ImgKey,
+		&i.SourceModuleID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getModuleByNumber = `-- name: GetModuleByNumber :one
+SELECT id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key, source_module_id, deleted_at FROM modules
+WHERE unit_id = $1::int AND module_number = $2::int
+    AND deleted_at IS NULL
+`
+
+type GetModuleByNumberParams struct {
+	UnitID       int32 `json:"unitId"`
+	ModuleNumber int32 `json:"moduleNumber"`
+}
+
+func (q *Queries) GetModuleByNumber(ctx context.Context, arg GetModuleByNumberParams) (Module, error) {
+	row := q.db.QueryRowContext(ctx, getModuleByNumber, arg.UnitID, arg.ModuleNumber)
+	var i Module
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MediaExt,
+		&i.Draft,
+		&i.ModuleNumber,
+		&i.UnitID,
+		&i.Name,
+		&i.Description,
+		&i.FolderObjectKey,
+		&i.ImgKey,
+		&i.SourceModuleID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getModuleQuestion = `-- name: GetModuleQuestion :one
+SELECT
+    q.id,
+    q.type,
+    q.answer_schema,
+    q.explanation
+FROM questions q
+JOIN question_sections qs ON qs.question_id = q.id
+JOIN sections s ON s.id = qs.section_id
+WHERE q.id = $1::int
+    AND s.module_id = $2::int
+`
+
+type GetModuleQuestionParams struct {
+	QuestionID int32 `json:"questionId"`
+	ModuleID   int32 `json:"moduleId"`
+}
+
+type GetModuleQuestionRow struct {
+	ID           int32           `json:"id"`
+	Type         QuestionType    `json:"type"`
+	AnswerSchema json.RawMessage `json:"answerSchema"`
+	Explanation  string          `json:"explanation"`
+}
+
+func (q *Queries) GetModuleQuestion(ctx context.Context, arg GetModuleQuestionParams) (GetModuleQuestionRow, error) {
+	row := q.db.QueryRowContext(ctx, getModuleQuestion, arg.QuestionID, arg.ModuleID)
+	var i GetModuleQuestionRow
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.AnswerSchema,
+		&i.Explanation,
 	)
 	return i, err
 }
 
 const getModuleTotalCountByUnitId = `-- name: GetModuleTotalCountByUnitId :one
-SELECT COUNT(*) FROM modules WHERE unit_id = $1::int
+SELECT COUNT(*) FROM modules WHERE unit_id = $1::int AND deleted_at IS NULL
 `
 
 func (q *Queries) GetModuleTotalCountByUnitId(ctx context.Context, unitID int32) (int64, error) {
@@ -273,7 +469,7 @@ SELECT jsonb_build_object(
 ) as module
 FROM modules m
 LEFT JOIN user_module_progress ump ON ump.module_id = m.id AND ump.user_id = $1::int
-WHERE m.unit_id = $2::int AND m.id = $3::int
+WHERE m.unit_id = $2::int AND m.id = $3::int AND m.deleted_at IS NULL
 `
 
 type GetModuleWithProgressParams struct {
@@ -290,7 +486,7 @@ func (q *Queries) GetModuleWithProgress(ctx context.Context, arg GetModuleWithPr
 }
 
 const getModulesByUnitId = `-- name: GetModulesByUnitId :many
-SELECT id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key FROM modules WHERE unit_id = $1::int
+SELECT id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key, source_module_id, deleted_at FROM modules WHERE unit_id = $1::int AND deleted_at IS NULL ORDER BY module_number
 `
 
 func (q *Queries) GetModulesByUnitId(ctx context.Context, unitID int32) ([]Module, error) {
@@ -314,6 +510,8 @@ func (q *Queries) GetModulesByUnitId(ctx context.Context, unitID int32) ([]Modul
 			&i.Description,
 			&i.FolderObjectKey,
 			&i.ImgKey,
+			&i.SourceModuleID,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -329,7 +527,7 @@ func (q *Queries) GetModulesByUnitId(ctx context.Context, unitID int32) ([]Modul
 }
 
 const getModulesCount = `-- name: GetModulesCount :one
-SELECT COUNT(*) FROM modules
+SELECT COUNT(*) FROM modules WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetModulesCount(ctx context.Context) (int64, error) {
@@ -341,7 +539,7 @@ func (q *Queries) GetModulesCount(ctx context.Context) (int64, error) {
 
 const getModulesList = `-- name: GetModulesList :many
 SELECT
-    m.id, m.created_at, m.updated_at, m.media_ext, m.draft, m.module_number, m.unit_id, m.name, m.description, m.folder_object_key, m.img_key,
+    m.id, m.created_at, m.updated_at, m.media_ext, m.draft, m.module_number, m.unit_id, m.name, m.description, m.folder_object_key, m.img_key, m.source_module_id, m.deleted_at,
     jsonb_build_object(
         'folderObjectKey', m.folder_object_key,
         'imgKey', m.img_key,
@@ -356,6 +554,7 @@ SELECT
 FROM modules m
 LEFT JOIN user_module_progress ump ON ump.module_id = m.id AND ump.user_id = $1::int
 WHERE m.unit_id = $2::int
+    AND m.deleted_at IS NULL
 ORDER BY m.module_number
 LIMIT $4::int
 OFFSET $3::int
@@ -380,6 +579,8 @@ type GetModulesListRow struct {
 	Description     string          `json:"description"`
 	FolderObjectKey uuid.NullUUID   `json:"folderObjectKey"`
 	ImgKey          uuid.NullUUID   `json:"imgKey"`
+	SourceModuleID  sql.NullInt32   `json:"sourceModuleId"`
+	DeletedAt       sql.NullTime    `json:"deletedAt"`
 	ModuleProgress  json.RawMessage `json:"moduleProgress"`
 }
 
@@ -409,6 +610,8 @@ func (q *Queries) GetModulesList(ctx context.Context, arg GetModulesListParams)
 			&i.Description,
 			&i.FolderObjectKey,
 			&i.ImgKey,
+			&i.SourceModuleID,
+			&i.DeletedAt,
 			&i.ModuleProgress,
 		); err != nil {
 			return nil, err
@@ -428,6 +631,7 @@ const getNextModuleId = `-- name: GetNextModuleId :one
 SELECT id
 FROM modules
 WHERE unit_id = $1::int
+  AND deleted_at IS NULL
   AND module_number > $2::int
 ORDER BY module_number ASC
 LIMIT 1
@@ -448,15 +652,17 @@ func (q *Queries) GetNextModuleId(ctx context.Context, arg GetNextModuleIdParams
 const getNextModuleIdInUnitOrNextUnit = `-- name: GetNextModuleIdInUnitOrNextUnit :one
 SELECT id
 FROM modules
-WHERE unit_id = $1::int
+WHERE deleted_at IS NULL
+    AND (unit_id = $1::int
     OR unit_id = (
         SELECT id
         FROM units
         WHERE course_id = $2::int
+            AND deleted_at IS NULL
             AND unit_number > $3::int
         ORDER BY unit_number ASC
         LIMIT 1
-    )
+    ))
 ORDER BY module_number ASC
 LIMIT 1
 `
@@ -478,6 +684,7 @@ const getNextModuleNumber = `-- name: GetNextModuleNumber :one
 SELECT module_number
 FROM modules
 WHERE unit_id = $1::int
+    AND deleted_at IS NULL
     AND module_number > $2::int
 ORDER BY module_number ASC
 LIMIT 1
@@ -499,6 +706,7 @@ const getNextUnitId = `-- name: GetNextUnitId :one
 SELECT id
 FROM units
 WHERE course_id = $1::int
+  AND deleted_at IS NULL
   AND unit_number > $2::int
 ORDER BY unit_number ASC
 LIMIT 1
@@ -520,6 +728,7 @@ const getNextUnitModuleId = `-- name: GetNextUnitModuleId :one
 SELECT id
 FROM modules
 WHERE unit_id = $1::int
+  AND deleted_at IS NULL
 ORDER BY module_number ASC
 LIMIT 1
 `
@@ -535,6 +744,7 @@ const getPrevModuleId = `-- name: GetPrevModuleId :one
 SELECT id
 FROM modules
 WHERE unit_id = $1::int
+  AND deleted_at IS NULL
   AND module_number < $2::int
 ORDER BY module_number DESC
 LIMIT 1
@@ -556,6 +766,7 @@ const getPrevUnitId = `-- name: GetPrevUnitId :one
 SELECT id
 FROM units
 WHERE course_id = $1::int
+  AND deleted_at IS NULL
   AND unit_number < $2::int
 ORDER BY unit_number DESC
 LIMIT 1
@@ -577,6 +788,7 @@ const getPrevUnitModuleId = `-- name: GetPrevUnitModuleId :one
 SELECT id
 FROM modules
 WHERE unit_id = $1::int
+  AND deleted_at IS NULL
 ORDER BY module_number DESC
 LIMIT 1
 `
@@ -588,6 +800,43 @@ func (q *Queries) GetPrevUnitModuleId(ctx context.Context, unitID int32) (int32,
 	return id, err
 }
 
+const getQuestionOptions = `-- name: GetQuestionOptions :many
+SELECT id, question_id, content, is_correct, feedback, position
+FROM question_options
+WHERE question_id = $1
+ORDER BY position, id
+`
+
+func (q *Queries) GetQuestionOptions(ctx context.Context, questionID int32) ([]QuestionOption, error) {
+	rows, err := q.db.QueryContext(ctx, getQuestionOptions, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []QuestionOption{}
+	for rows.Next() {
+		var i QuestionOption
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuestionID,
+			&i.Content,
+			&i.IsCorrect,
+			&i.Feedback,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSectionProgress = `-- name: GetSectionProgress :many
 SELECT
     section_id,
@@ -678,44 +927,47 @@ WITH section_content AS (
                 WHERE vs.section_id = s.id
             )
             WHEN 'question' THEN (
+                -- The answer key is never sent: options carry no isCorrect or
+                -- position, and fill_blank questions only say how many blanks
+                -- they have. Explanations and feedback appear once the
+                -- question has been answered.
                 SELECT jsonb_build_object(
                     'id', q.id,
                     'objectKey', qs.object_key,
                     'mediaExt', qs.media_ext,
                     'question', q.question,
                     'type', q.type,
+                    'blankCount', jsonb_array_length(COALESCE(q.answer_schema->'blanks', '[]'::jsonb)),
+                    'explanation', CASE WHEN uqa.id IS NOT NULL THEN NULLIF(q.explanation, '') END,
                     'options', COALESCE(
                         (SELECT jsonb_agg(
                             jsonb_build_object(
                                 'id', qo.id,
                                 'content', qo.content,
-                                'isCorrect', qo.is_correct
+                                'feedback', CASE WHEN uqa.id IS NOT NULL THEN NULLIF(qo.feedback, '') END
                             ) ORDER BY qo.id
                         )
                         FROM question_options qo
                         WHERE qo.question_id = q.id
                         ), '[]'::jsonb),
-                    'userQuestionAnswer', (
-                        SELECT CASE
-                            WHEN uqa.id IS NOT NULL THEN
-                                jsonb_build_object(
-                                    'optionId', uqa.option_id,
-                                    'answeredAt', uqa.answered_at,
-                                    'isCorrect', uqa.is_correct,
-                                    'progress', uqa.progress
-                                )
-                            ELSE NULL
-                        END
-                        FROM question_sections qs2
-                        LEFT JOIN user_module_progress ump ON ump.module_id = $1::int AND ump.user_id = $2::int
-                        LEFT JOIN user_question_answers uqa ON uqa.user_module_progress_id = ump.id
-                            AND uqa.question_id = q.id
-                        WHERE qs2.section_id = s.id
-                        LIMIT 1
-                    )
+                    'userQuestionAnswer', CASE
+                        WHEN uqa.id IS NOT NULL THEN
+                            jsonb_build_object(
+                                'optionId', uqa.option_id,
+                                'answer', uqa.answer,
+                                'answeredAt', uqa.answered_at,
+                                'isCorrect', uqa.is_correct,
+                                'attempts', uqa.attempts,
+                                'progress', uqa.progress
+                            )
+                        ELSE NULL
+                    END
                 )
                 FROM question_sections qs
                 JOIN questions q ON q.id = qs.question_id
+                LEFT JOIN user_module_progress ump ON ump.module_id = $1::int AND ump.user_id = $2::int
+                LEFT JOIN user_question_answers uqa ON uqa.user_module_progress_id = ump.id
+                    AND uqa.question_id = q.id
                 WHERE qs.section_id = s.id
             )
             WHEN 'code' THEN (
@@ -728,6 +980,38 @@ WITH section_content AS (
                 FROM code_sections cs
                 WHERE cs.section_id = s.id
             )
+            WHEN 'exercise' THEN (
+                -- Hidden test cases are left out.
+                SELECT jsonb_build_object(
+                    'prompt', es.prompt,
+                    'language', es.language,
+                    'starterCode', es.starter_code,
+                    'timeLimitMs', es.time_limit_ms,
+                    'memoryLimitKb', es.memory_limit_kb,
+                    'objectKey', es.object_key,
+                    'mediaExt', es.media_ext,
+                    'testCases', COALESCE(
+                        (SELECT jsonb_agg(
+                            jsonb_build_object(
+                                'input', etc.input,
+                                'expectedOutput', etc.expected_output,
+                                'hidden', etc.hidden
+                            ) ORDER BY etc.position
+                        )
+                        FROM exercise_test_cases etc
+                        WHERE etc.section_id = es.section_id AND NOT etc.hidden
+                        ), '[]'::jsonb),
+                    'passed', EXISTS (
+                        SELECT 1
+                        FROM exercise_submissions sub
+                        WHERE sub.section_id = es.section_id
+                            AND sub.user_id = $2::int
+                            AND sub.passed
+                    )
+                )
+                FROM exercise_sections es
+                WHERE es.section_id = s.id
+            )
             WHEN 'image' THEN (
             SELECT jsonb_build_object(
                 'url', ims.url, 
@@ -805,7 +1089,7 @@ func (q *Queries) GetSingleModuleSections(ctx context.Context, arg GetSingleModu
 const getUnitNumber = `-- name: GetUnitNumber :one
 SELECT unit_number
 FROM units
-WHERE id = $1::int
+WHERE id = $1::int AND deleted_at IS NULL
 `
 
 func (q *Queries) GetUnitNumber(ctx context.Context, unitID int32) (int32, error) {
@@ -992,7 +1276,7 @@ INSERT INTO
         name,
         description
     )
-VALUES (COALESCE($1::UUID, NULL), COALESCE($2::UUID, NULL), COALESCE($3::text, ''), $4, $5, $6, $7) RETURNING id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key
+VALUES (COALESCE($1::UUID, NULL), COALESCE($2::UUID, NULL), COALESCE($3::text, ''), $4, $5, $6, $7) RETURNING id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key, source_module_id, deleted_at
 `
 
 type InsertModuleParams struct {
@@ -1028,6 +1312,8 @@ func (q *Queries) InsertModule(ctx context.Context, arg InsertModuleParams) (Mod
 		&i.Description,
 		&i.FolderObjectKey,
 		&i.ImgKey,
+		&i.SourceModuleID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -1037,19 +1323,29 @@ INSERT INTO
     questions (
         type,
         question,
-        difficulty_level
+        difficulty_level,
+        answer_schema,
+        explanation
     )
-VALUES ($1, $2, $3) RETURNING id, created_at, updated_at, type, question, difficulty_level
+VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at, type, question, difficulty_level, answer_schema, explanation
 `
 
 type InsertQuestionParams struct {
-	Type            string              `json:"type"`
+	Type            QuestionType        `json:"type"`
 	Question        string              `json:"question"`
 	DifficultyLevel NullDifficultyLevel `json:"difficultyLevel"`
+	AnswerSchema    json.RawMessage     `json:"answerSchema"`
+	Explanation     string              `json:"explanation"`
 }
 
 func (q *Queries) InsertQuestion(ctx context.Context, arg InsertQuestionParams) (Question, error) {
-	row := q.db.QueryRowContext(ctx, insertQuestion, arg.Type, arg.Question, arg.DifficultyLevel)
+	row := q.db.QueryRowContext(ctx, insertQuestion,
+		arg.Type,
+		arg.Question,
+		arg.DifficultyLevel,
+		arg.AnswerSchema,
+		arg.Explanation,
+	)
 	var i Question
 	err := row.Scan(
 		&i.ID,
@@ -1058,6 +1354,8 @@ func (q *Queries) InsertQuestion(ctx context.Context, arg InsertQuestionParams)
 		&i.Type,
 		&i.Question,
 		&i.DifficultyLevel,
+		&i.AnswerSchema,
+		&i.Explanation,
 	)
 	return i, err
 }
@@ -1067,19 +1365,29 @@ INSERT INTO
     question_options (
         question_id,
         content,
-        is_correct
+        is_correct,
+        feedback,
+        position
     )
-VALUES ($1, $2, $3)
+VALUES ($1, $2, $3, $4, $5)
 `
 
 type InsertQuestionOptionParams struct {
 	QuestionID int32  `json:"questionId"`
 	Content    string `json:"content"`
 	IsCorrect  bool   `json:"isCorrect"`
+	Feedback   string `json:"feedback"`
+	Position   int16  `json:"position"`
 }
 
 func (q *Queries) InsertQuestionOption(ctx context.Context, arg InsertQuestionOptionParams) error {
-	_, err := q.db.ExecContext(ctx, insertQuestionOption, arg.QuestionID, arg.Content, arg.IsCorrect)
+	_, err := q.db.ExecContext(ctx, insertQuestionOption,
+		arg.QuestionID,
+		arg.Content,
+		arg.IsCorrect,
+		arg.Feedback,
+		arg.Position,
+	)
 	return err
 }
 
@@ -1123,7 +1431,7 @@ func (q *Queries) InsertQuestionTag(ctx context.Context, arg InsertQuestionTagPa
 const insertSection = `-- name: InsertSection :one
 INSERT INTO
     sections (module_id, type, position)
-VALUES ($1, $2::section_type, $3) RETURNING id, created_at, updated_at, module_id, type, position
+VALUES ($1, $2::section_type, $3) RETURNING id, created_at, updated_at, module_id, type, position, source_section_id
 `
 
 type InsertSectionParams struct {
@@ -1142,6 +1450,7 @@ func (q *Queries) InsertSection(ctx context.Context, arg InsertSectionParams) (S
 		&i.ModuleID,
 		&i.Type,
 		&i.Position,
+		&i.SourceSectionID,
 	)
 	return i, err
 }
@@ -1210,14 +1519,94 @@ func (q *Queries) IsModuleFurtherThan(ctx context.Context, arg IsModuleFurtherTh
 	return is_further, err
 }
 
+const lockModule = `-- name: LockModule :one
+SELECT id FROM modules WHERE id = $1::int AND deleted_at IS NULL FOR UPDATE
+`
+
+func (q *Queries) LockModule(ctx context.Context, moduleID int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, lockModule, moduleID)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const setModuleNumber = `-- name: SetModuleNumber :exec
+UPDATE modules
+SET
+    unit_id = $1::int,
+    module_number = $2::int,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $3::int
+`
+
+type SetModuleNumberParams struct {
+	UnitID       int32 `json:"unitId"`
+	ModuleNumber int32 `json:"moduleNumber"`
+	ModuleID     int32 `json:"moduleId"`
+}
+
+func (q *Queries) SetModuleNumber(ctx context.Context, arg SetModuleNumberParams) error {
+	_, err := q.db.ExecContext(ctx, setModuleNumber, arg.UnitID, arg.ModuleNumber, arg.ModuleID)
+	return err
+}
+
+const setSectionPosition = `-- name: SetSectionPosition :exec
+UPDATE sections
+SET
+    position = $1::int,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2::int
+`
+
+type SetSectionPositionParams struct {
+	Position  int32 `json:"position"`
+	SectionID int32 `json:"sectionId"`
+}
+
+func (q *Queries) SetSectionPosition(ctx context.Context, arg SetSectionPositionParams) error {
+	_, err := q.db.ExecContext(ctx, setSectionPosition, arg.Position, arg.SectionID)
+	return err
+}
+
+const shiftModuleNumbers = `-- name: ShiftModuleNumbers :exec
+UPDATE modules
+SET module_number = module_number + $1::int
+WHERE unit_id = $2::int AND deleted_at IS NULL
+`
+
+type ShiftModuleNumbersParams struct {
+	Offset int32 `json:"offset"`
+	UnitID int32 `json:"unitId"`
+}
+
+func (q *Queries) ShiftModuleNumbers(ctx context.Context, arg ShiftModuleNumbersParams) error {
+	_, err := q.db.ExecContext(ctx, shiftModuleNumbers, arg.Offset, arg.UnitID)
+	return err
+}
+
+const softDeleteModule = `-- name: SoftDeleteModule :execrows
+UPDATE modules
+SET deleted_at = NOW()
+WHERE id = $1::int
+    AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteModule(ctx context.Context, moduleID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteModule, moduleID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateModule = `-- name: UpdateModule :one
 UPDATE modules
 SET
     name = COALESCE(NULLIF($1::text, ''), name),
     description = COALESCE(NULLIF($2::text, ''), description),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $3::int
-RETURNING id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key
+WHERE id = $3::int AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, media_ext, draft, module_number, unit_id, name, description, folder_object_key, img_key, source_module_id, deleted_at
 `
 
 type UpdateModuleParams struct {
@@ -1241,16 +1630,103 @@ func (q *Queries) UpdateModule(ctx context.Context, arg UpdateModuleParams) (Mod
 		&i.Description,
 		&i.FolderObjectKey,
 		&i.ImgKey,
+		&i.SourceModuleID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const upsertQuestionAnswer = `-- name: UpsertQuestionAnswer :exec
+const updateQuestion = `-- name: UpdateQuestion :exec
+UPDATE questions
+SET
+    type = $1,
+    question = $2,
+    answer_schema = $3,
+    explanation = $4,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $5::int
+`
+
+type UpdateQuestionParams struct {
+	Type         QuestionType    `json:"type"`
+	Question     string          `json:"question"`
+	AnswerSchema json.RawMessage `json:"answerSchema"`
+	Explanation  string          `json:"explanation"`
+	QuestionID   int32           `json:"questionId"`
+}
+
+func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) error {
+	_, err := q.db.ExecContext(ctx, updateQuestion,
+		arg.Type,
+		arg.Question,
+		arg.AnswerSchema,
+		arg.Explanation,
+		arg.QuestionID,
+	)
+	return err
+}
+
+const updateQuestionOption = `-- name: UpdateQuestionOption :exec
+UPDATE question_options
+SET
+    content = $1,
+    is_correct = $2,
+    feedback = $3,
+    position = $4
+WHERE id = $5::int
+`
+
+type UpdateQuestionOptionParams struct {
+	Content   string `json:"content"`
+	IsCorrect bool   `json:"isCorrect"`
+	Feedback  string `json:"feedback"`
+	Position  int16  `json:"position"`
+	OptionID  int32  `json:"optionId"`
+}
+
+func (q *Queries) UpdateQuestionOption(ctx context.Context, arg UpdateQuestionOptionParams) error {
+	_, err := q.db.ExecContext(ctx, updateQuestionOption,
+		arg.Content,
+		arg.IsCorrect,
+		arg.Feedback,
+		arg.Position,
+		arg.OptionID,
+	)
+	return err
+}
+
+const updateQuestionSection = `-- name: UpdateQuestionSection :exec
+UPDATE question_sections
+SET
+    object_key = $1,
+    media_ext = $2
+WHERE section_id = $3::int
+`
+
+type UpdateQuestionSectionParams struct {
+	ObjectKey uuid.NullUUID  `json:"objectKey"`
+	MediaExt  sql.NullString `json:"mediaExt"`
+	SectionID int32          `json:"sectionId"`
+}
+
+func (q *Queries) UpdateQuestionSection(ctx context.Context, arg UpdateQuestionSectionParams) error {
+	_, err := q.db.ExecContext(ctx, updateQuestionSection, arg.ObjectKey, arg.MediaExt, arg.SectionID)
+	return err
+}
+
+const upsertQuestionAnswer = `-- name: UpsertQuestionAnswer :one
+WITH previous AS (
+    SELECT answer
+    FROM user_question_answers
+    WHERE user_module_progress_id = $1
+        AND question_id = $2
+)
 INSERT INTO
     user_question_answers (
         user_module_progress_id,
         question_id,
         option_id,
+        answer,
         is_correct,
         answered_at
     )
@@ -1259,7 +1735,8 @@ VALUES (
         $2,
         $3,
         $4,
-        COALESCE($5, NOW())
+        $5,
+        COALESCE($6, NOW())
     ) ON CONFLICT (
         user_module_progress_id,
         question_id
@@ -1267,28 +1744,51 @@ VALUES (
 UPDATE
 SET
     option_id = EXCLUDED.option_id,
+    answer = EXCLUDED.answer,
     is_correct = EXCLUDED.is_correct,
     answered_at = EXCLUDED.answered_at,
+    attempts = user_question_answers.attempts + CASE
+        WHEN user_question_answers.answer = EXCLUDED.answer THEN 0
+        ELSE 1
+    END,
     updated_at = NOW()
+RETURNING
+    attempts,
+    NOT EXISTS (
+        SELECT 1
+        FROM previous
+        WHERE previous.answer = user_question_answers.answer
+    ) AS changed
 `
 
 type UpsertQuestionAnswerParams struct {
-	UserModuleProgressID int32       `json:"userModuleProgressId"`
-	QuestionID           int32       `json:"questionId"`
-	OptionID             int32       `json:"optionId"`
-	IsCorrect            bool        `json:"isCorrect"`
-	Column5              interface{} `json:"column5"`
+	UserModuleProgressID int32           `json:"userModuleProgressId"`
+	QuestionID           int32           `json:"questionId"`
+	OptionID             sql.NullInt32   `json:"optionId"`
+	Answer               json.RawMessage `json:"answer"`
+	IsCorrect            bool            `json:"isCorrect"`
+	Column6              interface{}     `json:"column6"`
 }
 
-func (q *Queries) UpsertQuestionAnswer(ctx context.Context, arg UpsertQuestionAnswerParams) error {
-	_, err := q.db.ExecContext(ctx, upsertQuestionAnswer,
+type UpsertQuestionAnswerRow struct {
+	Attempts int32 `json:"attempts"`
+	Changed  bool  `json:"changed"`
+}
+
+// Saves a learner's answer to a question. changed is false when it repeats
+// the answer already saved, which does not count as another attempt.
+func (q *Queries) UpsertQuestionAnswer(ctx context.Context, arg UpsertQuestionAnswerParams) (UpsertQuestionAnswerRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertQuestionAnswer,
 		arg.UserModuleProgressID,
 		arg.QuestionID,
 		arg.OptionID,
+		arg.Answer,
 		arg.IsCorrect,
-		arg.Column5,
+		arg.Column6,
 	)
-	return err
+	var i UpsertQuestionAnswerRow
+	err := row.Scan(&i.Attempts, &i.Changed)
+	return i, err
 }
 
 const upsertSectionProgress = `-- name: UpsertSectionProgress :exec
@@ -9,12 +9,153 @@ import (
 	"context"
 )
 
-const getAllNotifications = `-- name: GetAllNotifications :many
-SELECT id, created_at, updated_at, user_id, content, read FROM notifications
+const countUnreadNotifications = `-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = $1::int
+    AND read = FALSE
 `
 
-func (q *Queries) GetAllNotifications(ctx context.Context) ([]Notification, error) {
-	rows, err := q.db.QueryContext(ctx, getAllNotifications)
+func (q *Queries) CountUnreadNotifications(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnreadNotifications, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createNotification = `-- name: CreateNotification :one
+INSERT INTO notifications (user_id, content)
+VALUES ($1::int, $2::text)
+RETURNING id, created_at, updated_at, user_id, content, read
+`
+
+type CreateNotificationParams struct {
+	UserID  int32  `json:"userId"`
+	Content string `json:"content"`
+}
+
+func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
+	row := q.db.QueryRowContext(ctx, createNotification, arg.UserID, arg.Content)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Content,
+		&i.Read,
+	)
+	return i, err
+}
+
+const deleteNotification = `-- name: DeleteNotification :execrows
+DELETE FROM notifications
+WHERE id = $1::int
+    AND user_id = $2::int
+`
+
+type DeleteNotificationParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"userId"`
+}
+
+func (q *Queries) DeleteNotification(ctx context.Context, arg DeleteNotificationParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteNotification, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getNotificationByID = `-- name: GetNotificationByID :one
+SELECT id, created_at, updated_at, user_id, content, read
+FROM notifications
+WHERE id = $1::int
+`
+
+func (q *Queries) GetNotificationByID(ctx context.Context, id int32) (Notification, error) {
+	row := q.db.QueryRowContext(ctx, getNotificationByID, id)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Content,
+		&i.Read,
+	)
+	return i, err
+}
+
+const getNotificationsAfter = `-- name: GetNotificationsAfter :many
+SELECT id, created_at, updated_at, user_id, content, read
+FROM notifications
+WHERE user_id = $1::int
+    AND id > $2::int
+ORDER BY id ASC
+LIMIT $3::int
+`
+
+type GetNotificationsAfterParams struct {
+	UserID    int32 `json:"userId"`
+	AfterID   int32 `json:"afterId"`
+	PageLimit int32 `json:"pageLimit"`
+}
+
+func (q *Queries) GetNotificationsAfter(ctx context.Context, arg GetNotificationsAfterParams) ([]Notification, error) {
+	rows, err := q.db.QueryContext(ctx, getNotificationsAfter, arg.UserID, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Notification{}
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Content,
+			&i.Read,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserNotifications = `-- name: GetUserNotifications :many
+SELECT id, created_at, updated_at, user_id, content, read
+FROM notifications
+WHERE user_id = $1::int
+    AND ($2::int = 0 OR id < $2::int)
+    AND (NOT $3::boolean OR read = FALSE)
+ORDER BY id DESC
+LIMIT $4::int
+`
+
+type GetUserNotificationsParams struct {
+	UserID     int32 `json:"userId"`
+	Cursor     int32 `json:"cursor"`
+	UnreadOnly bool  `json:"unreadOnly"`
+	PageLimit  int32 `json:"pageLimit"`
+}
+
+func (q *Queries) GetUserNotifications(ctx context.Context, arg GetUserNotificationsParams) ([]Notification, error) {
+	rows, err := q.db.QueryContext(ctx, getUserNotifications,
+		arg.UserID,
+		arg.Cursor,
+		arg.UnreadOnly,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -42,3 +183,42 @@ func (q *Queries) GetAllNotifications(ctx context.Context) ([]Notification, erro
 	}
 	return items, nil
 }
+
+const markAllNotificationsRead = `-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET
+    read = TRUE,
+    updated_at = NOW()
+WHERE user_id = $1::int
+    AND read = FALSE
+`
+
+func (q *Queries) MarkAllNotificationsRead(ctx context.Context, userID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markAllNotificationsRead, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markNotificationRead = `-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET
+    read = TRUE,
+    updated_at = NOW()
+WHERE id = $1::int
+    AND user_id = $2::int
+`
+
+type MarkNotificationReadParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"userId"`
+}
+
+func (q *Queries) MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markNotificationRead, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -8,48 +8,115 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddUserCpus(ctx context.Context, arg AddUserCpusParams) error
 	CalculateCourseProgress(ctx context.Context, arg CalculateCourseProgressParams) (interface{}, error)
 	CalculateModuleProgress(ctx context.Context, arg CalculateModuleProgressParams) (interface{}, error)
+	ClearAccountIPLoginAttempts(ctx context.Context, accountKey string) (int64, error)
+	ClearLoginAttempts(ctx context.Context, arg ClearLoginAttemptsParams) (int64, error)
+	ClearSectionPositions(ctx context.Context, moduleID int32) error
+	CloseBrokenStreaks(ctx context.Context) (int64, error)
+	CloseStreak(ctx context.Context, arg CloseStreakParams) error
+	ConsumeOAuthState(ctx context.Context, arg ConsumeOAuthStateParams) (ConsumeOAuthStateRow, error)
+	ConsumeUserActionToken(ctx context.Context, arg ConsumeUserActionTokenParams) (ConsumeUserActionTokenRow, error)
+	CopyExerciseSubmissions(ctx context.Context, arg CopyExerciseSubmissionsParams) error
+	CopyQuestionAnswer(ctx context.Context, arg CopyQuestionAnswerParams) error
+	CopySectionProgress(ctx context.Context, arg CopySectionProgressParams) error
+	CountCompletedCourses(ctx context.Context, userID int32) (int64, error)
+	CountCompletedModules(ctx context.Context, userID int32) (int64, error)
+	CountDueQuestionReviews(ctx context.Context, userID int32) (int64, error)
+	CountPerfectQuizzes(ctx context.Context, userID int32) (int64, error)
+	CountUnreadNotifications(ctx context.Context, userID int32) (int64, error)
+	CountUnusedUserRecoveryCodes(ctx context.Context, userID int32) (int64, error)
+	CountUserIdentities(ctx context.Context, userID int32) (int32, error)
+	CourseHasLearners(ctx context.Context, courseID int32) (bool, error)
 	CreateAchievement(ctx context.Context, arg CreateAchievementParams) (Achievement, error)
 	CreateCourse(ctx context.Context, arg CreateCourseParams) (int32, error)
 	CreateCourseTag(ctx context.Context, name string) (int32, error)
+	CreateCourseVersion(ctx context.Context, arg CreateCourseVersionParams) (CourseVersion, error)
 	CreateModule(ctx context.Context, arg CreateModuleParams) (Module, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	CreateOAuthState(ctx context.Context, arg CreateOAuthStateParams) error
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	CreateStreak(ctx context.Context, arg CreateStreakParams) (Streak, error)
 	CreateUnit(ctx context.Context, arg CreateUnitParams) (int32, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserActionToken(ctx context.Context, arg CreateUserActionTokenParams) error
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	CreateUserRecoveryCode(ctx context.Context, arg CreateUserRecoveryCodeParams) error
 	DeleteAchievement(ctx context.Context, id int32) error
 	DeleteCourse(ctx context.Context, courseID int32) error
+	DeleteCourseSnapshots(ctx context.Context, courseID int32) error
+	DeleteExerciseTestCases(ctx context.Context, sectionID int32) error
+	DeleteExpiredOAuthStates(ctx context.Context) error
+	DeleteExpiredRefreshTokens(ctx context.Context) (int64, error)
+	DeleteFullRateLimitBuckets(ctx context.Context) (int64, error)
 	DeleteModule(ctx context.Context, moduleID int32) error
 	DeleteModuleProgress(ctx context.Context, arg DeleteModuleProgressParams) error
+	DeleteNotification(ctx context.Context, arg DeleteNotificationParams) (int64, error)
+	DeleteQuestionOption(ctx context.Context, optionID int32) error
+	DeleteQuestionTags(ctx context.Context, questionID int32) error
+	DeleteSection(ctx context.Context, sectionID int32) error
+	DeleteSectionContent(ctx context.Context, sectionID int32) error
 	DeleteSectionProgress(ctx context.Context, arg DeleteSectionProgressParams) error
+	DeleteSectionQuestion(ctx context.Context, sectionID int32) error
+	DeleteStaleLoginAttempts(ctx context.Context, windowSeconds int32) (int64, error)
 	DeleteUnit(ctx context.Context, unitID int32) error
 	DeleteUser(ctx context.Context, id int32) error
 	DeleteUserCourse(ctx context.Context, arg DeleteUserCourseParams) error
+	DeleteUserIdentity(ctx context.Context, arg DeleteUserIdentityParams) (int64, error)
+	DeleteUserMFA(ctx context.Context, userID int32) error
+	DeleteUserRecoveryCodes(ctx context.Context, userID int32) error
+	EnableUserMFA(ctx context.Context, userID int32) (int64, error)
+	ExtendStreak(ctx context.Context, id int32) (Streak, error)
 	GetAchievementByID(ctx context.Context, id int32) (Achievement, error)
 	GetAchievementsCount(ctx context.Context) (int64, error)
 	GetAllAchievements(ctx context.Context) ([]Achievement, error)
 	GetAllCoursesWithOptionalProgress(ctx context.Context, arg GetAllCoursesWithOptionalProgressParams) ([]GetAllCoursesWithOptionalProgressRow, error)
-	GetAllNotifications(ctx context.Context) ([]Notification, error)
 	GetCodeSection(ctx context.Context, sectionID int32) (GetCodeSectionRow, error)
 	GetCourseAndUnitIDs(ctx context.Context, id int32) (GetCourseAndUnitIDsRow, error)
 	GetCourseAuthors(ctx context.Context, courseID int32) ([]GetCourseAuthorsRow, error)
 	GetCourseByID(ctx context.Context, courseID int32) (GetCourseByIDRow, error)
+	GetCourseModules(ctx context.Context, courseID int32) ([]GetCourseModulesRow, error)
 	GetCourseProgressSummaryBase(ctx context.Context, arg GetCourseProgressSummaryBaseParams) (GetCourseProgressSummaryBaseRow, error)
+	GetCourseSources(ctx context.Context, courseID int32) ([]GetCourseSourcesRow, error)
 	GetCourseTags(ctx context.Context, courseID int32) ([]Tag, error)
+	GetCourseUnit(ctx context.Context, arg GetCourseUnitParams) (Unit, error)
 	GetCourseUnits(ctx context.Context, courseID int32) ([]GetCourseUnitsRow, error)
+	GetCourseVersion(ctx context.Context, arg GetCourseVersionParams) (CourseVersion, error)
+	GetCourseVersions(ctx context.Context, courseID int32) ([]CourseVersion, error)
 	GetCoursesCount(ctx context.Context) (int64, error)
 	GetCurrentUnitAndModule(ctx context.Context, arg GetCurrentUnitAndModuleParams) (GetCurrentUnitAndModuleRow, error)
+	GetDueQuestionReviews(ctx context.Context, arg GetDueQuestionReviewsParams) ([]GetDueQuestionReviewsRow, error)
 	GetEnrolledCoursesWithProgress(ctx context.Context, arg GetEnrolledCoursesWithProgressParams) ([]GetEnrolledCoursesWithProgressRow, error)
+	GetExerciseSection(ctx context.Context, sectionID int32) (ExerciseSection, error)
+	GetExerciseTestCases(ctx context.Context, sectionID int32) ([]ExerciseTestCase, error)
+	GetExpiredTrashFolders(ctx context.Context, before time.Time) ([]GetExpiredTrashFoldersRow, error)
 	GetFirstModuleIdInUnit(ctx context.Context, unitID int32) (int32, error)
 	GetFirstUnitAndModuleInCourse(ctx context.Context, courseID int32) (GetFirstUnitAndModuleInCourseRow, error)
 	GetFurthestModuleID(ctx context.Context, arg GetFurthestModuleIDParams) (sql.NullInt32, error)
 	GetImageSection(ctx context.Context, sectionID int32) (GetImageSectionRow, error)
-	GetLastModuleNumber(ctx context.Context, unitID int32) (interface{}, error)
+	GetLastModuleNumber(ctx context.Context, unitID int32) (int32, error)
+	GetLastUnitNumber(ctx context.Context, courseID int32) (int32, error)
+	GetLatestCourseVersion(ctx context.Context, courseID int32) (CourseVersion, error)
+	GetLatestStreak(ctx context.Context, userID int32) (Streak, error)
+	GetLoginLockout(ctx context.Context, arg GetLoginLockoutParams) (int32, error)
+	GetLongestStreak(ctx context.Context, userID int32) (int32, error)
+	GetLottieSection(ctx context.Context, sectionID int32) (LottieSection, error)
 	GetMarkdownSection(ctx context.Context, sectionID int32) (GetMarkdownSectionRow, error)
+	GetMediaFolderAccess(ctx context.Context, arg GetMediaFolderAccessParams) (GetMediaFolderAccessRow, error)
+	GetMediaReferences(ctx context.Context) ([]GetMediaReferencesRow, error)
 	GetModuleByID(ctx context.Context, id int32) (Module, error)
+	GetModuleByNumber(ctx context.Context, arg GetModuleByNumberParams) (Module, error)
+	GetModuleExerciseSection(ctx context.Context, arg GetModuleExerciseSectionParams) (ExerciseSection, error)
 	GetModuleProgressByUnit(ctx context.Context, arg GetModuleProgressByUnitParams) ([]GetModuleProgressByUnitRow, error)
+	GetModuleQuestion(ctx context.Context, arg GetModuleQuestionParams) (GetModuleQuestionRow, error)
+	GetModuleSections(ctx context.Context, moduleID int32) ([]Section, error)
 	GetModuleSectionsWithProgress(ctx context.Context, arg GetModuleSectionsWithProgressParams) ([]GetModuleSectionsWithProgressRow, error)
 	GetModuleTotalCountByUnitId(ctx context.Context, unitID int32) (int64, error)
 	GetModuleWithProgress(ctx context.Context, arg GetModuleWithProgressParams) (json.RawMessage, error)
@@ -61,9 +128,15 @@ type Querier interface {
 	GetNextModuleNumber(ctx context.Context, arg GetNextModuleNumberParams) (int32, error)
 	GetNextUnitId(ctx context.Context, arg GetNextUnitIdParams) (int32, error)
 	GetNextUnitModuleId(ctx context.Context, unitID int32) (int32, error)
+	GetNotificationByID(ctx context.Context, id int32) (Notification, error)
+	GetNotificationsAfter(ctx context.Context, arg GetNotificationsAfterParams) ([]Notification, error)
+	GetOpenStreakForUpdate(ctx context.Context, userID int32) (Streak, error)
 	GetPrevModuleId(ctx context.Context, arg GetPrevModuleIdParams) (int32, error)
 	GetPrevUnitId(ctx context.Context, arg GetPrevUnitIdParams) (int32, error)
 	GetPrevUnitModuleId(ctx context.Context, unitID int32) (int32, error)
+	GetQuestionAnswerKey(ctx context.Context, questionID int32) (GetQuestionAnswerKeyRow, error)
+	GetQuestionOptions(ctx context.Context, questionID int32) ([]QuestionOption, error)
+	GetQuestionReviewForUpdate(ctx context.Context, arg GetQuestionReviewForUpdateParams) (QuestionReview, error)
 	//  object_key UUID,
 	//     width INTEGER DEFAULT 200,
 	//     height INTEGER DEFAULT 200,
@@ -72,25 +145,48 @@ type Querier interface {
 	//     headline TEXT NOT NULL,
 	//     caption TEXT NOT NULL,
 	GetQuestionSection(ctx context.Context, sectionID int32) (GetQuestionSectionRow, error)
+	GetQuestionTagNames(ctx context.Context, questionID int32) ([]string, error)
 	GetReceivedAchievementsCount(ctx context.Context) (int64, error)
+	GetRefreshTokenByHashForUpdate(ctx context.Context, tokenHash string) (RefreshToken, error)
 	GetSectionContent(ctx context.Context, sectionID int32) (interface{}, error)
 	GetSectionProgress(ctx context.Context, arg GetSectionProgressParams) ([]GetSectionProgressRow, error)
+	GetSectionQuestion(ctx context.Context, sectionID int32) (GetSectionQuestionRow, error)
+	GetSectionQuestionAnswer(ctx context.Context, arg GetSectionQuestionAnswerParams) (GetSectionQuestionAnswerRow, error)
 	GetSingleModuleSections(ctx context.Context, arg GetSingleModuleSectionsParams) ([]GetSingleModuleSectionsRow, error)
 	GetTopUsersByStreak(ctx context.Context, limit int32) ([]GetTopUsersByStreakRow, error)
+	GetTrash(ctx context.Context) ([]GetTrashRow, error)
+	GetTrashedCourse(ctx context.Context, courseID int32) (GetTrashedCourseRow, error)
+	GetTrashedModule(ctx context.Context, moduleID int32) (GetTrashedModuleRow, error)
+	GetTrashedUnit(ctx context.Context, unitID int32) (GetTrashedUnitRow, error)
+	GetUnearnedAchievements(ctx context.Context, userID int32) ([]Achievement, error)
 	GetUnitByID(ctx context.Context, unitID int32) (Unit, error)
 	GetUnitModules(ctx context.Context, unitID int32) ([]GetUnitModulesRow, error)
 	GetUnitNumber(ctx context.Context, unitID int32) (int32, error)
 	GetUnitsByCourseID(ctx context.Context, courseID int32) ([]Unit, error)
 	GetUnitsCount(ctx context.Context) (int64, error)
+	GetUserAchievements(ctx context.Context, userID int32) ([]GetUserAchievementsRow, error)
 	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
 	GetUserByID(ctx context.Context, id int32) (GetUserByIDRow, error)
+	GetUserCourseVersion(ctx context.Context, arg GetUserCourseVersionParams) (CourseVersion, error)
+	GetUserIdentities(ctx context.Context, userID int32) ([]UserIdentity, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	GetUserLoginForUpdate(ctx context.Context, id int32) (GetUserLoginForUpdateRow, error)
+	GetUserMFA(ctx context.Context, userID int32) (UserMfa, error)
+	GetUserNotifications(ctx context.Context, arg GetUserNotificationsParams) ([]Notification, error)
+	GetUserStreakDays(ctx context.Context, userID int32) (int32, error)
+	GetUserTimezone(ctx context.Context, userID int32) (string, error)
 	GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUsersRow, error)
 	GetUsersCount(ctx context.Context) (int64, error)
 	GetVideoSection(ctx context.Context, sectionID int32) (GetVideoSectionRow, error)
+	GrantUserAchievement(ctx context.Context, arg GrantUserAchievementParams) (UserAchievement, error)
+	HasPassedExercise(ctx context.Context, arg HasPassedExerciseParams) (bool, error)
 	InitializeModuleProgress(ctx context.Context, arg InitializeModuleProgressParams) error
 	InsertCodeSection(ctx context.Context, arg InsertCodeSectionParams) error
 	InsertCourseAuthor(ctx context.Context, arg InsertCourseAuthorParams) error
 	InsertCourseTag(ctx context.Context, arg InsertCourseTagParams) error
+	InsertExerciseSection(ctx context.Context, arg InsertExerciseSectionParams) error
+	InsertExerciseSubmission(ctx context.Context, arg InsertExerciseSubmissionParams) (int32, error)
+	InsertExerciseTestCase(ctx context.Context, arg InsertExerciseTestCaseParams) error
 	InsertImageSection(ctx context.Context, arg InsertImageSectionParams) error
 	InsertLottieSection(ctx context.Context, arg InsertLottieSectionParams) error
 	InsertMarkdownSection(ctx context.Context, arg InsertMarkdownSectionParams) error
@@ -103,26 +199,72 @@ type Querier interface {
 	InsertTag(ctx context.Context, name string) (int32, error)
 	InsertUserPreferences(ctx context.Context, arg InsertUserPreferencesParams) (UserPreference, error)
 	InsertVideoSection(ctx context.Context, arg InsertVideoSectionParams) error
+	InvalidateUserActionTokens(ctx context.Context, arg InvalidateUserActionTokensParams) error
+	IsCourseAuthor(ctx context.Context, arg IsCourseAuthorParams) (bool, error)
+	IsCourseSnapshot(ctx context.Context, courseID int32) (bool, error)
+	IsEnrolledInCourse(ctx context.Context, arg IsEnrolledInCourseParams) (bool, error)
+	IsMediaFolderInUse(ctx context.Context, folderObjectKey uuid.UUID) (bool, error)
 	IsModuleFurtherThan(ctx context.Context, arg IsModuleFurtherThanParams) (bool, error)
+	IsUnitSnapshot(ctx context.Context, unitID int32) (bool, error)
+	LockCourse(ctx context.Context, courseID int32) error
+	LockModule(ctx context.Context, moduleID int32) (int32, error)
+	MarkAllNotificationsRead(ctx context.Context, userID int32) (int64, error)
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (int64, error)
+	MarkRefreshTokenUsed(ctx context.Context, id int32) error
+	MoveCourseLearners(ctx context.Context, arg MoveCourseLearnersParams) error
+	MoveCourseUnits(ctx context.Context, arg MoveCourseUnitsParams) error
+	MoveDeletedModules(ctx context.Context, arg MoveDeletedModulesParams) error
+	MoveQuestionReview(ctx context.Context, arg MoveQuestionReviewParams) error
 	PublishCourse(ctx context.Context, courseID int32) error
+	PurgeTrashedCourses(ctx context.Context, before time.Time) (int64, error)
+	PurgeTrashedModules(ctx context.Context, before time.Time) (int64, error)
+	PurgeTrashedUnits(ctx context.Context, before time.Time) (int64, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (int32, error)
 	RemoveCourseTag(ctx context.Context, arg RemoveCourseTagParams) error
 	ResetUserStreaks(ctx context.Context) error
+	RestoreCourse(ctx context.Context, arg RestoreCourseParams) error
+	RestoreModule(ctx context.Context, arg RestoreModuleParams) error
+	RestoreUnit(ctx context.Context, arg RestoreUnitParams) error
+	RevokeRefreshTokenFamily(ctx context.Context, arg RevokeRefreshTokenFamilyParams) (int64, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID int32) (int64, error)
 	SearchCourseTags(ctx context.Context, arg SearchCourseTagsParams) ([]SearchCourseTagsRow, error)
 	SearchCourses(ctx context.Context, arg SearchCoursesParams) ([]SearchCoursesRow, error)
 	SearchCoursesFullText(ctx context.Context, arg SearchCoursesFullTextParams) ([]SearchCoursesFullTextRow, error)
+	SetLoginLockout(ctx context.Context, arg SetLoginLockoutParams) error
+	SetModuleNumber(ctx context.Context, arg SetModuleNumberParams) error
+	SetModuleSource(ctx context.Context, arg SetModuleSourceParams) error
+	SetPendingUserTOTPSecret(ctx context.Context, arg SetPendingUserTOTPSecretParams) (int64, error)
+	SetSectionPosition(ctx context.Context, arg SetSectionPositionParams) error
+	SetSectionSource(ctx context.Context, arg SetSectionSourceParams) error
+	SetUnitSource(ctx context.Context, arg SetUnitSourceParams) error
+	SetUserEmailVerified(ctx context.Context, arg SetUserEmailVerifiedParams) (int64, error)
+	ShiftModuleNumbers(ctx context.Context, arg ShiftModuleNumbersParams) error
+	ShiftUnitNumbers(ctx context.Context, arg ShiftUnitNumbersParams) error
+	SoftDeleteCourse(ctx context.Context, courseID int32) (int64, error)
+	SoftDeleteModule(ctx context.Context, moduleID int32) (int64, error)
+	SoftDeleteUnit(ctx context.Context, unitID int32) (int64, error)
 	StartCourseUserCourses(ctx context.Context, arg StartCourseUserCoursesParams) error
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error)
 	UpdateAchievement(ctx context.Context, arg UpdateAchievementParams) (Achievement, error)
 	UpdateCourse(ctx context.Context, arg UpdateCourseParams) error
+	UpdateExerciseSection(ctx context.Context, arg UpdateExerciseSectionParams) error
 	UpdateModule(ctx context.Context, arg UpdateModuleParams) (Module, error)
+	UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) error
+	UpdateQuestionOption(ctx context.Context, arg UpdateQuestionOptionParams) error
+	UpdateQuestionSection(ctx context.Context, arg UpdateQuestionSectionParams) error
 	UpdateUnit(ctx context.Context, arg UpdateUnitParams) error
 	UpdateUnitNumber(ctx context.Context, arg UpdateUnitNumberParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
 	UpdateUserPreferences(ctx context.Context, arg UpdateUserPreferencesParams) (UserPreference, error)
 	UpdateUserStreak(ctx context.Context, arg UpdateUserStreakParams) (User, error)
-	UpsertQuestionAnswer(ctx context.Context, arg UpsertQuestionAnswerParams) error
+	UpsertQuestionAnswer(ctx context.Context, arg UpsertQuestionAnswerParams) (UpsertQuestionAnswerRow, error)
+	UpsertQuestionReview(ctx context.Context, arg UpsertQuestionReviewParams) (QuestionReview, error)
 	UpsertSectionProgress(ctx context.Context, arg UpsertSectionProgressParams) error
 	UpsertUserCourse(ctx context.Context, arg UpsertUserCourseParams) error
 	UpsertUserModuleProgress(ctx context.Context, arg UpsertUserModuleProgressParams) (int32, error)
+	UseUserRecoveryCode(ctx context.Context, arg UseUserRecoveryCodeParams) (int64, error)
+	UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
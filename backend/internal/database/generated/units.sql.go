@@ -68,9 +68,53 @@ func (q *Queries) DeleteUnit(ctx context.Context, unitID int32) error {
 	return err
 }
 
+const getCourseUnit = `-- name: GetCourseUnit :one
+SELECT id, created_at, updated_at, media_ext, draft, unit_number, course_id, name, description, folder_object_key, img_key, source_unit_id, deleted_at FROM units
+WHERE id = $1::int AND course_id = $2::int AND deleted_at IS NULL
+`
+
+type GetCourseUnitParams struct {
+	UnitID   int32 `json:"unitId"`
+	CourseID int32 `json:"courseId"`
+}
+
+func (q *Queries) GetCourseUnit(ctx context.Context, arg GetCourseUnitParams) (Unit, error) {
+	row := q.db.QueryRowContext(ctx, getCourseUnit, arg.UnitID, arg.CourseID)
+	var i Unit
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MediaExt,
+		&i.Draft,
+		&i.UnitNumber,
+		&i.CourseID,
+		&i.Name,
+		&i.Description,
+		&i.FolderObjectKey,
+		&i.ImgKey,
+		&i.SourceUnitID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getLastUnitNumber = `-- name: GetLastUnitNumber :one
+SELECT COALESCE(MAX(unit_number), 0)::int as last_number
+FROM units
+WHERE course_id = $1::int AND deleted_at IS NULL
+`
+
+func (q *Queries) GetLastUnitNumber(ctx context.Context, courseID int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getLastUnitNumber, courseID)
+	var last_number int32
+	err := row.Scan(&last_number)
+	return last_number, err
+}
+
 const getUnitByID = `-- name: GetUnitByID :one
-SELECT id, created_at, updated_at, media_ext, draft, unit_number, course_id, name, description, folder_object_key, img_key FROM units
-WHERE id = $1::int
+SELECT id, created_at, updated_at, media_ext, draft, unit_number, course_id, name, description, folder_object_key, img_key, source_unit_id, deleted_at FROM units
+WHERE id = $1::int AND deleted_at IS NULL
 `
 
 func (q *Queries) GetUnitByID(ctx context.Context, unitID int32) (Unit, error) {
@@ -88,13 +132,15 @@ func (q *Queries) GetUnitByID(ctx context.Context, unitID int32) (Unit, error) {
 		&i.Description,
 		&i.FolderObjectKey,
 		&i.ImgKey,
+		&i.SourceUnitID,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getUnitsByCourseID = `-- name: GetUnitsByCourseID :many
-SELECT id, created_at, updated_at, media_ext, draft, unit_number, course_id, name, description, folder_object_key, img_key FROM units
-WHERE course_id = $1::int
+SELECT id, created_at, updated_at, media_ext, draft, unit_number, course_id, name, description, folder_object_key, img_key, source_unit_id, deleted_at FROM units
+WHERE course_id = $1::int AND deleted_at IS NULL
 `
 
 func (q *Queries) GetUnitsByCourseID(ctx context.Context, courseID int32) ([]Unit, error) {
@@ -118,6 +164,8 @@ func (q *Queries) GetUnitsByCourseID(ctx context.Context, courseID int32) ([]Uni
 			&i.Description,
 			&i.FolderObjectKey,
 			&i.ImgKey,
+			&i.SourceUnitID,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -133,7 +181,7 @@ func (q *Queries) GetUnitsByCourseID(ctx context.Context, courseID int32) ([]Uni
 }
 
 const getUnitsCount = `-- name: GetUnitsCount :one
-SELECT COUNT(*) FROM units
+SELECT COUNT(*) FROM units WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetUnitsCount(ctx context.Context) (int64, error) {
@@ -143,11 +191,54 @@ func (q *Queries) GetUnitsCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const shiftUnitNumbers = `-- name: ShiftUnitNumbers :exec
+UPDATE units
+SET unit_number = unit_number + $1::int
+WHERE course_id = $2::int AND deleted_at IS NULL
+`
+
+type ShiftUnitNumbersParams struct {
+	Offset   int32 `json:"offset"`
+	CourseID int32 `json:"courseId"`
+}
+
+func (q *Queries) ShiftUnitNumbers(ctx context.Context, arg ShiftUnitNumbersParams) error {
+	_, err := q.db.ExecContext(ctx, shiftUnitNumbers, arg.Offset, arg.CourseID)
+	return err
+}
+
+const softDeleteUnit = `-- name: SoftDeleteUnit :one
+WITH deleted_unit AS (
+    UPDATE units
+    SET deleted_at = NOW()
+    WHERE id = $1::int
+        AND deleted_at IS NULL
+    RETURNING id, deleted_at
+),
+deleted_modules AS (
+    UPDATE modules m
+    SET deleted_at = du.deleted_at
+    FROM deleted_unit du
+    WHERE m.unit_id = du.id
+        AND m.deleted_at IS NULL
+)
+SELECT COUNT(*) FROM deleted_unit
+`
+
+// Stamps the unit and its modules with the same deleted_at, and returns how
+// many units that was.
+func (q *Queries) SoftDeleteUnit(ctx context.Context, unitID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteUnit, unitID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const updateUnit = `-- name: UpdateUnit :exec
 UPDATE units
 SET name = $1::text,
     description = $2::text
-WHERE id = $3::int
+WHERE id = $3::int AND deleted_at IS NULL
 `
 
 type UpdateUnitParams struct {
@@ -164,7 +255,7 @@ func (q *Queries) UpdateUnit(ctx context.Context, arg UpdateUnitParams) error {
 const updateUnitNumber = `-- name: UpdateUnitNumber :exec
 UPDATE units
 SET unit_number = $1::int
-WHERE id = $2::int
+WHERE id = $2::int AND deleted_at IS NULL
 `
 
 type UpdateUnitNumberParams struct {
@@ -38,7 +38,7 @@ VALUES (
         $8,
         $9,
         $10
-    ) RETURNING id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext
+    ) RETURNING id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext, verified_email
 `
 
 type CreateUserParams struct {
@@ -91,6 +91,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.FolderObjectKey,
 		&i.ImgKey,
 		&i.MediaExt,
+		&i.VerifiedEmail,
 	)
 	return i, err
 }
@@ -166,7 +167,7 @@ func (q *Queries) GetTopUsersByStreak(ctx context.Context, limit int32) ([]GetTo
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext, user_id, theme, language, timezone
+SELECT id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext, verified_email, user_id, theme, language, timezone
 FROM users
     LEFT JOIN user_preferences ON users.id = user_preferences.user_id
 WHERE
@@ -197,6 +198,7 @@ type GetUserByEmailRow struct {
 	FolderObjectKey   uuid.NullUUID  `json:"folderObjectKey"`
 	ImgKey            uuid.NullUUID  `json:"imgKey"`
 	MediaExt          sql.NullString `json:"mediaExt"`
+	VerifiedEmail     sql.NullString `json:"verifiedEmail"`
 	UserID            sql.NullInt32  `json:"userId"`
 	Theme             sql.NullString `json:"theme"`
 	Language          sql.NullString `json:"language"`
@@ -229,6 +231,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEm
 		&i.FolderObjectKey,
 		&i.ImgKey,
 		&i.MediaExt,
+		&i.VerifiedEmail,
 		&i.UserID,
 		&i.Theme,
 		&i.Language,
@@ -238,7 +241,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEm
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext, user_id, theme, language, timezone
+SELECT id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext, verified_email, user_id, theme, language, timezone
 FROM users
     LEFT JOIN user_preferences ON users.id = user_preferences.user_id
 WHERE
@@ -269,6 +272,7 @@ type GetUserByIDRow struct {
 	FolderObjectKey   uuid.NullUUID  `json:"folderObjectKey"`
 	ImgKey            uuid.NullUUID  `json:"imgKey"`
 	MediaExt          sql.NullString `json:"mediaExt"`
+	VerifiedEmail     sql.NullString `json:"verifiedEmail"`
 	UserID            sql.NullInt32  `json:"userId"`
 	Theme             sql.NullString `json:"theme"`
 	Language          sql.NullString `json:"language"`
@@ -301,6 +305,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (GetUserByIDRow, er
 		&i.FolderObjectKey,
 		&i.ImgKey,
 		&i.MediaExt,
+		&i.VerifiedEmail,
 		&i.UserID,
 		&i.Theme,
 		&i.Language,
@@ -561,11 +566,36 @@ func (q *Queries) ResetUserStreaks(ctx context.Context) error {
 	return err
 }
 
+const setUserEmailVerified = `-- name: SetUserEmailVerified :execrows
+UPDATE users
+SET
+    is_email_verified = TRUE,
+    verified_email = email,
+    updated_at = NOW()
+WHERE id = $1::int
+    AND email = $2::text
+`
+
+type SetUserEmailVerifiedParams struct {
+	ID    int32  `json:"id"`
+	Email string `json:"email"`
+}
+
+// Marks email as verified if it is still the user's address.
+func (q *Queries) SetUserEmailVerified(ctx context.Context, arg SetUserEmailVerifiedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserEmailVerified, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
     username = COALESCE(NULLIF($1::text, ''), username),
     email = COALESCE(NULLIF($2::text, ''), email),
+    is_email_verified = is_email_verified AND COALESCE(NULLIF($2::text, ''), email) = email,
     first_name = COALESCE(NULLIF($3::text, ''), first_name),
     last_name = COALESCE(NULLIF($4::text, ''), last_name),
     bio = COALESCE(NULLIF($5::text, ''), bio),
@@ -575,7 +605,7 @@ SET
     media_ext = COALESCE(NULLIF($9::text, ''), media_ext),
     updated_at = NOW()
 WHERE id = $10
-RETURNING id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext
+RETURNING id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext, verified_email
 `
 
 type UpdateUserParams struct {
@@ -628,10 +658,29 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.FolderObjectKey,
 		&i.ImgKey,
 		&i.MediaExt,
+		&i.VerifiedEmail,
 	)
 	return i, err
 }
 
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET
+    password_hash = $1::text,
+    updated_at = NOW()
+WHERE id = $2::int
+`
+
+type UpdateUserPasswordParams struct {
+	PasswordHash string `json:"passwordHash"`
+	ID           int32  `json:"id"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.PasswordHash, arg.ID)
+	return err
+}
+
 const updateUserPreferences = `-- name: UpdateUserPreferences :one
 UPDATE user_preferences
 SET
@@ -672,7 +721,7 @@ SET
     streak = $1::int,
     last_streak_date = $2::timestamptz
 WHERE id = $3
-RETURNING id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext
+RETURNING id, created_at, updated_at, username, email, oauth_id, role, password_hash, first_name, last_name, profile_picture_url, last_login_at, is_active, is_email_verified, bio, location, cpus, streak, last_streak_date, folder_object_key, img_key, media_ext, verified_email
 `
 
 type UpdateUserStreakParams struct {
@@ -707,6 +756,7 @@ func (q *Queries) UpdateUserStreak(ctx context.Context, arg UpdateUserStreakPara
 		&i.FolderObjectKey,
 		&i.ImgKey,
 		&i.MediaExt,
+		&i.VerifiedEmail,
 	)
 	return i, err
 }
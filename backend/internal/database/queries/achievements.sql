@@ -6,8 +6,8 @@ SELECT * FROM achievements WHERE id = $1 LIMIT 1;
 
 -- name: CreateAchievement :one
 INSERT INTO
-    achievements (name, description, points)
-VALUES ($1, $2, $3) RETURNING *;
+    achievements (name, description, points, criteria, threshold)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
 
 -- name: UpdateAchievement :one
 UPDATE achievements
@@ -15,12 +15,94 @@ SET
     name = $1,
     description = $2,
     points = $3,
+    criteria = $4,
+    threshold = $5,
     updated_at = CURRENT_TIMESTAMP
 WHERE
-    id = $4 RETURNING *;
+    id = $6 RETURNING *;
 
 -- name: DeleteAchievement :exec
 DELETE FROM achievements WHERE id = $1;
 
 -- name: GetAchievementsCount :one
 SELECT COUNT(*) FROM achievements;
+
+-- name: GetUnearnedAchievements :many
+SELECT a.*
+FROM achievements a
+WHERE a.criteria IS NOT NULL
+    AND NOT EXISTS (
+        SELECT 1
+        FROM user_achievements ua
+        WHERE ua.achievement_id = a.id
+            AND ua.user_id = @user_id::int
+    )
+ORDER BY a.id;
+
+-- name: GrantUserAchievement :one
+INSERT INTO user_achievements (user_id, achievement_id)
+VALUES (@user_id::int, @achievement_id::int)
+ON CONFLICT ON CONSTRAINT uniq_user_achievement DO NOTHING
+RETURNING *;
+
+-- name: AddUserCpus :exec
+UPDATE users
+SET cpus = cpus + @points::int
+WHERE id = @user_id::int;
+
+-- name: GetUserAchievements :many
+SELECT
+    ua.id,
+    ua.user_id,
+    ua.achievement_id,
+    ua.achieved_at,
+    a.name,
+    a.description,
+    a.points
+FROM user_achievements ua
+JOIN achievements a ON a.id = ua.achievement_id
+WHERE ua.user_id = @user_id::int
+ORDER BY ua.achieved_at DESC;
+
+-- name: CountCompletedModules :one
+SELECT COUNT(*)
+FROM user_module_progress ump
+JOIN modules m ON m.id = ump.module_id
+JOIN units u ON u.id = m.unit_id
+JOIN courses c ON c.id = u.course_id
+WHERE ump.user_id = @user_id::int
+    AND ump.status = 'completed'
+    AND m.deleted_at IS NULL
+    AND u.deleted_at IS NULL
+    AND c.deleted_at IS NULL;
+
+-- name: CountCompletedCourses :one
+SELECT COUNT(*)
+FROM user_courses uc
+JOIN courses c ON c.id = uc.course_id
+WHERE uc.user_id = @user_id::int
+    AND uc.progress >= 100
+    AND c.deleted_at IS NULL;
+
+-- name: CountPerfectQuizzes :one
+SELECT COUNT(*)
+FROM (
+    SELECT ump.id
+    FROM user_module_progress ump
+    JOIN modules m ON m.id = ump.module_id
+    JOIN units u ON u.id = m.unit_id
+    JOIN courses c ON c.id = u.course_id
+    JOIN sections s ON s.module_id = ump.module_id AND s.type = 'question'
+    JOIN question_sections qs ON qs.section_id = s.id
+    LEFT JOIN user_question_answers uqa ON uqa.user_module_progress_id = ump.id
+        AND uqa.question_id = qs.question_id
+    WHERE ump.user_id = @user_id::int
+        AND m.deleted_at IS NULL
+        AND u.deleted_at IS NULL
+        AND c.deleted_at IS NULL
+    GROUP BY ump.id
+    HAVING COUNT(qs.question_id) = COUNT(CASE WHEN uqa.is_correct THEN 1 END)
+) perfect_modules;
+
+-- name: GetUserStreakDays :one
+SELECT streak FROM users WHERE id = @user_id::int;
@@ -0,0 +1,30 @@
+-- name: GetModuleSections :many
+SELECT *
+FROM sections
+WHERE module_id = @module_id::int
+ORDER BY position, id;
+
+-- name: GetLottieSection :one
+SELECT *
+FROM lottie_sections
+WHERE section_id = @section_id::int;
+
+-- name: GetSectionQuestion :one
+SELECT
+    q.id,
+    q.type,
+    q.question,
+    q.answer_schema,
+    q.explanation,
+    qs.object_key,
+    qs.media_ext
+FROM question_sections qs
+JOIN questions q ON q.id = qs.question_id
+WHERE qs.section_id = @section_id::int;
+
+-- name: GetQuestionTagNames :many
+SELECT t.name
+FROM tags t
+JOIN question_tags qt ON qt.tag_id = t.id
+WHERE qt.question_id = @question_id::int
+ORDER BY t.name;
@@ -0,0 +1,223 @@
+-- name: LockCourse :exec
+SELECT id FROM courses WHERE id = @course_id::int AND deleted_at IS NULL FOR UPDATE;
+
+-- name: CreateCourseVersion :one
+INSERT INTO course_versions (
+    course_id,
+    version,
+    snapshot_course_id,
+    published_by
+)
+VALUES (
+    @course_id::int,
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM course_versions WHERE course_id = @course_id::int),
+    @snapshot_course_id::int,
+    sqlc.narg(published_by)::int
+)
+RETURNING *;
+
+-- name: GetCourseVersion :one
+SELECT *
+FROM course_versions
+WHERE course_id = @course_id::int
+    AND version = @version::int;
+
+-- name: GetCourseVersions :many
+SELECT *
+FROM course_versions
+WHERE course_id = @course_id::int
+ORDER BY version DESC;
+
+-- name: GetLatestCourseVersion :one
+SELECT *
+FROM course_versions
+WHERE course_id = @course_id::int
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: GetUserCourseVersion :one
+SELECT cv.*
+FROM course_versions cv
+JOIN user_courses uc ON uc.course_id = cv.snapshot_course_id
+WHERE cv.course_id = @course_id::int
+    AND uc.user_id = @user_id::int
+ORDER BY cv.version DESC
+LIMIT 1;
+
+-- name: IsCourseSnapshot :one
+SELECT EXISTS (
+    SELECT 1 FROM course_versions WHERE snapshot_course_id = @course_id::int
+)::boolean;
+
+-- name: IsEnrolledInCourse :one
+SELECT EXISTS (
+    SELECT 1 FROM user_courses WHERE user_id = @user_id::int AND course_id = @course_id::int
+)::boolean;
+
+-- name: IsUnitSnapshot :one
+SELECT EXISTS (
+    SELECT 1
+    FROM units u
+    JOIN course_versions cv ON cv.snapshot_course_id = u.course_id
+    WHERE u.id = @unit_id::int
+)::boolean;
+
+-- name: CourseHasLearners :one
+SELECT EXISTS (
+    SELECT 1 FROM user_courses WHERE course_id = @course_id::int
+)::boolean;
+
+-- name: MoveCourseLearners :exec
+UPDATE user_courses SET course_id = @to_course_id::int WHERE course_id = @from_course_id::int;
+
+-- name: MoveCourseUnits :exec
+UPDATE units
+SET course_id = @to_course_id::int
+WHERE course_id = @from_course_id::int
+    AND deleted_at IS NULL;
+
+-- name: MoveDeletedModules :exec
+UPDATE modules
+SET unit_id = @to_unit_id::int
+WHERE unit_id = @from_unit_id::int
+    AND deleted_at IS NOT NULL;
+
+-- name: DeleteCourseSnapshots :exec
+DELETE FROM courses
+WHERE id IN (
+    SELECT snapshot_course_id FROM course_versions WHERE course_id = @course_id::int
+);
+
+-- name: SetUnitSource :exec
+-- Points the unit and the snapshot units copied from it at source_unit_id.
+UPDATE units
+SET source_unit_id = @source_unit_id::int
+WHERE id = @unit_id::int
+    OR source_unit_id = @unit_id::int;
+
+-- name: SetModuleSource :exec
+-- Points the module and the snapshot modules copied from it at source_module_id.
+UPDATE modules
+SET source_module_id = @source_module_id::int
+WHERE id = @module_id::int
+    OR source_module_id = @module_id::int;
+
+-- name: SetSectionSource :exec
+-- Points the section and the snapshot sections copied from it at source_section_id.
+UPDATE sections
+SET source_section_id = @source_section_id::int
+WHERE id = @section_id::int
+    OR source_section_id = @section_id::int;
+
+-- name: GetCourseSources :many
+SELECT 'unit'::text AS kind, u.id, u.source_unit_id AS source_id
+FROM units u
+WHERE u.course_id = @course_id::int
+    AND u.deleted_at IS NULL
+UNION ALL
+SELECT 'module'::text AS kind, m.id, m.source_module_id AS source_id
+FROM modules m
+JOIN units u ON u.id = m.unit_id
+WHERE u.course_id = @course_id::int
+    AND u.deleted_at IS NULL
+    AND m.deleted_at IS NULL
+UNION ALL
+SELECT 'section'::text AS kind, s.id, s.source_section_id AS source_id
+FROM sections s
+JOIN modules m ON m.id = s.module_id
+JOIN units u ON u.id = m.unit_id
+WHERE u.course_id = @course_id::int
+    AND u.deleted_at IS NULL
+    AND m.deleted_at IS NULL;
+
+-- name: CopySectionProgress :exec
+INSERT INTO user_section_progress (
+    user_id,
+    module_id,
+    section_id,
+    started_at,
+    completed_at,
+    has_seen,
+    seen_at,
+    progress
+)
+SELECT
+    usp.user_id,
+    s.module_id,
+    s.id,
+    usp.started_at,
+    usp.completed_at,
+    usp.has_seen,
+    usp.seen_at,
+    usp.progress
+FROM user_section_progress usp
+JOIN sections s ON s.id = @to_section_id::int
+WHERE usp.user_id = @user_id::int
+    AND usp.section_id = @from_section_id::int
+ON CONFLICT (user_id, section_id) DO NOTHING;
+
+-- name: GetSectionQuestionAnswer :one
+SELECT uqa.question_id, uqa.option_id, uqa.answer, uqa.is_correct, uqa.answered_at, uqa.attempts
+FROM user_question_answers uqa
+JOIN user_module_progress ump ON ump.id = uqa.user_module_progress_id
+JOIN question_sections qs ON qs.question_id = uqa.question_id
+WHERE ump.user_id = @user_id::int
+    AND qs.section_id = @section_id::int;
+
+-- name: CopyQuestionAnswer :exec
+INSERT INTO user_question_answers (
+    user_module_progress_id,
+    question_id,
+    option_id,
+    answer,
+    is_correct,
+    answered_at,
+    attempts
+)
+SELECT
+    ump.id,
+    qs.question_id,
+    sqlc.narg(option_id)::int,
+    @answer::jsonb,
+    @is_correct::boolean,
+    @answered_at::timestamptz,
+    @attempts::int
+FROM question_sections qs
+JOIN sections s ON s.id = qs.section_id
+JOIN user_module_progress ump ON ump.module_id = s.module_id
+WHERE ump.user_id = @user_id::int
+    AND qs.section_id = @section_id::int
+ON CONFLICT (user_module_progress_id, question_id) DO NOTHING;
+
+-- name: MoveQuestionReview :exec
+UPDATE question_reviews
+SET question_id = @to_question_id::int
+WHERE user_id = @user_id::int
+    AND question_id = @from_question_id::int
+    AND NOT EXISTS (
+        SELECT 1 FROM question_reviews
+        WHERE user_id = @user_id::int AND question_id = @to_question_id::int
+    );
+
+-- name: CopyExerciseSubmissions :exec
+INSERT INTO exercise_submissions (
+    created_at,
+    user_id,
+    section_id,
+    code,
+    passed,
+    passed_count,
+    total_count
+)
+SELECT
+    created_at,
+    user_id,
+    @to_section_id::int,
+    code,
+    passed,
+    passed_count,
+    total_count
+FROM exercise_submissions
+WHERE user_id = @user_id::int
+    AND section_id = @from_section_id::int
+ORDER BY id;
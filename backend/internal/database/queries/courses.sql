@@ -32,7 +32,11 @@ INSERT INTO course_authors (course_id, user_id)
 VALUES (@course_id::int, @user_id::int);
 
 -- name: GetCoursesCount :one
-SELECT COUNT(*) FROM courses;
+SELECT COUNT(*)
+FROM courses c
+WHERE c.deleted_at IS NULL AND NOT EXISTS (
+    SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id
+);
 
 -- name: UpdateCourse :exec
 UPDATE courses
@@ -81,12 +85,12 @@ SET
         WHEN @rating::float < 0 THEN rating 
         ELSE @rating::float 
     END
-WHERE id = @course_id::int;
+WHERE id = @course_id::int AND deleted_at IS NULL;
 
 -- name: PublishCourse :exec
 UPDATE courses
 SET draft = FALSE
-WHERE id = @course_id::int;
+WHERE id = @course_id::int AND deleted_at IS NULL;
 
 -- name: GetCourseByID :one
 SELECT
@@ -106,7 +110,8 @@ SELECT
     rating
 FROM courses
 WHERE
-    id = @course_id::int;
+    id = @course_id::int
+    AND deleted_at IS NULL;
 
 -- name: GetCourseAuthors :many
 SELECT u.id, u.first_name, u.last_name
@@ -162,6 +167,7 @@ SELECT
 FROM units
 WHERE
     course_id = @course_id::int
+    AND deleted_at IS NULL
 ORDER BY unit_number;
 
 -- name: GetUnitModules :many
@@ -179,6 +185,7 @@ SELECT
 FROM modules
 WHERE
     unit_id = @unit_id::int
+    AND deleted_at IS NULL
 ORDER BY module_number;
 
 -- name: GetVideoSection :one
@@ -223,14 +230,14 @@ SELECT
     q.id,
     q.question,
     q.type,
+    jsonb_array_length(COALESCE(q.answer_schema->'blanks', '[]'::jsonb))::int AS blank_count,
     object_key as object_key,
     media_ext as media_ext,
     COALESCE(
         json_agg(
             json_build_object(
                 'id', qo.id,
-                'content', qo.content,
-                'is_correct', qo.is_correct
+                'content', qo.content
             ) ORDER BY qo.id
         ),
         '[]'::json
@@ -239,7 +246,7 @@ FROM question_sections qs
 JOIN questions q ON q.id = qs.question_id
 LEFT JOIN question_options qo ON qo.question_id = q.id
 WHERE qs.section_id = @section_id::int
-GROUP BY q.id, q.question, q.type;
+GROUP BY q.id, q.question, q.type, q.answer_schema;
 
 -- name: GetCodeSection :one
 SELECT 
@@ -253,11 +260,44 @@ WHERE section_id = @section_id::int;
 -- name: DeleteCourse :exec
 DELETE FROM courses WHERE id = @course_id::int;
 
+-- name: SoftDeleteCourse :one
+-- Stamps the course, its snapshots and everything in them with the same
+-- deleted_at, and returns how many courses that was.
+WITH deleted_courses AS (
+    UPDATE courses c
+    SET deleted_at = NOW()
+    WHERE c.deleted_at IS NULL
+        AND (
+            (c.id = @course_id::int AND NOT EXISTS (
+                SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id
+            ))
+            OR c.id IN (SELECT snapshot_course_id FROM course_versions WHERE course_id = @course_id::int)
+        )
+    RETURNING c.id, c.deleted_at
+),
+deleted_units AS (
+    UPDATE units u
+    SET deleted_at = dc.deleted_at
+    FROM deleted_courses dc
+    WHERE u.course_id = dc.id
+        AND u.deleted_at IS NULL
+    RETURNING u.id, u.deleted_at
+),
+deleted_modules AS (
+    UPDATE modules m
+    SET deleted_at = du.deleted_at
+    FROM deleted_units du
+    WHERE m.unit_id = du.id
+        AND m.deleted_at IS NULL
+)
+SELECT COUNT(*) FROM deleted_courses;
+
 -- name: GetCourseProgressSummaryBase :one
 WITH current_unit_id AS (
     SELECT u.id
     FROM units u
     WHERE u.course_id = @course_id::int
+        AND u.deleted_at IS NULL
     ORDER BY u.updated_at DESC
     LIMIT 1
 ),
@@ -265,6 +305,7 @@ current_module_id AS (
     SELECT m.id
     FROM modules m
     WHERE m.unit_id = (SELECT id FROM current_unit_id)
+        AND m.deleted_at IS NULL
     ORDER BY m.updated_at DESC
     LIMIT 1
 )
@@ -310,7 +351,7 @@ FROM courses c
          LEFT JOIN modules m ON m.id = cmi.id
          LEFT JOIN user_courses uc ON uc.course_id = c.id AND uc.user_id = @user_id::int
          LEFT JOIN user_module_progress ump ON ump.module_id = cmi.id AND ump.user_id = @user_id::int
-WHERE c.id = @course_id::int;
+WHERE c.id = @course_id::int AND c.deleted_at IS NULL;
 
 -- name: GetModuleProgressByUnit :many
 SELECT m.id, m.created_at, m.updated_at, m.module_number, m.unit_id, m.name, m.description, m.folder_object_key, m.img_key, m.media_ext, ump.progress, ump.status
@@ -320,12 +361,13 @@ FROM
     AND ump.user_id = @user_id::int
 WHERE
     m.unit_id = @unit_id::int
+    AND m.deleted_at IS NULL
 ORDER BY m.module_number;
 
 -- name: GetAllCoursesWithOptionalProgress :many
 WITH user_progress AS (
     SELECT
-        uc.course_id,
+        COALESCE(cv.course_id, uc.course_id) AS course_id,
         u.id as unit_id,
         u.created_at as unit_created_at,
         u.updated_at as unit_updated_at,
@@ -347,8 +389,9 @@ WITH user_progress AS (
         ump.progress as module_progress,
         ump.status as module_status
     FROM user_courses uc
-             JOIN units u ON u.course_id = uc.course_id
-             JOIN modules m ON m.unit_id = u.id
+             LEFT JOIN course_versions cv ON cv.snapshot_course_id = uc.course_id
+             JOIN units u ON u.course_id = uc.course_id AND u.deleted_at IS NULL
+             JOIN modules m ON m.unit_id = u.id AND m.deleted_at IS NULL
              LEFT JOIN user_module_progress ump ON ump.module_id = m.id
         AND ump.user_id = @user_id::int
     WHERE uc.user_id = @user_id::int
@@ -384,10 +427,14 @@ SELECT
     up.module_description,
     COALESCE(up.module_progress, 0) as module_progress,
     COALESCE(up.module_status, 'uninitiated') as module_status,
-   (SELECT COUNT(*) FROM courses) as total_count
+   (SELECT COUNT(*) FROM courses c2
+    WHERE c2.deleted_at IS NULL
+        AND NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c2.id)) as total_count
 FROM (
     SELECT id
-    FROM courses
+    FROM courses c
+    WHERE c.deleted_at IS NULL
+        AND NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id)
     ORDER BY id
     LIMIT @page_limit::int
     OFFSET @page_offset::int
@@ -422,6 +469,7 @@ WITH enrolled_count AS (
     SELECT COUNT(*) as total
     FROM courses c
     JOIN user_courses uc ON uc.course_id = c.id AND uc.user_id = @user_id::int
+    WHERE c.deleted_at IS NULL
 ),
 latest_progress AS (
     SELECT
@@ -447,10 +495,11 @@ latest_progress AS (
         ump.progress as module_progress,
         ump.status as module_status
     FROM units u
-    JOIN modules m ON m.unit_id = u.id
+    JOIN modules m ON m.unit_id = u.id AND m.deleted_at IS NULL
     JOIN user_module_progress ump ON ump.module_id = m.id
         AND ump.user_id = @user_id::int
         AND (ump.status = 'uninitiated' OR ump.status = 'in_progress')
+    WHERE u.deleted_at IS NULL
     ORDER BY ump.updated_at DESC NULLS LAST
 ),
 enrolled_courses AS (
@@ -482,6 +531,7 @@ enrolled_courses AS (
     JOIN user_courses uc ON uc.course_id = c.id 
         AND uc.user_id = @user_id::int
     LEFT JOIN latest_progress lp ON lp.course_id = c.id
+    WHERE c.deleted_at IS NULL
     ORDER BY c.created_at DESC
     LIMIT @page_limit::int
     OFFSET @page_offset::int
@@ -574,12 +624,12 @@ SELECT
                 'mediaExt', media_ext,
                 'question', q.question,
                 'type', q.type,
+                'blankCount', jsonb_array_length(COALESCE(q.answer_schema->'blanks', '[]'::jsonb)),
                 'options', (
                     SELECT jsonb_agg(jsonb_build_object(
                         'id', qo.id,
-                        'content', qo.content,
-                        'isCorrect', qo.is_correct
-                    ))
+                        'content', qo.content
+                    ) ORDER BY qo.id)
                     FROM question_options qo
                     WHERE qo.question_id = q.id
                 )
@@ -644,8 +694,9 @@ SELECT
     u.id as unit_id,
     m.id as module_id
 FROM units u
-JOIN modules m ON m.unit_id = u.id
+JOIN modules m ON m.unit_id = u.id AND m.deleted_at IS NULL
 WHERE u.course_id = @course_id::int
+    AND u.deleted_at IS NULL
 ORDER BY u.unit_number ASC, m.module_number ASC
 LIMIT 1;
 
@@ -674,6 +725,8 @@ SELECT
     COUNT(*) OVER() as total_count
 FROM courses c
 WHERE 
+    c.deleted_at IS NULL AND
+    NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id) AND
     (LOWER(c.name) LIKE LOWER(@search_query::text) OR
      LOWER(c.description) LIKE LOWER(@search_query::text) OR
      EXISTS (
@@ -716,6 +769,8 @@ SELECT
     ) as rank
 FROM courses c
 WHERE 
+    c.deleted_at IS NULL AND
+    NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id) AND
     to_tsvector('english', c.name) ||
     to_tsvector('english', COALESCE(c.description, '')) ||
     to_tsvector('english', COALESCE(c.requirements, '')) ||
@@ -785,6 +840,8 @@ WITH latest_module_progress AS (
     JOIN units u ON u.id = m.unit_id
     WHERE ump.user_id = $1 
     AND u.course_id = $2
+    AND m.deleted_at IS NULL
+    AND u.deleted_at IS NULL
     ORDER BY ump.updated_at DESC NULLS LAST
     LIMIT 1
 )
@@ -813,3 +870,39 @@ FROM latest_module_progress lmp
 JOIN modules m ON m.id = lmp.module_id
 JOIN units u ON u.id = m.unit_id
 LEFT JOIN user_module_progress ump ON ump.module_id = m.id AND ump.user_id = $1;
+
+-- name: IsCourseAuthor :one
+SELECT EXISTS (
+    SELECT 1
+    FROM course_authors ca
+    WHERE ca.course_id = @course_id::int
+        AND ca.user_id = @user_id::int
+        AND EXISTS (
+            SELECT 1
+            FROM courses c
+            WHERE c.id = ca.course_id
+                AND c.deleted_at IS NULL
+        )
+        AND (
+            sqlc.narg(unit_id)::int IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM units u
+                WHERE u.id = sqlc.narg(unit_id)::int
+                    AND u.course_id = ca.course_id
+                    AND u.deleted_at IS NULL
+            )
+        )
+        AND (
+            sqlc.narg(module_id)::int IS NULL
+            OR EXISTS (
+                SELECT 1
+                FROM modules m
+                    JOIN units u ON u.id = m.unit_id
+                WHERE m.id = sqlc.narg(module_id)::int
+                    AND u.course_id = ca.course_id
+                    AND m.deleted_at IS NULL
+                    AND (sqlc.narg(unit_id)::int IS NULL OR m.unit_id = sqlc.narg(unit_id)::int)
+            )
+        )
+)::boolean;
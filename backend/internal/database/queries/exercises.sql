@@ -0,0 +1,77 @@
+-- name: InsertExerciseSection :exec
+INSERT INTO
+    exercise_sections (
+        section_id,
+        prompt,
+        language,
+        starter_code,
+        time_limit_ms,
+        memory_limit_kb,
+        object_key,
+        media_ext
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: InsertExerciseTestCase :exec
+INSERT INTO
+    exercise_test_cases (
+        section_id,
+        position,
+        input,
+        expected_output,
+        hidden
+    )
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: UpdateExerciseSection :exec
+UPDATE exercise_sections
+SET
+    prompt = @prompt,
+    language = @language,
+    starter_code = @starter_code,
+    time_limit_ms = @time_limit_ms,
+    memory_limit_kb = @memory_limit_kb,
+    object_key = @object_key,
+    media_ext = @media_ext
+WHERE section_id = @section_id::int;
+
+-- name: DeleteExerciseTestCases :exec
+DELETE FROM exercise_test_cases WHERE section_id = @section_id::int;
+
+-- name: GetExerciseSection :one
+SELECT * FROM exercise_sections WHERE section_id = $1;
+
+-- name: GetModuleExerciseSection :one
+SELECT es.*
+FROM exercise_sections es
+JOIN sections s ON s.id = es.section_id
+JOIN modules m ON m.id = s.module_id
+JOIN units u ON u.id = m.unit_id
+JOIN courses c ON c.id = u.course_id
+WHERE es.section_id = @section_id::int
+    AND s.module_id = @module_id::int
+    AND m.deleted_at IS NULL
+    AND u.deleted_at IS NULL
+    AND c.deleted_at IS NULL;
+
+-- name: GetExerciseTestCases :many
+SELECT * FROM exercise_test_cases WHERE section_id = $1 ORDER BY position;
+
+-- name: InsertExerciseSubmission :one
+INSERT INTO
+    exercise_submissions (
+        user_id,
+        section_id,
+        code,
+        passed,
+        passed_count,
+        total_count
+    )
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;
+
+-- name: HasPassedExercise :one
+SELECT EXISTS (
+    SELECT 1
+    FROM exercise_submissions
+    WHERE user_id = $1 AND section_id = $2 AND passed
+);
@@ -0,0 +1,41 @@
+-- name: GetLoginLockout :one
+SELECT COALESCE(CEIL(EXTRACT(EPOCH FROM MAX(locked_until) - NOW())), 0)::int AS retry_after_seconds
+FROM login_attempts
+WHERE ((scope = 'account' AND key = @email::text) OR (scope = 'ip' AND key = @ip_address::text))
+    AND locked_until > NOW();
+
+-- name: RecordLoginFailure :one
+-- The count restarts once the previous failure is older than the window.
+INSERT INTO login_attempts (scope, key, failed_count, last_failed_at, account_key)
+VALUES (@scope::text, @key::text, 1, NOW(), @account_key::text)
+ON CONFLICT (scope, key) DO UPDATE
+SET
+    failed_count = CASE
+        WHEN login_attempts.last_failed_at < NOW() - make_interval(secs => @window_seconds::int) THEN 1
+        ELSE login_attempts.failed_count + 1
+    END,
+    last_failed_at = NOW(),
+    account_key = EXCLUDED.account_key
+RETURNING failed_count;
+
+-- name: SetLoginLockout :exec
+UPDATE login_attempts
+SET locked_until = NOW() + make_interval(secs => @lockout_seconds::int)
+WHERE scope = @scope::text
+    AND key = @key::text;
+
+-- name: ClearLoginAttempts :execrows
+DELETE FROM login_attempts
+WHERE scope = @scope::text
+    AND key = @key::text;
+
+-- name: ClearAccountIPLoginAttempts :execrows
+-- Clears the IPs whose latest failure was a sign-in to the account.
+DELETE FROM login_attempts
+WHERE scope = 'ip'
+    AND account_key = @account_key::text;
+
+-- name: DeleteStaleLoginAttempts :execrows
+DELETE FROM login_attempts
+WHERE last_failed_at < NOW() - make_interval(secs => @window_seconds::int)
+    AND (locked_until IS NULL OR locked_until < NOW());
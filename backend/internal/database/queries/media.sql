@@ -0,0 +1,79 @@
+-- name: GetMediaReferences :many
+-- Every media folder and object the database refers to, including those of
+-- snapshots and of content still in the trash. Section media are kept in the
+-- folder of their module.
+SELECT 'users'::text AS resource, folder_object_key, img_key AS object_key
+FROM users
+WHERE folder_object_key IS NOT NULL
+UNION
+SELECT 'courses'::text, folder_object_key, img_key
+FROM courses
+WHERE folder_object_key IS NOT NULL
+UNION
+SELECT 'units'::text, folder_object_key, img_key
+FROM units
+WHERE folder_object_key IS NOT NULL
+UNION
+SELECT 'modules'::text, folder_object_key, img_key
+FROM modules
+WHERE folder_object_key IS NOT NULL
+UNION
+SELECT 'modules'::text, m.folder_object_key, media.object_key
+FROM (
+    SELECT section_id, object_key FROM video_sections
+    UNION ALL
+    SELECT section_id, object_key FROM question_sections
+    UNION ALL
+    SELECT section_id, object_key FROM markdown_sections
+    UNION ALL
+    SELECT section_id, object_key FROM code_sections
+    UNION ALL
+    SELECT section_id, object_key FROM lottie_sections
+    UNION ALL
+    SELECT section_id, object_key FROM image_sections
+    UNION ALL
+    SELECT section_id, object_key FROM exercise_sections
+) media
+JOIN sections s ON s.id = media.section_id
+JOIN modules m ON m.id = s.module_id
+WHERE m.folder_object_key IS NOT NULL
+    AND media.object_key IS NOT NULL;
+
+-- name: GetMediaFolderAccess :one
+-- Reports whether anything uses the media folder and whether the user may
+-- change what is in it. Users own their profile folder, and course authors
+-- the folders of the course and its units and modules. Folders a published
+-- version uses are never changed.
+WITH owners AS (
+    SELECT NULL::int AS course_id, id AS user_id
+    FROM users
+    WHERE @resource::text = 'users' AND folder_object_key = @folder_object_key::uuid
+    UNION ALL
+    SELECT id, NULL::int
+    FROM courses
+    WHERE @resource::text = 'courses' AND folder_object_key = @folder_object_key::uuid
+    UNION ALL
+    SELECT course_id, NULL::int
+    FROM units
+    WHERE @resource::text = 'units' AND folder_object_key = @folder_object_key::uuid
+    UNION ALL
+    SELECT u.course_id, NULL::int
+    FROM modules m
+    JOIN units u ON u.id = m.unit_id
+    WHERE @resource::text = 'modules' AND m.folder_object_key = @folder_object_key::uuid
+)
+SELECT
+    EXISTS (SELECT 1 FROM owners)::boolean AS in_use,
+    (
+        EXISTS (
+            SELECT 1
+            FROM owners o
+            LEFT JOIN course_authors ca ON ca.course_id = o.course_id
+            WHERE o.user_id = @user_id::int OR ca.user_id = @user_id::int
+        )
+        AND NOT EXISTS (
+            SELECT 1
+            FROM owners o
+            JOIN course_versions cv ON cv.snapshot_course_id = o.course_id
+        )
+    )::boolean AS allowed;
@@ -1,8 +1,8 @@
 -- name: GetModulesByUnitId :many
-SELECT * FROM modules WHERE unit_id = @unit_id::int;
+SELECT * FROM modules WHERE unit_id = @unit_id::int AND deleted_at IS NULL ORDER BY module_number;
 
 -- name: GetModulesCount :one
-SELECT COUNT(*) FROM modules;
+SELECT COUNT(*) FROM modules WHERE deleted_at IS NULL;
 
 -- name: GetModuleWithProgress :one
 SELECT jsonb_build_object(
@@ -24,7 +24,7 @@ SELECT jsonb_build_object(
 ) as module
 FROM modules m
 LEFT JOIN user_module_progress ump ON ump.module_id = m.id AND ump.user_id = @user_id::int
-WHERE m.unit_id = @unit_id::int AND m.id = @module_id::int;
+WHERE m.unit_id = @unit_id::int AND m.id = @module_id::int AND m.deleted_at IS NULL;
 
 -- name: GetSingleModuleSections :many
 WITH section_content AS (
@@ -68,44 +68,47 @@ WITH section_content AS (
                 WHERE vs.section_id = s.id
             )
             WHEN 'question' THEN (
+                -- The answer key is never sent: options carry no isCorrect or
+                -- position, and fill_blank questions only say how many blanks
+                -- they have. Explanations and feedback appear once the
+                -- question has been answered.
                 SELECT jsonb_build_object(
                     'id', q.id,
                     'objectKey', qs.object_key,
                     'mediaExt', qs.media_ext,
                     'question', q.question,
                     'type', q.type,
+                    'blankCount', jsonb_array_length(COALESCE(q.answer_schema->'blanks', '[]'::jsonb)),
+                    'explanation', CASE WHEN uqa.id IS NOT NULL THEN NULLIF(q.explanation, '') END,
                     'options', COALESCE(
                         (SELECT jsonb_agg(
                             jsonb_build_object(
                                 'id', qo.id,
                                 'content', qo.content,
-                                'isCorrect', qo.is_correct
+                                'feedback', CASE WHEN uqa.id IS NOT NULL THEN NULLIF(qo.feedback, '') END
                             ) ORDER BY qo.id
                         )
                         FROM question_options qo
                         WHERE qo.question_id = q.id
                         ), '[]'::jsonb),
-                    'userQuestionAnswer', (
-                        SELECT CASE
-                            WHEN uqa.id IS NOT NULL THEN
-                                jsonb_build_object(
-                                    'optionId', uqa.option_id,
-                                    'answeredAt', uqa.answered_at,
-                                    'isCorrect', uqa.is_correct,
-                                    'progress', uqa.progress
-                                )
-                            ELSE NULL
-                        END
-                        FROM question_sections qs2
-                        LEFT JOIN user_module_progress ump ON ump.module_id = @module_id::int AND ump.user_id = @user_id::int
-                        LEFT JOIN user_question_answers uqa ON uqa.user_module_progress_id = ump.id
-                            AND uqa.question_id = q.id
-                        WHERE qs2.section_id = s.id
-                        LIMIT 1
-                    )
+                    'userQuestionAnswer', CASE
+                        WHEN uqa.id IS NOT NULL THEN
+                            jsonb_build_object(
+                                'optionId', uqa.option_id,
+                                'answer', uqa.answer,
+                                'answeredAt', uqa.answered_at,
+                                'isCorrect', uqa.is_correct,
+                                'attempts', uqa.attempts,
+                                'progress', uqa.progress
+                            )
+                        ELSE NULL
+                    END
                 )
                 FROM question_sections qs
                 JOIN questions q ON q.id = qs.question_id
+                LEFT JOIN user_module_progress ump ON ump.module_id = @module_id::int AND ump.user_id = @user_id::int
+                LEFT JOIN user_question_answers uqa ON uqa.user_module_progress_id = ump.id
+                    AND uqa.question_id = q.id
                 WHERE qs.section_id = s.id
             )
             WHEN 'code' THEN (
@@ -118,6 +121,38 @@ WITH section_content AS (
                 FROM code_sections cs
                 WHERE cs.section_id = s.id
             )
+            WHEN 'exercise' THEN (
+                -- Hidden test cases are left out.
+                SELECT jsonb_build_object(
+                    'prompt', es.prompt,
+                    'language', es.language,
+                    'starterCode', es.starter_code,
+                    'timeLimitMs', es.time_limit_ms,
+                    'memoryLimitKb', es.memory_limit_kb,
+                    'objectKey', es.object_key,
+                    'mediaExt', es.media_ext,
+                    'testCases', COALESCE(
+                        (SELECT jsonb_agg(
+                            jsonb_build_object(
+                                'input', etc.input,
+                                'expectedOutput', etc.expected_output,
+                                'hidden', etc.hidden
+                            ) ORDER BY etc.position
+                        )
+                        FROM exercise_test_cases etc
+                        WHERE etc.section_id = es.section_id AND NOT etc.hidden
+                        ), '[]'::jsonb),
+                    'passed', EXISTS (
+                        SELECT 1
+                        FROM exercise_submissions sub
+                        WHERE sub.section_id = es.section_id
+                            AND sub.user_id = @user_id::int
+                            AND sub.passed
+                    )
+                )
+                FROM exercise_sections es
+                WHERE es.section_id = s.id
+            )
             WHEN 'image' THEN (
             SELECT jsonb_build_object(
                 'url', ims.url, 
@@ -162,12 +197,13 @@ FROM user_section_progress
 WHERE user_id = @user_id::int AND module_id = @module_id::int;
 
 -- name: GetModuleTotalCountByUnitId :one
-SELECT COUNT(*) FROM modules WHERE unit_id = @unit_id::int;
+SELECT COUNT(*) FROM modules WHERE unit_id = @unit_id::int AND deleted_at IS NULL;
 
 -- name: GetNextModuleId :one
 SELECT id
 FROM modules
 WHERE unit_id = @unit_id::int
+  AND deleted_at IS NULL
   AND module_number > @module_number::int
 ORDER BY module_number ASC
 LIMIT 1;
@@ -176,6 +212,7 @@ LIMIT 1;
 SELECT id
 FROM modules
 WHERE unit_id = @unit_id::int
+  AND deleted_at IS NULL
   AND module_number < @module_number::int
 ORDER BY module_number DESC
 LIMIT 1;
@@ -184,6 +221,7 @@ LIMIT 1;
 SELECT id
 FROM units
 WHERE course_id = @course_id::int
+  AND deleted_at IS NULL
   AND unit_number > @unit_number::int
 ORDER BY unit_number ASC
 LIMIT 1;
@@ -192,6 +230,7 @@ LIMIT 1;
 SELECT id
 FROM units
 WHERE course_id = @course_id::int
+  AND deleted_at IS NULL
   AND unit_number < @unit_number::int
 ORDER BY unit_number DESC
 LIMIT 1;
@@ -199,12 +238,13 @@ LIMIT 1;
 -- name: GetUnitNumber :one
 SELECT unit_number
 FROM units
-WHERE id = @unit_id::int;
+WHERE id = @unit_id::int AND deleted_at IS NULL;
 
 -- name: GetNextUnitModuleId :one
 SELECT id
 FROM modules
 WHERE unit_id = @unit_id::int
+  AND deleted_at IS NULL
 ORDER BY module_number ASC
 LIMIT 1;
 
@@ -212,21 +252,24 @@ LIMIT 1;
 SELECT id
 FROM modules
 WHERE unit_id = @unit_id::int
+  AND deleted_at IS NULL
 ORDER BY module_number DESC
 LIMIT 1;
 
 -- name: GetNextModuleIdInUnitOrNextUnit :one
 SELECT id
 FROM modules
-WHERE unit_id = @unit_id::int
+WHERE deleted_at IS NULL
+    AND (unit_id = @unit_id::int
     OR unit_id = (
         SELECT id
         FROM units
         WHERE course_id = @course_id::int
+            AND deleted_at IS NULL
             AND unit_number > @unit_number::int
         ORDER BY unit_number ASC
         LIMIT 1
-    )
+    ))
 ORDER BY module_number ASC
 LIMIT 1;
 
@@ -234,18 +277,25 @@ LIMIT 1;
 SELECT module_number
 FROM modules
 WHERE unit_id = @unit_id::int
+    AND deleted_at IS NULL
     AND module_number > @module_number::int
 ORDER BY module_number ASC
 LIMIT 1;
 
 -- name: GetModuleByID :one
-SELECT * FROM modules WHERE id = @id::int;
+SELECT * FROM modules WHERE id = @id::int AND deleted_at IS NULL;
+
+-- name: GetModuleByNumber :one
+SELECT * FROM modules
+WHERE unit_id = @unit_id::int AND module_number = @module_number::int
+    AND deleted_at IS NULL;
 
 -- name: CreateModule :one
 WITH new_module AS (
     SELECT COALESCE(MAX(module_number), 0) + 1 as next_number
     FROM modules
     WHERE unit_id = @unit_id::int
+        AND deleted_at IS NULL
 )
 INSERT INTO modules (
     module_number,
@@ -272,12 +322,110 @@ SET
     name = COALESCE(NULLIF(@name::text, ''), name),
     description = COALESCE(NULLIF(@description::text, ''), description),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = @module_id::int
+WHERE id = @module_id::int AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteModule :exec
 DELETE FROM modules WHERE id = @module_id::int;
 
+-- name: SoftDeleteModule :execrows
+UPDATE modules
+SET deleted_at = NOW()
+WHERE id = @module_id::int
+    AND deleted_at IS NULL;
+
+-- name: LockModule :one
+SELECT id FROM modules WHERE id = @module_id::int AND deleted_at IS NULL FOR UPDATE;
+
+-- name: DeleteSectionQuestion :exec
+DELETE FROM questions
+WHERE id IN (
+    SELECT question_id FROM question_sections WHERE section_id = @section_id::int
+);
+
+-- name: DeleteSection :exec
+DELETE FROM sections WHERE id = @section_id::int;
+
+-- name: DeleteSectionContent :exec
+WITH
+    deleted_markdown AS (
+        DELETE FROM markdown_sections WHERE section_id = @section_id::int
+    ),
+    deleted_code AS (
+        DELETE FROM code_sections WHERE section_id = @section_id::int
+    ),
+    deleted_video AS (
+        DELETE FROM video_sections WHERE section_id = @section_id::int
+    ),
+    deleted_lottie AS (
+        DELETE FROM lottie_sections WHERE section_id = @section_id::int
+    )
+DELETE FROM image_sections WHERE section_id = @section_id::int;
+
+-- name: ClearSectionPositions :exec
+UPDATE sections SET position = -id WHERE module_id = @module_id::int;
+
+-- name: SetSectionPosition :exec
+UPDATE sections
+SET
+    position = @position::int,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @section_id::int;
+
+-- name: GetCourseModules :many
+SELECT m.id, m.unit_id, m.module_number
+FROM modules m
+JOIN units u ON u.id = m.unit_id
+WHERE u.course_id = @course_id::int
+    AND u.deleted_at IS NULL
+    AND m.deleted_at IS NULL
+ORDER BY u.unit_number, m.module_number;
+
+-- name: ShiftModuleNumbers :exec
+UPDATE modules
+SET module_number = module_number + @offset::int
+WHERE unit_id = @unit_id::int AND deleted_at IS NULL;
+
+-- name: SetModuleNumber :exec
+UPDATE modules
+SET
+    unit_id = @unit_id::int,
+    module_number = @module_number::int,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @module_id::int;
+
+-- name: UpdateQuestion :exec
+UPDATE questions
+SET
+    type = @type,
+    question = @question,
+    answer_schema = @answer_schema,
+    explanation = @explanation,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @question_id::int;
+
+-- name: UpdateQuestionSection :exec
+UPDATE question_sections
+SET
+    object_key = @object_key,
+    media_ext = @media_ext
+WHERE section_id = @section_id::int;
+
+-- name: UpdateQuestionOption :exec
+UPDATE question_options
+SET
+    content = @content,
+    is_correct = @is_correct,
+    feedback = @feedback,
+    position = @position
+WHERE id = @option_id::int;
+
+-- name: DeleteQuestionOption :exec
+DELETE FROM question_options WHERE id = @option_id::int;
+
+-- name: DeleteQuestionTags :exec
+DELETE FROM question_tags WHERE question_id = @question_id::int;
+
 -- name: GetModulesList :many
 SELECT
     m.*,
@@ -295,15 +443,17 @@ SELECT
 FROM modules m
 LEFT JOIN user_module_progress ump ON ump.module_id = m.id AND ump.user_id = @user_id::int
 WHERE m.unit_id = @unit_id::int
+    AND m.deleted_at IS NULL
 ORDER BY m.module_number
 LIMIT @page_size::int
 OFFSET @page_offset::int;
 
 -- name: GetLastModuleNumber :one
-SELECT COALESCE(MAX(module_number), 0) as last_number
+SELECT COALESCE(MAX(module_number), 0)::int as last_number
 FROM modules
 WHERE
-    unit_id = @unit_id::int;
+    unit_id = @unit_id::int
+    AND deleted_at IS NULL;
 
 -- name: InsertModule :one
 INSERT INTO
@@ -392,9 +542,11 @@ INSERT INTO
     questions (
         type,
         question,
-        difficulty_level
+        difficulty_level,
+        answer_schema,
+        explanation
     )
-VALUES ($1, $2, $3) RETURNING *;
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
 
 -- name: InsertQuestionSection :exec
 INSERT INTO
@@ -406,9 +558,11 @@ INSERT INTO
     question_options (
         question_id,
         content,
-        is_correct
+        is_correct,
+        feedback,
+        position
     )
-VALUES ($1, $2, $3);
+VALUES ($1, $2, $3, $4, $5);
 
 -- name: InsertCodeSection :exec
 INSERT INTO
@@ -431,7 +585,8 @@ SELECT u.course_id, m.unit_id
 FROM modules m
     JOIN units u ON m.unit_id = u.id
 WHERE
-    m.id = $1;
+    m.id = $1
+    AND m.deleted_at IS NULL;
 
 -- name: UpsertUserModuleProgress :one
 INSERT INTO user_module_progress (
@@ -475,12 +630,21 @@ SET
     has_seen = EXCLUDED.has_seen,
     seen_at = EXCLUDED.seen_at;
 
--- name: UpsertQuestionAnswer :exec
+-- name: UpsertQuestionAnswer :one
+-- Saves a learner's answer to a question. changed is false when it repeats
+-- the answer already saved, which does not count as another attempt.
+WITH previous AS (
+    SELECT answer
+    FROM user_question_answers
+    WHERE user_module_progress_id = $1
+        AND question_id = $2
+)
 INSERT INTO
     user_question_answers (
         user_module_progress_id,
         question_id,
         option_id,
+        answer,
         is_correct,
         answered_at
     )
@@ -489,7 +653,8 @@ VALUES (
         $2,
         $3,
         $4,
-        COALESCE($5, NOW())
+        $5,
+        COALESCE($6, NOW())
     ) ON CONFLICT (
         user_module_progress_id,
         question_id
@@ -497,9 +662,39 @@ VALUES (
 UPDATE
 SET
     option_id = EXCLUDED.option_id,
+    answer = EXCLUDED.answer,
     is_correct = EXCLUDED.is_correct,
     answered_at = EXCLUDED.answered_at,
-    updated_at = NOW();
+    attempts = user_question_answers.attempts + CASE
+        WHEN user_question_answers.answer = EXCLUDED.answer THEN 0
+        ELSE 1
+    END,
+    updated_at = NOW()
+RETURNING
+    attempts,
+    NOT EXISTS (
+        SELECT 1
+        FROM previous
+        WHERE previous.answer = user_question_answers.answer
+    ) AS changed;
+
+-- name: GetModuleQuestion :one
+SELECT
+    q.id,
+    q.type,
+    q.answer_schema,
+    q.explanation
+FROM questions q
+JOIN question_sections qs ON qs.question_id = q.id
+JOIN sections s ON s.id = qs.section_id
+WHERE q.id = @question_id::int
+    AND s.module_id = @module_id::int;
+
+-- name: GetQuestionOptions :many
+SELECT id, question_id, content, is_correct, feedback, position
+FROM question_options
+WHERE question_id = $1
+ORDER BY position, id;
 
 -- name: CalculateModuleProgress :one
 SELECT
@@ -509,6 +704,14 @@ SELECT
             COUNT(CASE
                 WHEN s.type = 'question' THEN
                     CASE WHEN uqa.is_correct THEN 1 END
+                WHEN s.type = 'exercise' THEN
+                    CASE WHEN EXISTS (
+                        SELECT 1
+                        FROM exercise_submissions sub
+                        WHERE sub.section_id = s.id
+                            AND sub.user_id = $1
+                            AND sub.passed
+                    ) THEN 1 END
                 ELSE
                     CASE WHEN usp.has_seen THEN 1 END
             END)::FLOAT / COUNT(*)::FLOAT
@@ -539,7 +742,9 @@ JOIN units u ON m.unit_id = u.id
 LEFT JOIN user_module_progress ump
     ON ump.module_id = m.id
     AND ump.user_id = $1
-WHERE u.course_id = $2;
+WHERE u.course_id = $2
+    AND u.deleted_at IS NULL
+    AND m.deleted_at IS NULL;
 
 -- name: UpsertUserCourse :exec
 INSERT INTO
@@ -574,5 +779,6 @@ SELECT EXISTS (
 SELECT id
 FROM modules
 WHERE unit_id = @unit_id::int
+  AND deleted_at IS NULL
 ORDER BY module_number ASC
 LIMIT 1;
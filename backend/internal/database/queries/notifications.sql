@@ -1,2 +1,53 @@
--- name: GetAllNotifications :many
-SELECT * FROM notifications;
\ No newline at end of file
+-- name: GetUserNotifications :many
+SELECT *
+FROM notifications
+WHERE user_id = @user_id::int
+    AND (@cursor::int = 0 OR id < @cursor::int)
+    AND (NOT @unread_only::boolean OR read = FALSE)
+ORDER BY id DESC
+LIMIT @page_limit::int;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = @user_id::int
+    AND read = FALSE;
+
+-- name: CreateNotification :one
+INSERT INTO notifications (user_id, content)
+VALUES (@user_id::int, @content::text)
+RETURNING *;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET
+    read = TRUE,
+    updated_at = NOW()
+WHERE id = @id::int
+    AND user_id = @user_id::int;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET
+    read = TRUE,
+    updated_at = NOW()
+WHERE user_id = @user_id::int
+    AND read = FALSE;
+
+-- name: DeleteNotification :execrows
+DELETE FROM notifications
+WHERE id = @id::int
+    AND user_id = @user_id::int;
+
+-- name: GetNotificationByID :one
+SELECT *
+FROM notifications
+WHERE id = @id::int;
+
+-- name: GetNotificationsAfter :many
+SELECT *
+FROM notifications
+WHERE user_id = @user_id::int
+    AND id > @after_id::int
+ORDER BY id ASC
+LIMIT @page_limit::int;
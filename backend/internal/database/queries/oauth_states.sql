@@ -0,0 +1,15 @@
+-- name: CreateOAuthState :exec
+INSERT INTO oauth_states (id, provider, code_verifier, client_state, expires_at, link_user_id)
+VALUES (@id::uuid, @provider::text, @code_verifier::text, @client_state::text, @expires_at::timestamptz,
+    sqlc.narg(link_user_id)::int);
+
+-- name: ConsumeOAuthState :one
+DELETE FROM oauth_states
+WHERE id = @id::uuid
+    AND provider = @provider::text
+    AND expires_at > NOW()
+RETURNING code_verifier, client_state, link_user_id;
+
+-- name: DeleteExpiredOAuthStates :exec
+DELETE FROM oauth_states
+WHERE expires_at < NOW();
@@ -0,0 +1,22 @@
+-- name: TakeRateLimitToken :one
+-- Refills the bucket for the time since it was last used and takes a token
+-- if a whole one is left. allowed records whether one was taken.
+INSERT INTO rate_limit_buckets AS b (key, capacity, refill_rate, tokens, allowed, updated_at)
+VALUES (@key::text, @capacity::float8, @refill_rate::float8, @capacity::float8 - 1, TRUE, NOW())
+ON CONFLICT (key) DO UPDATE
+SET
+    capacity = EXCLUDED.capacity,
+    refill_rate = EXCLUDED.refill_rate,
+    allowed = LEAST(EXCLUDED.capacity, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * EXCLUDED.refill_rate) >= 1,
+    tokens = LEAST(EXCLUDED.capacity, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * EXCLUDED.refill_rate)
+        - CASE
+            WHEN LEAST(EXCLUDED.capacity, b.tokens + EXTRACT(EPOCH FROM NOW() - b.updated_at) * EXCLUDED.refill_rate) >= 1 THEN 1
+            ELSE 0
+        END,
+    updated_at = NOW()
+RETURNING allowed, tokens;
+
+-- name: DeleteFullRateLimitBuckets :execrows
+-- A full bucket behaves exactly like a missing one.
+DELETE FROM rate_limit_buckets
+WHERE tokens + EXTRACT(EPOCH FROM NOW() - updated_at) * refill_rate >= capacity;
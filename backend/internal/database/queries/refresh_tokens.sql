@@ -0,0 +1,33 @@
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, device_name, user_agent, ip_address, mfa_verified)
+VALUES (@user_id::int, @family_id::uuid, @token_hash::text, @expires_at::timestamptz,
+    sqlc.narg(device_name)::text, sqlc.narg(user_agent)::text, sqlc.narg(ip_address)::text, @mfa_verified::boolean)
+RETURNING *;
+
+-- name: GetRefreshTokenByHashForUpdate :one
+SELECT *
+FROM refresh_tokens
+WHERE token_hash = @token_hash::text
+FOR UPDATE;
+
+-- name: MarkRefreshTokenUsed :exec
+UPDATE refresh_tokens
+SET used_at = NOW()
+WHERE id = @id::int;
+
+-- name: RevokeRefreshTokenFamily :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE family_id = @family_id::uuid
+    AND user_id = @user_id::int
+    AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshTokens :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE user_id = @user_id::int
+    AND revoked_at IS NULL;
+
+-- name: DeleteExpiredRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < NOW();
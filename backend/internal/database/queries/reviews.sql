@@ -0,0 +1,116 @@
+-- name: GetQuestionReviewForUpdate :one
+SELECT r.*
+FROM question_reviews r
+WHERE r.user_id = @user_id::int
+    AND r.question_id = @question_id::int
+    AND EXISTS (
+        SELECT 1
+        FROM question_sections qs
+        JOIN sections s ON s.id = qs.section_id
+        JOIN modules m ON m.id = s.module_id
+        JOIN units u ON u.id = m.unit_id
+        JOIN courses c ON c.id = u.course_id
+        WHERE qs.question_id = r.question_id
+            AND m.deleted_at IS NULL
+            AND u.deleted_at IS NULL
+            AND c.deleted_at IS NULL
+    )
+FOR UPDATE OF r;
+
+-- name: UpsertQuestionReview :one
+INSERT INTO question_reviews (
+    user_id,
+    question_id,
+    ease_factor,
+    interval_days,
+    repetitions,
+    lapses,
+    due_at,
+    reviewed_at
+)
+VALUES (
+    @user_id::int,
+    @question_id::int,
+    @ease_factor::float8,
+    @interval_days::int,
+    @repetitions::int,
+    @lapses::int,
+    @due_at::timestamptz,
+    @reviewed_at::timestamptz
+)
+ON CONFLICT (user_id, question_id) DO UPDATE SET
+    ease_factor = EXCLUDED.ease_factor,
+    interval_days = EXCLUDED.interval_days,
+    repetitions = EXCLUDED.repetitions,
+    lapses = EXCLUDED.lapses,
+    due_at = EXCLUDED.due_at,
+    reviewed_at = EXCLUDED.reviewed_at,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetDueQuestionReviews :many
+SELECT
+    r.question_id,
+    r.ease_factor,
+    r.interval_days,
+    r.repetitions,
+    r.lapses,
+    r.due_at,
+    q.question,
+    q.type,
+    jsonb_array_length(COALESCE(q.answer_schema->'blanks', '[]'::jsonb))::int AS blank_count,
+    COALESCE((
+        SELECT json_agg(
+            json_build_object(
+                'id', qo.id,
+                'content', qo.content
+            ) ORDER BY qo.id
+        )
+        FROM question_options qo
+        WHERE qo.question_id = q.id
+    ), '[]'::json)::json AS question_options
+FROM question_reviews r
+JOIN questions q ON q.id = r.question_id
+WHERE r.user_id = @user_id::int
+    AND r.due_at <= NOW()
+    AND EXISTS (
+        SELECT 1
+        FROM question_sections qs
+        JOIN sections s ON s.id = qs.section_id
+        JOIN modules m ON m.id = s.module_id
+        JOIN units u ON u.id = m.unit_id
+        JOIN courses c ON c.id = u.course_id
+        WHERE qs.question_id = r.question_id
+            AND m.deleted_at IS NULL
+            AND u.deleted_at IS NULL
+            AND c.deleted_at IS NULL
+    )
+ORDER BY r.due_at, r.question_id
+LIMIT @row_limit::int;
+
+-- name: CountDueQuestionReviews :one
+SELECT COUNT(*)
+FROM question_reviews r
+WHERE r.user_id = @user_id::int
+    AND r.due_at <= NOW()
+    AND EXISTS (
+        SELECT 1
+        FROM question_sections qs
+        JOIN sections s ON s.id = qs.section_id
+        JOIN modules m ON m.id = s.module_id
+        JOIN units u ON u.id = m.unit_id
+        JOIN courses c ON c.id = u.course_id
+        WHERE qs.question_id = r.question_id
+            AND m.deleted_at IS NULL
+            AND u.deleted_at IS NULL
+            AND c.deleted_at IS NULL
+    );
+
+-- name: GetQuestionAnswerKey :one
+SELECT
+    id,
+    type,
+    answer_schema,
+    explanation
+FROM questions
+WHERE id = @question_id::int;
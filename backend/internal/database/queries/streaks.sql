@@ -0,0 +1,72 @@
+-- name: GetOpenStreakForUpdate :one
+SELECT *
+FROM streaks
+WHERE user_id = @user_id::int
+    AND end_date IS NULL
+ORDER BY start_date DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetLatestStreak :one
+SELECT *
+FROM streaks
+WHERE user_id = @user_id::int
+ORDER BY start_date DESC
+LIMIT 1;
+
+-- name: GetLongestStreak :one
+SELECT COALESCE(MAX(longest_streak), 0)::int
+FROM streaks
+WHERE user_id = @user_id::int;
+
+-- name: CreateStreak :one
+-- Users have one open streak at most. If another transaction opened it
+-- first, that streak is returned instead.
+INSERT INTO streaks (user_id, start_date, current_streak, longest_streak)
+VALUES (@user_id::int, @start_date::date, 1, @longest_streak::int)
+ON CONFLICT (user_id) WHERE end_date IS NULL DO UPDATE SET updated_at = NOW()
+RETURNING *;
+
+-- name: ExtendStreak :one
+UPDATE streaks
+SET
+    current_streak = current_streak + 1,
+    longest_streak = GREATEST(longest_streak, current_streak + 1),
+    updated_at = NOW()
+WHERE id = @id::int
+RETURNING *;
+
+-- name: CloseStreak :exec
+UPDATE streaks
+SET
+    end_date = @end_date::date,
+    updated_at = NOW()
+WHERE id = @id::int;
+
+-- name: CloseBrokenStreaks :execrows
+-- Timezones Postgres does not know count as UTC, so a bad preference cannot
+-- fail the whole job.
+WITH zones AS (
+    SELECT name FROM pg_timezone_names
+),
+closed AS (
+    UPDATE streaks s
+    SET
+        end_date = s.start_date + (s.current_streak - 1),
+        updated_at = NOW()
+    FROM users u
+    LEFT JOIN user_preferences up ON up.user_id = u.id
+    LEFT JOIN zones z ON z.name = up.timezone
+    WHERE s.user_id = u.id
+        AND s.end_date IS NULL
+        AND s.start_date + s.current_streak < (NOW() AT TIME ZONE COALESCE(z.name, 'UTC'))::date
+    RETURNING s.user_id
+)
+UPDATE users
+SET streak = 0
+WHERE id IN (SELECT user_id FROM closed);
+
+-- name: GetUserTimezone :one
+SELECT timezone
+FROM user_preferences
+WHERE user_id = @user_id::int;
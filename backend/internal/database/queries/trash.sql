@@ -0,0 +1,173 @@
+-- name: GetTrash :many
+-- Lists what was deleted on its own. Units and modules deleted along with
+-- their course or unit share its deleted_at and come back with it.
+SELECT 'course'::text AS kind, c.id, c.name, c.id AS course_id, 0::int AS unit_id, c.deleted_at::timestamptz AS deleted_at
+FROM courses c
+WHERE c.deleted_at IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id)
+UNION ALL
+SELECT 'unit'::text AS kind, u.id, u.name, u.course_id, 0::int AS unit_id, u.deleted_at::timestamptz AS deleted_at
+FROM units u
+JOIN courses c ON c.id = u.course_id
+WHERE u.deleted_at IS NOT NULL
+    AND u.deleted_at IS DISTINCT FROM c.deleted_at
+UNION ALL
+SELECT 'module'::text AS kind, m.id, m.name, u.course_id, m.unit_id, m.deleted_at::timestamptz AS deleted_at
+FROM modules m
+JOIN units u ON u.id = m.unit_id
+WHERE m.deleted_at IS NOT NULL
+    AND m.deleted_at IS DISTINCT FROM u.deleted_at
+ORDER BY deleted_at DESC;
+
+-- name: GetTrashedCourse :one
+SELECT c.id, c.deleted_at::timestamptz AS deleted_at
+FROM courses c
+WHERE c.id = @course_id::int
+    AND c.deleted_at IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM course_versions cv WHERE cv.snapshot_course_id = c.id);
+
+-- name: GetTrashedUnit :one
+SELECT
+    u.id,
+    u.course_id,
+    u.deleted_at::timestamptz AS deleted_at,
+    (c.deleted_at IS NOT NULL)::boolean AS course_deleted
+FROM units u
+JOIN courses c ON c.id = u.course_id
+WHERE u.id = @unit_id::int
+    AND u.deleted_at IS NOT NULL;
+
+-- name: GetTrashedModule :one
+SELECT
+    m.id,
+    m.unit_id,
+    u.course_id,
+    m.deleted_at::timestamptz AS deleted_at,
+    (u.deleted_at IS NOT NULL)::boolean AS unit_deleted
+FROM modules m
+JOIN units u ON u.id = m.unit_id
+WHERE m.id = @module_id::int
+    AND m.deleted_at IS NOT NULL;
+
+-- name: RestoreCourse :exec
+-- Restores the course and its snapshots with the units and modules that
+-- were deleted along with them.
+WITH restored_courses AS (
+    UPDATE courses
+    SET deleted_at = NULL
+    WHERE deleted_at = @deleted_at::timestamptz
+        AND (
+            id = @course_id::int
+            OR id IN (SELECT snapshot_course_id FROM course_versions WHERE course_id = @course_id::int)
+        )
+    RETURNING id
+),
+restored_units AS (
+    UPDATE units
+    SET deleted_at = NULL
+    WHERE deleted_at = @deleted_at::timestamptz
+        AND course_id IN (SELECT id FROM restored_courses)
+    RETURNING id
+)
+UPDATE modules
+SET deleted_at = NULL
+WHERE deleted_at = @deleted_at::timestamptz
+    AND unit_id IN (SELECT id FROM restored_units);
+
+-- name: RestoreUnit :exec
+-- The unit keeps its number unless another unit has taken it, in which case
+-- it goes at the end of the course.
+WITH restored_unit AS (
+    UPDATE units u
+    SET
+        deleted_at = NULL,
+        unit_number = CASE
+            WHEN EXISTS (
+                SELECT 1
+                FROM units l
+                WHERE l.course_id = u.course_id
+                    AND l.unit_number = u.unit_number
+                    AND l.deleted_at IS NULL
+            ) THEN (
+                SELECT COALESCE(MAX(l.unit_number), 0) + 1
+                FROM units l
+                WHERE l.course_id = u.course_id
+                    AND l.deleted_at IS NULL
+            )
+            ELSE u.unit_number
+        END
+    WHERE u.id = @unit_id::int
+        AND u.deleted_at = @deleted_at::timestamptz
+    RETURNING u.id
+)
+UPDATE modules
+SET deleted_at = NULL
+WHERE deleted_at = @deleted_at::timestamptz
+    AND unit_id IN (SELECT id FROM restored_unit);
+
+-- name: RestoreModule :exec
+-- The module keeps its number unless another module has taken it, in which
+-- case it goes at the end of the unit.
+UPDATE modules m
+SET
+    deleted_at = NULL,
+    module_number = CASE
+        WHEN EXISTS (
+            SELECT 1
+            FROM modules l
+            WHERE l.unit_id = m.unit_id
+                AND l.module_number = m.module_number
+                AND l.deleted_at IS NULL
+        ) THEN (
+            SELECT COALESCE(MAX(l.module_number), 0) + 1
+            FROM modules l
+            WHERE l.unit_id = m.unit_id
+                AND l.deleted_at IS NULL
+        )
+        ELSE m.module_number
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE m.id = @module_id::int
+    AND m.deleted_at = @deleted_at::timestamptz;
+
+-- name: GetExpiredTrashFolders :many
+-- Lists the media folders of everything deleted before the cutoff, along
+-- with those of the units and modules that go with it.
+SELECT 'courses'::text AS resource, c.folder_object_key
+FROM courses c
+WHERE c.deleted_at < @before::timestamptz
+    AND c.folder_object_key IS NOT NULL
+UNION
+SELECT 'units'::text AS resource, u.folder_object_key
+FROM units u
+JOIN courses c ON c.id = u.course_id
+WHERE (u.deleted_at < @before::timestamptz OR c.deleted_at < @before::timestamptz)
+    AND u.folder_object_key IS NOT NULL
+UNION
+SELECT 'modules'::text AS resource, m.folder_object_key
+FROM modules m
+JOIN units u ON u.id = m.unit_id
+JOIN courses c ON c.id = u.course_id
+WHERE (
+        m.deleted_at < @before::timestamptz
+        OR u.deleted_at < @before::timestamptz
+        OR c.deleted_at < @before::timestamptz
+    )
+    AND m.folder_object_key IS NOT NULL;
+
+-- name: PurgeTrashedCourses :execrows
+DELETE FROM courses WHERE deleted_at < @before::timestamptz;
+
+-- name: PurgeTrashedUnits :execrows
+DELETE FROM units WHERE deleted_at < @before::timestamptz;
+
+-- name: PurgeTrashedModules :execrows
+DELETE FROM modules WHERE deleted_at < @before::timestamptz;
+
+-- name: IsMediaFolderInUse :one
+-- Snapshots and the course they were published from share media folders.
+SELECT (
+    EXISTS (SELECT 1 FROM courses WHERE folder_object_key = @folder_object_key::uuid)
+    OR EXISTS (SELECT 1 FROM units WHERE folder_object_key = @folder_object_key::uuid)
+    OR EXISTS (SELECT 1 FROM modules WHERE folder_object_key = @folder_object_key::uuid)
+)::boolean AS in_use;
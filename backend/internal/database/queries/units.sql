@@ -20,27 +20,60 @@ VALUES (
 RETURNING id;
 
 -- name: GetUnitsCount :one
-SELECT COUNT(*) FROM units;
+SELECT COUNT(*) FROM units WHERE deleted_at IS NULL;
 
 -- name: GetUnitByID :one
 SELECT * FROM units
-WHERE id = @unit_id::int;
+WHERE id = @unit_id::int AND deleted_at IS NULL;
+
+-- name: GetCourseUnit :one
+SELECT * FROM units
+WHERE id = @unit_id::int AND course_id = @course_id::int AND deleted_at IS NULL;
+
+-- name: GetLastUnitNumber :one
+SELECT COALESCE(MAX(unit_number), 0)::int as last_number
+FROM units
+WHERE course_id = @course_id::int AND deleted_at IS NULL;
 
 -- name: GetUnitsByCourseID :many
 SELECT * FROM units
-WHERE course_id = @course_id::int;
+WHERE course_id = @course_id::int AND deleted_at IS NULL;
 
 -- name: UpdateUnit :exec
 UPDATE units
 SET name = @name::text,
     description = @description::text
-WHERE id = @unit_id::int;
+WHERE id = @unit_id::int AND deleted_at IS NULL;
 
 -- name: UpdateUnitNumber :exec
 UPDATE units
 SET unit_number = @unit_number::int
-WHERE id = @unit_id::int;
+WHERE id = @unit_id::int AND deleted_at IS NULL;
+
+-- name: ShiftUnitNumbers :exec
+UPDATE units
+SET unit_number = unit_number + @offset::int
+WHERE course_id = @course_id::int AND deleted_at IS NULL;
 
 -- name: DeleteUnit :exec
 DELETE FROM units
-WHERE id = @unit_id::int;
\ No newline at end of file
+WHERE id = @unit_id::int;
+
+-- name: SoftDeleteUnit :one
+-- Stamps the unit and its modules with the same deleted_at, and returns how
+-- many units that was.
+WITH deleted_unit AS (
+    UPDATE units
+    SET deleted_at = NOW()
+    WHERE id = @unit_id::int
+        AND deleted_at IS NULL
+    RETURNING id, deleted_at
+),
+deleted_modules AS (
+    UPDATE modules m
+    SET deleted_at = du.deleted_at
+    FROM deleted_unit du
+    WHERE m.unit_id = du.id
+        AND m.deleted_at IS NULL
+)
+SELECT COUNT(*) FROM deleted_unit;
@@ -0,0 +1,19 @@
+-- name: CreateUserActionToken :exec
+INSERT INTO user_action_tokens (id, user_id, purpose, expires_at, email)
+VALUES (@id::uuid, @user_id::int, @purpose::text, @expires_at::timestamptz, sqlc.narg(email)::text);
+
+-- name: ConsumeUserActionToken :one
+UPDATE user_action_tokens
+SET used_at = NOW()
+WHERE id = @id::uuid
+    AND purpose = @purpose::text
+    AND used_at IS NULL
+    AND expires_at > NOW()
+RETURNING user_id, email;
+
+-- name: InvalidateUserActionTokens :exec
+UPDATE user_action_tokens
+SET used_at = NOW()
+WHERE user_id = @user_id::int
+    AND purpose = @purpose::text
+    AND used_at IS NULL;
@@ -0,0 +1,32 @@
+-- name: GetUserIdentity :one
+SELECT *
+FROM user_identities
+WHERE provider = @provider::text
+    AND subject = @subject::text;
+
+-- name: GetUserIdentities :many
+SELECT *
+FROM user_identities
+WHERE user_id = @user_id::int
+ORDER BY created_at;
+
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (user_id, provider, subject, email)
+VALUES (@user_id::int, @provider::text, @subject::text, sqlc.narg(email)::text)
+RETURNING *;
+
+-- name: CountUserIdentities :one
+SELECT COUNT(*)::int
+FROM user_identities
+WHERE user_id = @user_id::int;
+
+-- name: DeleteUserIdentity :execrows
+DELETE FROM user_identities
+WHERE user_id = @user_id::int
+    AND provider = @provider::text;
+
+-- name: GetUserLoginForUpdate :one
+SELECT id, password_hash
+FROM users
+WHERE id = @id::int
+FOR UPDATE;
@@ -0,0 +1,52 @@
+-- name: SetPendingUserTOTPSecret :execrows
+-- Starts or restarts enrolment; does nothing once MFA is enabled.
+INSERT INTO user_mfa (user_id, totp_secret)
+VALUES (@user_id::int, @totp_secret::text)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    totp_secret = EXCLUDED.totp_secret,
+    last_used_step = 0,
+    created_at = NOW()
+WHERE user_mfa.enabled_at IS NULL;
+
+-- name: GetUserMFA :one
+SELECT user_id, totp_secret, last_used_step, enabled_at, created_at
+FROM user_mfa
+WHERE user_id = @user_id::int;
+
+-- name: EnableUserMFA :execrows
+UPDATE user_mfa
+SET enabled_at = NOW()
+WHERE user_id = @user_id::int
+    AND enabled_at IS NULL;
+
+-- name: UseUserTOTPStep :execrows
+UPDATE user_mfa
+SET last_used_step = @step::bigint
+WHERE user_id = @user_id::int
+    AND last_used_step < @step::bigint;
+
+-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa
+WHERE user_id = @user_id::int;
+
+-- name: CreateUserRecoveryCode :exec
+INSERT INTO user_recovery_codes (user_id, code_hash)
+VALUES (@user_id::int, @code_hash::text);
+
+-- name: UseUserRecoveryCode :execrows
+UPDATE user_recovery_codes
+SET used_at = NOW()
+WHERE user_id = @user_id::int
+    AND code_hash = @code_hash::text
+    AND used_at IS NULL;
+
+-- name: CountUnusedUserRecoveryCodes :one
+SELECT COUNT(*)
+FROM user_recovery_codes
+WHERE user_id = @user_id::int
+    AND used_at IS NULL;
+
+-- name: DeleteUserRecoveryCodes :exec
+DELETE FROM user_recovery_codes
+WHERE user_id = @user_id::int;
@@ -86,6 +86,7 @@ UPDATE users
 SET
     username = COALESCE(NULLIF(@username::text, ''), username),
     email = COALESCE(NULLIF(@email::text, ''), email),
+    is_email_verified = is_email_verified AND COALESCE(NULLIF(@email::text, ''), email) = email,
     first_name = COALESCE(NULLIF(@first_name::text, ''), first_name),
     last_name = COALESCE(NULLIF(@last_name::text, ''), last_name),
     bio = COALESCE(NULLIF(@bio::text, ''), bio),
@@ -187,3 +188,20 @@ DELETE FROM users WHERE id = @id;
 -- name: GetReceivedAchievementsCount :one
 SELECT COUNT(*) FROM user_achievements;
 
+
+-- name: SetUserEmailVerified :execrows
+-- Marks email as verified if it is still the user's address.
+UPDATE users
+SET
+    is_email_verified = TRUE,
+    verified_email = email,
+    updated_at = NOW()
+WHERE id = @id::int
+    AND email = @email::text;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET
+    password_hash = @password_hash::text,
+    updated_at = NOW()
+WHERE id = @id::int;
@@ -26,6 +26,10 @@ const (
 	ContentAlreadyExists ErrorCode = "CONTENT_ALREADY_EXISTS"
 	TokenExpired         ErrorCode = "TOKEN_EXPIRED"
 	DuplicateValue       ErrorCode = "DUPLICATE_VALUE"
+	AccountLocked        ErrorCode = "ACCOUNT_LOCKED"
+	RateLimited          ErrorCode = "RATE_LIMITED"
+	InvalidMFACode       ErrorCode = "INVALID_MFA_CODE"
+	ServiceUnavailable   ErrorCode = "SERVICE_UNAVAILABLE"
 )
 
 var ErrNotFound = errors.New("item not found")
@@ -19,6 +19,7 @@ type AchievementsHandler interface {
 	CreateAchievement(c *gin.Context)
 	UpdateAchievement(c *gin.Context)
 	DeleteAchievement(c *gin.Context)
+	GetMyAchievements(c *gin.Context)
 	RegisterRoutes(r *gin.RouterGroup)
 }
 
@@ -86,6 +87,12 @@ func (h *achievementsHandler) CreateAchievement(c *gin.Context) {
 		return
 	}
 
+	if err := achievement.Validate(); err != nil {
+		log.WithError(err).Error("invalid achievement")
+		c.JSON(http.StatusBadRequest, models.Response{Success: false, Message: err.Error()})
+		return
+	}
+
 	err = h.repo.CreateAchievement(&achievement)
 	if err != nil {
 		log.WithError(err).Error("failed to create achievement")
@@ -120,6 +127,12 @@ func (h *achievementsHandler) UpdateAchievement(c *gin.Context) {
 		return
 	}
 
+	if err := achievement.Validate(); err != nil {
+		log.WithError(err).Error("invalid achievement")
+		c.JSON(http.StatusBadRequest, models.Response{Success: false, Message: err.Error()})
+		return
+	}
+
 	achievement.ID = int32(id)
 	err = h.repo.UpdateAchievement(&achievement)
 	if err != nil {
@@ -162,6 +175,31 @@ func (h *achievementsHandler) DeleteAchievement(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *achievementsHandler) GetMyAchievements(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetMyAchievements")
+	userID, err := GetUserID(c)
+	if err != nil {
+		log.WithError(err).Error("failed to get user ID")
+		c.JSON(http.StatusUnauthorized, models.Response{Success: false, Message: "unauthorized"})
+		return
+	}
+
+	achievements, err := h.repo.GetUserAchievements(userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get user achievements")
+		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "internal server error"})
+		return
+	}
+
+	response := models.Response{
+		Success: true,
+		Message: "achievements retrieved successfully",
+		Payload: map[string]interface{}{"achievements": achievements},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *achievementsHandler) RegisterRoutes(r *gin.RouterGroup) {
 	public := r.Group("/achievements")
 	authorized := r.Group("/achievements", middleware.Auth())
@@ -169,7 +207,10 @@ func (h *achievementsHandler) RegisterRoutes(r *gin.RouterGroup) {
 	public.GET("", h.GetAllAchievements)
 	public.GET("/:id", h.GetAchievementByID)
 
-	authorized.POST("", h.CreateAchievement)
-	authorized.PUT("/:id", h.UpdateAchievement)
-	authorized.DELETE("/:id", h.DeleteAchievement)
+	authorized.GET("/me", h.GetMyAchievements)
+
+	admins := authorized.Group("", middleware.RequireRole(models.RoleAdmin), middleware.RequireMFA())
+	admins.POST("", h.CreateAchievement)
+	admins.PUT("/:id", h.UpdateAchievement)
+	admins.DELETE("/:id", h.DeleteAchievement)
 }
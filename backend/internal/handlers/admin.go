@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"algolearn/internal/models"
 	"algolearn/internal/service"
 	"algolearn/pkg/security"
 	"net/http"
@@ -21,7 +22,7 @@ func NewAdminHandler(userService service.UserService, courseService service.Cour
 	}, nil
 }
 
-// adminAuthRequired is a middleware to check if user is authenticated and is an admin
+// adminAuthRequired is a middleware to check if user is authenticated with MFA and is an admin
 func (h *AdminHandler) adminAuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip auth for API endpoints that handle their own auth
@@ -59,9 +60,16 @@ func (h *AdminHandler) adminAuthRequired() gin.HandlerFunc {
 			return
 		}
 
+		// The panel is only open to sessions signed in with a second factor
+		if !claims.MFA {
+			c.Redirect(http.StatusFound, "/admin")
+			c.Abort()
+			return
+		}
+
 		// Get user and check if they're an admin
 		user, err := h.userService.GetUserByID(c, claims.UserID)
-		if err != nil || user.Role != "admin" {
+		if err != nil || user.Role != models.RoleAdmin {
 			c.Redirect(http.StatusFound, "/admin")
 			c.Abort()
 			return
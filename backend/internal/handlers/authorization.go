@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/internal/service"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserRole returns the role carried by the caller's access token.
+func GetUserRole(c *gin.Context) string {
+	return c.GetString(middleware.RoleKey)
+}
+
+// RequireCourseAuthor lets admins signed in with MFA through and restricts
+// everyone else to courses they author. The courseId path parameter is
+// required; unitId and moduleId, when present, must belong to that course.
+// Snapshots of published versions are rejected for everyone. It must run
+// after middleware.Auth.
+func RequireCourseAuthor(courseRepo service.CourseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := logger.Get().WithBaseFields(logger.Middleware, "RequireCourseAuthor")
+
+		courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 64)
+		if err != nil || courseID <= 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   "invalid course ID: must be a positive integer",
+			})
+			return
+		}
+
+		// Published versions are never edited, not even by admins.
+		isSnapshot, err := courseRepo.IsCourseSnapshot(c.Request.Context(), courseID)
+		if err != nil {
+			log.WithError(err).Error("failed to check course snapshot")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "internal server error while verifying user permissions",
+			})
+			return
+		}
+		if isSnapshot {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.Response{
+				Success:   false,
+				ErrorCode: httperr.Forbidden,
+				Message:   "published versions of a course cannot be edited",
+			})
+			return
+		}
+
+		// Admins may edit every course, but only from a session that signed
+		// in with a second factor; otherwise they are held to authorship.
+		if GetUserRole(c) == models.RoleAdmin && c.GetBool(middleware.MFAKey) {
+			c.Next()
+			return
+		}
+
+		userID, err := GetUserID(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Response{
+				Success:   false,
+				ErrorCode: httperr.Unauthorized,
+				Message:   "authentication required",
+			})
+			return
+		}
+
+		var unitID, moduleID int64
+		if unitIDStr := c.Param("unitId"); unitIDStr != "" {
+			unitID, err = strconv.ParseInt(unitIDStr, 10, 64)
+			if err != nil || unitID <= 0 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+					Success:   false,
+					ErrorCode: httperr.InvalidInput,
+					Message:   "invalid unit ID: must be a positive integer",
+				})
+				return
+			}
+		}
+		if moduleIDStr := c.Param("moduleId"); moduleIDStr != "" {
+			moduleID, err = strconv.ParseInt(moduleIDStr, 10, 64)
+			if err != nil || moduleID <= 0 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, models.Response{
+					Success:   false,
+					ErrorCode: httperr.InvalidInput,
+					Message:   "invalid module ID: must be a positive integer",
+				})
+				return
+			}
+		}
+
+		isAuthor, err := courseRepo.IsCourseAuthor(c.Request.Context(), userID, courseID, unitID, moduleID)
+		if err != nil {
+			log.WithError(err).Error("failed to check course authorship")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "internal server error while verifying user permissions",
+			})
+			return
+		}
+
+		if !isAuthor {
+			log.Debugf("user %d tried to edit course %d without authoring it", userID, courseID)
+			c.AbortWithStatusJSON(http.StatusForbidden, models.Response{
+				Success:   false,
+				ErrorCode: httperr.Forbidden,
+				Message:   "only the course's authors can edit it",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
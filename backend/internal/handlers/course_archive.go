@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/internal/service"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CourseArchiveHandler interface {
+	ExportCourse(c *gin.Context)
+	ImportCourse(c *gin.Context)
+	RegisterRoutes(r *gin.RouterGroup)
+}
+
+type courseArchiveHandler struct {
+	repo service.CourseArchiveService
+	log  *logger.Logger
+}
+
+func NewCourseArchiveHandler(repo service.CourseArchiveService) CourseArchiveHandler {
+	return &courseArchiveHandler{repo: repo, log: logger.Get()}
+}
+
+func (h *courseArchiveHandler) ExportCourse(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ExportCourse")
+	ctx := c.Request.Context()
+
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 32)
+	if err != nil || courseID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidCourseID,
+			Message:   "invalid course ID: must be a positive integer",
+		})
+		return
+	}
+
+	clearDeadlines(c)
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="course-%d.zip"`, courseID))
+
+	err = h.repo.ExportCourse(ctx, int32(courseID), c.Writer)
+	if err == nil {
+		return
+	}
+
+	// Once the archive has started streaming the status is sent and the
+	// client only sees a truncated download.
+	if c.Writer.Written() {
+		log.WithError(err).Error("failed to export course")
+		return
+	}
+
+	c.Header("Content-Disposition", "")
+	if errors.Is(err, httperr.ErrNotFound) {
+		c.JSON(http.StatusNotFound, models.Response{
+			Success:   false,
+			ErrorCode: httperr.NoData,
+			Message:   "course not found",
+		})
+		return
+	}
+	log.WithError(err).Error("failed to export course")
+	c.JSON(http.StatusInternalServerError, models.Response{
+		Success:   false,
+		ErrorCode: httperr.InternalError,
+		Message:   "failed to export course",
+	})
+}
+
+func (h *courseArchiveHandler) ImportCourse(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ImportCourse")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to import courses",
+		})
+		return
+	}
+
+	dryRun := false
+	if dryRunStr := c.Query("dryRun"); dryRunStr != "" {
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   "invalid dryRun: must be true or false",
+			})
+			return
+		}
+	}
+
+	clearDeadlines(c)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.MaxCourseArchiveBytes)
+
+	fileHeader, err := c.FormFile("archive")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, models.Response{
+				Success:   false,
+				ErrorCode: httperr.ExceededMaxFileSize,
+				Message:   fmt.Sprintf("course archives must not exceed %d MB", models.MaxCourseArchiveBytes>>20),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidFormData,
+			Message:   "an archive file is required",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.WithError(err).Error("failed to open uploaded archive")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.FileUploadFailed,
+			Message:   "failed to read archive",
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.repo.ImportCourse(ctx, file, fileHeader.Size, userID, dryRun)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidArchive) ||
+			errors.Is(err, service.ErrInvalidSectionContent) ||
+			errors.Is(err, service.ErrInvalidQuestion) ||
+			errors.Is(err, service.ErrInvalidExercise) {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   err.Error(),
+			})
+			return
+		}
+		log.WithError(err).Error("failed to import course")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InternalError,
+			Message:   "failed to import course",
+		})
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, models.Response{
+			Success: true,
+			Message: "course archive is valid",
+			Payload: result,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "course imported successfully",
+		Payload: result,
+	})
+}
+
+// clearDeadlines lifts the server's read and write timeouts for a request
+// that moves a whole course archive, which can take minutes. The routes are
+// also exempt from the Timeout middleware.
+func clearDeadlines(c *gin.Context) {
+	log := logger.Get().WithBaseFields(logger.Handler, "clearDeadlines")
+
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		log.WithError(err).Warn("failed to clear read deadline")
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.WithError(err).Warn("failed to clear write deadline")
+	}
+}
+
+func (h *courseArchiveHandler) RegisterRoutes(r *gin.RouterGroup) {
+	admins := r.Group("/courses", middleware.Auth(), middleware.RequireRole(models.RoleAdmin), middleware.RequireMFA())
+	admins.GET("/:courseId/export", h.ExportCourse)
+	admins.POST("/import", h.ImportCourse)
+}
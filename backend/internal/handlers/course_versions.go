@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/internal/service"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CourseVersionHandler interface {
+	GetCourseVersions(c *gin.Context)
+	DiffCourseVersions(c *gin.Context)
+	MigrateCourseProgress(c *gin.Context)
+	RegisterRoutes(r *gin.RouterGroup)
+}
+
+type courseVersionHandler struct {
+	repo service.CourseVersionService
+	log  *logger.Logger
+}
+
+func NewCourseVersionHandler(repo service.CourseVersionService) CourseVersionHandler {
+	return &courseVersionHandler{repo: repo, log: logger.Get()}
+}
+
+func (h *courseVersionHandler) GetCourseVersions(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetCourseVersions")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to access course versions",
+		})
+		return
+	}
+
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 32)
+	if err != nil || courseID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidCourseID,
+			Message:   "invalid course ID: must be a positive integer",
+		})
+		return
+	}
+
+	versions, err := h.repo.GetCourseVersions(ctx, userID, courseID)
+	if err != nil {
+		log.WithError(err).Error("error fetching course versions")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while retrieving course versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "course versions retrieved successfully",
+		Payload: versions,
+	})
+}
+
+func (h *courseVersionHandler) DiffCourseVersions(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "DiffCourseVersions")
+	ctx := c.Request.Context()
+
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 32)
+	if err != nil || courseID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidCourseID,
+			Message:   "invalid course ID: must be a positive integer",
+		})
+		return
+	}
+
+	from, err := strconv.ParseInt(c.Query("from"), 10, 32)
+	if err != nil || from <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid from: must be a positive version number",
+		})
+		return
+	}
+
+	to, err := strconv.ParseInt(c.Query("to"), 10, 32)
+	if err != nil || to <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid to: must be a positive version number",
+		})
+		return
+	}
+
+	diff, err := h.repo.DiffCourseVersions(ctx, courseID, int32(from), int32(to))
+	if err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "course version not found",
+			})
+			return
+		}
+		log.WithError(err).Error("error diffing course versions")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while comparing course versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "course versions compared successfully",
+		Payload: diff,
+	})
+}
+
+func (h *courseVersionHandler) MigrateCourseProgress(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "MigrateCourseProgress")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to migrate course progress",
+		})
+		return
+	}
+
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 32)
+	if err != nil || courseID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidCourseID,
+			Message:   "invalid course ID: must be a positive integer",
+		})
+		return
+	}
+
+	migration, err := h.repo.MigrateCourseProgress(ctx, userID, courseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, httperr.ErrNotFound):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "no published version of the course to migrate to, or not enrolled in it",
+			})
+		case errors.Is(err, service.ErrAlreadyLatestVersion):
+			c.JSON(http.StatusConflict, models.Response{
+				Success:   false,
+				ErrorCode: httperr.ContentAlreadyExists,
+				Message:   err.Error(),
+			})
+		default:
+			log.WithError(err).Error("error migrating course progress")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "internal server error while migrating course progress",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "course progress migrated successfully",
+		Payload: migration,
+	})
+}
+
+func (h *courseVersionHandler) RegisterRoutes(r *gin.RouterGroup) {
+	versions := r.Group("/courses/:courseId/versions", middleware.Auth())
+	versions.GET("", h.GetCourseVersions)
+	versions.GET("/diff", h.DiffCourseVersions)
+	versions.POST("/migrate", h.MigrateCourseProgress)
+}
@@ -7,8 +7,10 @@ import (
 	"algolearn/pkg/logger"
 	"algolearn/pkg/middleware"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -22,6 +24,7 @@ type CourseHandler interface {
 	CreateCourse(c *gin.Context)
 	UpdateCourse(c *gin.Context)
 	PublishCourse(c *gin.Context)
+	CloneCourse(c *gin.Context)
 	GetCourse(c *gin.Context)
 	SearchCourses(c *gin.Context)
 	ListEnrolledCoursesWithProgress(c *gin.Context)
@@ -38,20 +41,35 @@ type CourseHandler interface {
 }
 
 type courseHandler struct {
-	courseRepo service.CourseService
-	userRepo   service.UserService
-	log        *logger.Logger
+	courseRepo  service.CourseService
+	userRepo    service.UserService
+	versionRepo service.CourseVersionService
+	limiter     *middleware.RateLimiter
+	log         *logger.Logger
 }
 
 func NewCourseHandler(courseRepo service.CourseService,
-	userRepo service.UserService) CourseHandler {
+	userRepo service.UserService, versionRepo service.CourseVersionService,
+	limiter *middleware.RateLimiter) CourseHandler {
 	return &courseHandler{
-		courseRepo: courseRepo,
-		userRepo:   userRepo,
-		log:        logger.Get(),
+		courseRepo:  courseRepo,
+		userRepo:    userRepo,
+		versionRepo: versionRepo,
+		limiter:     limiter,
+		log:         logger.Get(),
 	}
 }
 
+// learnerCourseID returns the course a student reads and records progress
+// against when they ask for courseID, which is the published version they
+// are pinned to. Everyone else works with courseID itself.
+func (h *courseHandler) learnerCourseID(c *gin.Context, userID int32, courseID int64) (int64, error) {
+	if GetUserRole(c) != models.RoleStudent {
+		return courseID, nil
+	}
+	return h.versionRepo.GetLearnerCourseID(c.Request.Context(), userID, courseID)
+}
+
 func (h *courseHandler) ListAllCoursesWithOptionalProgress(c *gin.Context) {
 	log := h.log.WithBaseFields(logger.Handler, "ListCourses")
 	ctx := c.Request.Context()
@@ -138,27 +156,6 @@ func (h *courseHandler) CreateCourse(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.GetUserByID(ctx, int32(userID))
-	if err != nil {
-		log.WithError(err).Error("error fetching user data")
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Success:   false,
-			ErrorCode: httperr.DatabaseFail,
-			Message:   "internal server error while verifying user permissions",
-		})
-		return
-	}
-
-	if user.Role != "instructor" && user.Role != "admin" {
-		log.Debug("non-instructor user tried to create a course")
-		c.JSON(http.StatusForbidden, models.Response{
-			Success:   false,
-			ErrorCode: httperr.Forbidden,
-			Message:   "only instructors can create courses",
-		})
-		return
-	}
-
 	course := models.Course{}
 	if err := c.ShouldBindJSON(&course); err != nil {
 		log.WithError(err).Error("error binding course data")
@@ -173,7 +170,7 @@ func (h *courseHandler) CreateCourse(c *gin.Context) {
 	fmt.Println("course UUID: ", course.FolderObjectKey)
 	fmt.Println("course imgKey: ", course.ImgKey)
 
-	createdCourse, err := h.courseRepo.CreateCourse(ctx, course)
+	createdCourse, err := h.courseRepo.CreateCourse(ctx, course, userID)
 	if err != nil {
 		log.WithError(err).Error("error creating course")
 		c.JSON(http.StatusInternalServerError, models.Response{
@@ -247,7 +244,26 @@ func (h *courseHandler) PublishCourse(c *gin.Context) {
 		return
 	}
 
-	if err := h.courseRepo.PublishCourse(ctx, courseID); err != nil {
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to publish a course",
+		})
+		return
+	}
+
+	version, err := h.versionRepo.PublishCourse(ctx, courseID, userID)
+	if err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "course not found",
+			})
+			return
+		}
 		log.WithError(err).Error("error publishing course")
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Success:   false,
@@ -257,9 +273,70 @@ func (h *courseHandler) PublishCourse(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.Response{
+	c.JSON(http.StatusCreated, models.Response{
 		Success: true,
 		Message: "course published successfully",
+		Payload: version,
+	})
+}
+
+func (h *courseHandler) CloneCourse(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "CloneCourse")
+	ctx := c.Request.Context()
+
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 64)
+	if err != nil || courseID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid course ID: must be a positive integer",
+		})
+		return
+	}
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to clone a course",
+		})
+		return
+	}
+
+	var req models.CloneRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	course, err := h.courseRepo.CloneCourse(ctx, courseID, req.Name, userID)
+	if err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "course not found",
+			})
+			return
+		}
+		log.WithError(err).Error("error cloning course")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while cloning course",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "course cloned successfully",
+		Payload: course,
 	})
 }
 
@@ -277,6 +354,18 @@ func (h *courseHandler) GetCourse(c *gin.Context) {
 		return
 	}
 
+	if userID, err := GetUserID(c); err == nil {
+		if courseID, err = h.learnerCourseID(c, userID, courseID); err != nil {
+			log.WithError(err).Error("error resolving course version")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "internal server error while retrieving course",
+			})
+			return
+		}
+	}
+
 	course, err := h.courseRepo.GetCourse(ctx, courseID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -387,6 +476,17 @@ func (h *courseHandler) GetCourseProgress(c *gin.Context) {
 		return
 	}
 
+	courseID, err = h.learnerCourseID(c, userID, courseID)
+	if err != nil {
+		log.WithError(err).Error("error resolving course version")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while retrieving course progress",
+		})
+		return
+	}
+
 	course, err := h.courseRepo.GetCourseWithProgress(ctx, int64(userID), courseID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -448,6 +548,17 @@ func (h *courseHandler) StartCourse(c *gin.Context) {
 		return
 	}
 
+	courseID, err = h.learnerCourseID(c, userID, courseID)
+	if err != nil {
+		log.WithError(err).Error("error resolving course version")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while starting the course",
+		})
+		return
+	}
+
 	unitID, moduleID, err := h.courseRepo.StartCourse(ctx, int64(userID), int32(courseID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -481,17 +592,6 @@ func (h *courseHandler) DeleteCourse(c *gin.Context) {
 	log := h.log.WithBaseFields(logger.Handler, "DeleteCourse")
 	ctx := c.Request.Context()
 
-	userID, err := GetUserID(c)
-	if err != nil {
-		log.Debug("unauthorized user tried to delete a course")
-		c.JSON(http.StatusUnauthorized, models.Response{
-			Success:   false,
-			ErrorCode: httperr.Unauthorized,
-			Message:   "authentication required to delete a course",
-		})
-		return
-	}
-
 	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 64)
 	if err != nil || courseID <= 0 {
 		c.JSON(http.StatusBadRequest, models.Response{
@@ -502,27 +602,6 @@ func (h *courseHandler) DeleteCourse(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.GetUserByID(ctx, int32(userID))
-	if err != nil {
-		log.WithError(err).Error("error fetching user data")
-		c.JSON(http.StatusInternalServerError, models.Response{
-			Success:   false,
-			ErrorCode: httperr.DatabaseFail,
-			Message:   "internal server error while verifying user permissions",
-		})
-		return
-	}
-
-	if user.Role != "admin" {
-		log.Debug("non-admin user tried to delete a course")
-		c.JSON(http.StatusForbidden, models.Response{
-			Success:   false,
-			ErrorCode: httperr.Forbidden,
-			Message:   "only administrators can delete courses",
-		})
-		return
-	}
-
 	err = h.courseRepo.DeleteCourse(ctx, courseID)
 	if err != nil {
 		if errors.Is(err, httperr.ErrNotFound) {
@@ -635,6 +714,17 @@ func (h *courseHandler) ResetCourseProgress(c *gin.Context) {
 		return
 	}
 
+	courseID, err = h.learnerCourseID(c, userID, courseID)
+	if err != nil {
+		log.WithError(err).Error("error resolving course version")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while resetting course progress",
+		})
+		return
+	}
+
 	log.WithFields(logrus.Fields{
 		"userID":   userID,
 		"courseID": courseID,
@@ -873,20 +963,37 @@ func (h *courseHandler) RegisterRoutes(r *gin.RouterGroup) {
 	authorized := courses.Group("", middleware.Auth())
 	{
 		authorized.GET("", h.ListAllCoursesWithOptionalProgress)
-		authorized.POST("", h.CreateCourse)
-		authorized.PUT("/:courseId", h.UpdateCourse)
-		authorized.POST("/:courseId/publish", h.PublishCourse)
 		authorized.GET("/:courseId", h.GetCourse)
-		authorized.GET("/search", h.SearchCourses)
 		authorized.GET("/progress", h.ListEnrolledCoursesWithProgress)
 		authorized.GET("/:courseId/progress", h.GetCourseProgress)
 		authorized.POST("/:courseId/start", h.StartCourse)
 		authorized.POST("/:courseId/reset", h.ResetCourseProgress)
-		authorized.DELETE("/:courseId", h.DeleteCourse)
 		authorized.GET("/:courseId/tags", h.GetCourseTags)
-		authorized.GET("/tags/search", h.SearchCourseTags)
-		authorized.POST("/:courseId/tags/:tagId", h.InsertCourseTag)
-		authorized.DELETE("/:courseId/tags/:tagId", h.RemoveCourseTag)
-		authorized.POST("/tags", h.CreateCourseTag)
+	}
+
+	search := authorized.Group("", h.limiter.RateLimit(middleware.SearchRateLimit))
+	{
+		search.GET("/search", h.SearchCourses)
+		search.GET("/tags/search", h.SearchCourseTags)
+	}
+
+	instructors := authorized.Group("", middleware.RequireRole(models.RoleAdmin, models.RoleInstructor))
+	{
+		instructors.POST("", h.CreateCourse)
+		instructors.POST("/tags", h.CreateCourseTag)
+	}
+
+	authors := instructors.Group("", RequireCourseAuthor(h.courseRepo))
+	{
+		authors.PUT("/:courseId", h.UpdateCourse)
+		authors.POST("/:courseId/publish", h.PublishCourse)
+		authors.POST("/:courseId/clone", h.CloneCourse)
+		authors.POST("/:courseId/tags/:tagId", h.InsertCourseTag)
+		authors.DELETE("/:courseId/tags/:tagId", h.RemoveCourseTag)
+	}
+
+	admins := authorized.Group("", middleware.RequireRole(models.RoleAdmin), middleware.RequireMFA())
+	{
+		admins.DELETE("/:courseId", h.DeleteCourse)
 	}
 }
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/internal/service"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IdentityHandler interface {
+	GetIdentities(c *gin.Context)
+	LinkIdentity(c *gin.Context)
+	UnlinkIdentity(c *gin.Context)
+	RegisterRoutes(r *gin.RouterGroup)
+}
+
+type identityHandler struct {
+	identityRepo service.IdentityService
+	stateRepo    service.OAuthStateService
+	log          *logger.Logger
+}
+
+func NewIdentityHandler(identityRepo service.IdentityService, stateRepo service.OAuthStateService) IdentityHandler {
+	return &identityHandler{identityRepo: identityRepo, stateRepo: stateRepo, log: logger.Get()}
+}
+
+func (h *identityHandler) GetIdentities(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetIdentities")
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to access linked accounts",
+		})
+		return
+	}
+
+	identities, err := h.identityRepo.GetIdentities(c.Request.Context(), userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get identities")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to get linked accounts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "linked accounts retrieved successfully",
+		Payload: map[string]interface{}{"identities": identities},
+	})
+}
+
+// LinkIdentity starts an OAuth flow that links the provider's identity to the
+// signed-in user. The client opens the returned URL in a browser; the
+// callback redirects back to the app with the outcome.
+func (h *identityHandler) LinkIdentity(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "LinkIdentity")
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to link accounts",
+		})
+		return
+	}
+
+	provider := c.Param("provider")
+	if !isOAuthProvider(provider) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "unknown provider",
+		})
+		return
+	}
+
+	clientState := c.Query("state")
+	if clientState == "" {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.MissingFields,
+			Message:   "state parameter is missing",
+		})
+		return
+	}
+
+	state, verifier, err := h.stateRepo.CreateState(c.Request.Context(), provider, clientState, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to create OAuth state")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InternalError,
+			Message:   "failed to start linking",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "open the authorization URL to link the account",
+		Payload: map[string]interface{}{"authorizationUrl": oauthAuthCodeURL(provider, state, verifier)},
+	})
+}
+
+func (h *identityHandler) UnlinkIdentity(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "UnlinkIdentity")
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to unlink accounts",
+		})
+		return
+	}
+
+	provider := c.Param("provider")
+	if !isOAuthProvider(provider) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "unknown provider",
+		})
+		return
+	}
+
+	if err := h.identityRepo.UnlinkIdentity(c.Request.Context(), userID, provider); err != nil {
+		switch {
+		case errors.Is(err, httperr.ErrNotFound):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "no linked account for this provider",
+			})
+		case errors.Is(err, service.ErrLastSignInMethod):
+			c.JSON(http.StatusConflict, models.Response{
+				Success:   false,
+				ErrorCode: httperr.Forbidden,
+				Message:   "set a password or link another account before unlinking this one",
+			})
+		default:
+			log.WithError(err).Error("failed to unlink identity")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "failed to unlink account",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "account unlinked successfully",
+	})
+}
+
+func (h *identityHandler) RegisterRoutes(r *gin.RouterGroup) {
+	authorized := r.Group("/users/me/identities", middleware.Auth())
+
+	authorized.GET("", h.GetIdentities)
+	authorized.POST("/:provider", h.LinkIdentity)
+	authorized.DELETE("/:provider", h.UnlinkIdentity)
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"algolearn/pkg/security"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	keySet *security.KeySet
+}
+
+func NewJWKSHandler(keySet *security.KeySet) *JWKSHandler {
+	return &JWKSHandler{keySet: keySet}
+}
+
+// GetJWKS publishes the public keys access tokens can be verified with.
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keySet.JWKS())
+}
+
+// RegisterRoutes implements the RouteRegistrar interface
+func (h *JWKSHandler) RegisterRoutes(r *gin.RouterGroup) {
+	// Empty implementation as the keyset is served from a well-known root path
+}
+
+// RegisterRootRoutes implements the RootRouteRegistrar interface
+func (h *JWKSHandler) RegisterRootRoutes(r *gin.Engine) {
+	r.GET("/.well-known/jwks.json", h.GetJWKS)
+}
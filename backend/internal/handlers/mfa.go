@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/internal/service"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler interface {
+	GetMFAStatus(c *gin.Context)
+	BeginTOTPEnrolment(c *gin.Context)
+	ConfirmTOTPEnrolment(c *gin.Context)
+	RegenerateRecoveryCodes(c *gin.Context)
+	DisableMFA(c *gin.Context)
+	RegisterRoutes(r *gin.RouterGroup)
+}
+
+type mfaHandler struct {
+	mfaRepo service.MFAService
+	log     *logger.Logger
+}
+
+func NewMFAHandler(mfaRepo service.MFAService) MFAHandler {
+	return &mfaHandler{mfaRepo: mfaRepo, log: logger.Get()}
+}
+
+func (h *mfaHandler) GetMFAStatus(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetMFAStatus")
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to access MFA settings",
+		})
+		return
+	}
+
+	status, err := h.mfaRepo.GetStatus(c.Request.Context(), userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get MFA status")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to get MFA settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "MFA settings retrieved successfully",
+		Payload: status,
+	})
+}
+
+// BeginTOTPEnrolment returns a new TOTP secret and its otpauth URI. MFA stays
+// off until ConfirmTOTPEnrolment is called with a code from the app.
+func (h *mfaHandler) BeginTOTPEnrolment(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "BeginTOTPEnrolment")
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to set up MFA",
+		})
+		return
+	}
+
+	enrolment, err := h.mfaRepo.BeginTOTPEnrolment(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrMFAAlreadyEnabled) {
+			c.JSON(http.StatusConflict, models.Response{
+				Success:   false,
+				ErrorCode: httperr.ContentAlreadyExists,
+				Message:   "multi-factor authentication is already enabled",
+			})
+			return
+		}
+		log.WithError(err).Error("failed to begin TOTP enrolment")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to set up MFA",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "scan the otpauth URI with an authenticator app and confirm with a code",
+		Payload: enrolment,
+	})
+}
+
+// ConfirmTOTPEnrolment enables MFA and returns the recovery codes, which are
+// not shown again.
+func (h *mfaHandler) ConfirmTOTPEnrolment(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ConfirmTOTPEnrolment")
+
+	userID, code, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaRepo.ConfirmTOTPEnrolment(c.Request.Context(), userID, code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMFACode):
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidMFACode,
+				Message:   "invalid authentication code",
+			})
+		case errors.Is(err, service.ErrNoPendingTOTP):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "start TOTP enrolment before confirming it",
+			})
+		case errors.Is(err, service.ErrMFAAlreadyEnabled):
+			c.JSON(http.StatusConflict, models.Response{
+				Success:   false,
+				ErrorCode: httperr.ContentAlreadyExists,
+				Message:   "multi-factor authentication is already enabled",
+			})
+		default:
+			log.WithError(err).Error("failed to confirm TOTP enrolment")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "failed to enable MFA",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "multi-factor authentication enabled; store the recovery codes somewhere safe and sign in again",
+		Payload: models.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after checking a
+// current code.
+func (h *mfaHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "RegenerateRecoveryCodes")
+
+	userID, code, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaRepo.RegenerateRecoveryCodes(c.Request.Context(), userID, code)
+	if err != nil {
+		if h.writeCodeError(c, err) {
+			return
+		}
+		log.WithError(err).Error("failed to regenerate recovery codes")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to regenerate recovery codes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "recovery codes regenerated; the previous ones no longer work",
+		Payload: models.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+func (h *mfaHandler) DisableMFA(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "DisableMFA")
+
+	userID, code, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaRepo.Disable(c.Request.Context(), userID, code); err != nil {
+		if h.writeCodeError(c, err) {
+			return
+		}
+		log.WithError(err).Error("failed to disable MFA")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to disable MFA",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "multi-factor authentication disabled",
+	})
+}
+
+// bindCode reads the caller's ID and the code from the request body, writing
+// the error response itself when either is missing.
+func (h *mfaHandler) bindCode(c *gin.Context) (int32, string, bool) {
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to change MFA settings",
+		})
+		return 0, "", false
+	}
+
+	var req models.MFACodeRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid JSON",
+		})
+		return 0, "", false
+	}
+
+	if req.Code == "" {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.MissingFields,
+			Message:   "code is required",
+		})
+		return 0, "", false
+	}
+
+	return userID, req.Code, true
+}
+
+// writeCodeError answers the errors returned when a code is checked against
+// an enabled second factor, and reports whether err was one of them.
+func (h *mfaHandler) writeCodeError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidMFACode,
+			Message:   "invalid authentication code",
+		})
+	case errors.Is(err, service.ErrMFANotEnabled):
+		c.JSON(http.StatusNotFound, models.Response{
+			Success:   false,
+			ErrorCode: httperr.NoData,
+			Message:   "multi-factor authentication is not enabled",
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *mfaHandler) RegisterRoutes(r *gin.RouterGroup) {
+	mfa := r.Group("/users/me/mfa", middleware.Auth())
+	mfa.GET("", h.GetMFAStatus)
+	mfa.DELETE("", h.DisableMFA)
+	mfa.POST("/totp", h.BeginTOTPEnrolment)
+	mfa.POST("/totp/verify", h.ConfirmTOTPEnrolment)
+	mfa.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+}
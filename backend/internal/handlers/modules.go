@@ -6,10 +6,13 @@ import (
 	"algolearn/internal/service"
 	"algolearn/pkg/logger"
 	"algolearn/pkg/middleware"
+	"algolearn/pkg/sandbox"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,8 +23,14 @@ type ModuleHandler interface {
 	CreateModule(c *gin.Context)
 	UpdateModule(c *gin.Context)
 	DeleteModule(c *gin.Context)
+	PatchSections(c *gin.Context)
+	CloneModule(c *gin.Context)
+	ReorderModules(c *gin.Context)
+	ReorderSections(c *gin.Context)
 	GetModuleWithProgress(c *gin.Context)
+	GetModuleSections(c *gin.Context)
 	UpdateModuleProgress(c *gin.Context)
+	SubmitExercise(c *gin.Context)
 	GetModules(c *gin.Context)
 	GetModulesCount(c *gin.Context)
 	RegisterRoutes(r *gin.RouterGroup)
@@ -29,15 +38,17 @@ type ModuleHandler interface {
 
 type moduleHandler struct {
 	moduleRepo service.ModuleService
-	userRepo   service.UserService
+	courseRepo service.CourseService
+	limiter    *middleware.RateLimiter
 	log        *logger.Logger
 }
 
 func NewModuleHandler(moduleRepo service.ModuleService,
-	userRepo service.UserService) ModuleHandler {
+	courseRepo service.CourseService, limiter *middleware.RateLimiter) ModuleHandler {
 	return &moduleHandler{
 		moduleRepo: moduleRepo,
-		userRepo:   userRepo,
+		courseRepo: courseRepo,
+		limiter:    limiter,
 		log:        logger.Get(),
 	}
 }
@@ -144,16 +155,18 @@ func (h *moduleHandler) GetModuleWithProgress(c *gin.Context) {
 	})
 }
 
-func (h *moduleHandler) CreateModule(c *gin.Context) {
-	log := h.log.WithBaseFields(logger.Handler, "CreateModule")
+// GetModuleSections returns the sections of a module with their answer keys
+// for its authors to edit; learners get them from GetModuleWithProgress.
+func (h *moduleHandler) GetModuleSections(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetModuleSections")
 	ctx := c.Request.Context()
 
-	userID, err := GetUserID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.Response{
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 64)
+	if err != nil || courseID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
 			Success:   false,
-			ErrorCode: httperr.Unauthorized,
-			Message:   "authentication required to create a module",
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid course ID: must be a positive integer",
 		})
 		return
 	}
@@ -168,12 +181,52 @@ func (h *moduleHandler) CreateModule(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.GetUserByID(ctx, int32(userID))
-	if err != nil || user.Role != "admin" {
-		c.JSON(http.StatusForbidden, models.Response{
+	moduleID, err := strconv.ParseInt(c.Param("moduleId"), 10, 64)
+	if err != nil || moduleID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid module ID: must be a positive integer",
+		})
+		return
+	}
+
+	sections, err := h.moduleRepo.GetModuleSections(ctx, courseID, unitID, moduleID)
+	if err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "module not found",
+			})
+			return
+		}
+		log.WithError(err).Error("error fetching module sections")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while retrieving module sections",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "module sections retrieved successfully",
+		Payload: sections,
+	})
+}
+
+func (h *moduleHandler) CreateModule(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "CreateModule")
+	ctx := c.Request.Context()
+
+	unitID, err := strconv.ParseInt(c.Param("unitId"), 10, 64)
+	if err != nil || unitID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
 			Success:   false,
-			ErrorCode: httperr.Forbidden,
-			Message:   "only administrators can create modules",
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid unit ID: must be a positive integer",
 		})
 		return
 	}
@@ -244,6 +297,15 @@ func (h *moduleHandler) CreateModule(c *gin.Context) {
 				Message:   "a module with this unit number already exists",
 			})
 			return
+		} else if errors.Is(err, service.ErrInvalidSectionContent) ||
+			errors.Is(err, service.ErrInvalidQuestion) ||
+			errors.Is(err, service.ErrInvalidExercise) {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidFormData,
+				Message:   err.Error(),
+			})
+			return
 		} else if err != nil {
 			log.WithError(err).Error("error creating module with content")
 			c.JSON(http.StatusInternalServerError, models.Response{
@@ -299,26 +361,6 @@ func (h *moduleHandler) UpdateModule(c *gin.Context) {
 	log := h.log.WithBaseFields(logger.Handler, "UpdateModule")
 	ctx := c.Request.Context()
 
-	userID, err := GetUserID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.Response{
-			Success:   false,
-			ErrorCode: httperr.Unauthorized,
-			Message:   "authentication required to update a module",
-		})
-		return
-	}
-
-	user, err := h.userRepo.GetUserByID(ctx, int32(userID))
-	if err != nil || user.Role != "admin" {
-		c.JSON(http.StatusForbidden, models.Response{
-			Success:   false,
-			ErrorCode: httperr.Forbidden,
-			Message:   "only administrators can update modules",
-		})
-		return
-	}
-
 	moduleID, err := strconv.ParseInt(c.Param("moduleId"), 10, 64)
 	if err != nil || moduleID <= 0 {
 		c.JSON(http.StatusBadRequest, models.Response{
@@ -369,26 +411,102 @@ func (h *moduleHandler) DeleteModule(c *gin.Context) {
 	log := h.log.WithBaseFields(logger.Handler, "DeleteModule")
 	ctx := c.Request.Context()
 
-	userID, err := GetUserID(c)
+	moduleID, err := strconv.ParseInt(c.Param("moduleId"), 10, 64)
+	if err != nil || moduleID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid module ID: must be a positive integer",
+		})
+		return
+	}
+
+	err = h.moduleRepo.DeleteModule(ctx, moduleID)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.Response{
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "module not found",
+			})
+			return
+		}
+		log.WithError(err).Error("error deleting module")
+		c.JSON(http.StatusInternalServerError, models.Response{
 			Success:   false,
-			ErrorCode: httperr.Unauthorized,
-			Message:   "authentication required to delete a module",
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while deleting module",
+		})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *moduleHandler) PatchSections(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "PatchSections")
+	ctx := c.Request.Context()
+
+	moduleID, err := strconv.ParseInt(c.Param("moduleId"), 10, 64)
+	if err != nil || moduleID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid module ID: must be a positive integer",
 		})
 		return
 	}
 
-	user, err := h.userRepo.GetUserByID(ctx, int32(userID))
-	if err != nil || user.Role != "admin" {
-		c.JSON(http.StatusForbidden, models.Response{
+	var patch models.SectionPatch
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
 			Success:   false,
-			ErrorCode: httperr.Forbidden,
-			Message:   "only administrators can delete modules",
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
 		})
 		return
 	}
 
+	sections, err := h.moduleRepo.PatchSections(ctx, moduleID, patch)
+	if err != nil {
+		switch {
+		case errors.Is(err, httperr.ErrNotFound):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "module not found",
+			})
+		case errors.Is(err, service.ErrInvalidSectionPatch),
+			errors.Is(err, service.ErrInvalidSectionContent),
+			errors.Is(err, service.ErrInvalidQuestion),
+			errors.Is(err, service.ErrInvalidExercise):
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   err.Error(),
+			})
+		default:
+			log.WithError(err).Error("error updating module sections")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "internal server error while updating module sections",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "sections updated successfully",
+		Payload: sections,
+	})
+}
+
+func (h *moduleHandler) CloneModule(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "CloneModule")
+	ctx := c.Request.Context()
+
 	moduleID, err := strconv.ParseInt(c.Param("moduleId"), 10, 64)
 	if err != nil || moduleID <= 0 {
 		c.JSON(http.StatusBadRequest, models.Response{
@@ -399,7 +517,17 @@ func (h *moduleHandler) DeleteModule(c *gin.Context) {
 		return
 	}
 
-	err = h.moduleRepo.DeleteModule(ctx, moduleID)
+	var req models.CloneRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	module, err := h.moduleRepo.CloneModule(ctx, moduleID, req.Name)
 	if err != nil {
 		if errors.Is(err, httperr.ErrNotFound) {
 			c.JSON(http.StatusNotFound, models.Response{
@@ -409,16 +537,144 @@ func (h *moduleHandler) DeleteModule(c *gin.Context) {
 			})
 			return
 		}
-		log.WithError(err).Error("error deleting module")
+		log.WithError(err).Error("error cloning module")
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Success:   false,
 			ErrorCode: httperr.DatabaseFail,
-			Message:   "internal server error while deleting module",
+			Message:   "internal server error while cloning module",
 		})
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "module cloned successfully",
+		Payload: module,
+	})
+}
+
+func (h *moduleHandler) ReorderModules(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ReorderModules")
+	ctx := c.Request.Context()
+
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 64)
+	if err != nil || courseID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidCourseID,
+			Message:   "invalid course ID: must be a positive integer",
+		})
+		return
+	}
+
+	unitID, err := strconv.ParseInt(c.Param("unitId"), 10, 64)
+	if err != nil || unitID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid unit ID: must be a positive integer",
+		})
+		return
+	}
+
+	var order models.Reorder
+	if err := json.NewDecoder(c.Request.Body).Decode(&order); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	modules, err := h.moduleRepo.ReorderModules(ctx, courseID, unitID, order.IDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, httperr.ErrNotFound):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "unit not found",
+			})
+		case errors.Is(err, service.ErrInvalidOrder):
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   err.Error(),
+			})
+		default:
+			log.WithError(err).Error("error reordering modules")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "internal server error while reordering modules",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "modules reordered successfully",
+		Payload: modules,
+	})
+}
+
+func (h *moduleHandler) ReorderSections(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ReorderSections")
+	ctx := c.Request.Context()
+
+	moduleID, err := strconv.ParseInt(c.Param("moduleId"), 10, 64)
+	if err != nil || moduleID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid module ID: must be a positive integer",
+		})
+		return
+	}
+
+	var order models.Reorder
+	if err := json.NewDecoder(c.Request.Body).Decode(&order); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	sections, err := h.moduleRepo.ReorderSections(ctx, moduleID, order.IDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, httperr.ErrNotFound):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "module not found",
+			})
+		case errors.Is(err, service.ErrInvalidOrder):
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   err.Error(),
+			})
+		default:
+			log.WithError(err).Error("error reordering sections")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "internal server error while reordering sections",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "sections reordered successfully",
+		Payload: sections,
+	})
 }
 
 func (h *moduleHandler) UpdateModuleProgress(c *gin.Context) {
@@ -455,7 +711,7 @@ func (h *moduleHandler) UpdateModuleProgress(c *gin.Context) {
 		return
 	}
 
-	err = h.moduleRepo.SaveModuleProgress(ctx, int64(userID), moduleID, batch.Sections, batch.Questions)
+	result, err := h.moduleRepo.SaveModuleProgress(ctx, int64(userID), moduleID, batch.Sections, batch.Questions)
 	if err != nil {
 		if errors.Is(err, httperr.ErrNotFound) {
 			c.JSON(http.StatusNotFound, models.Response{
@@ -465,6 +721,14 @@ func (h *moduleHandler) UpdateModuleProgress(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidAnswer) || errors.Is(err, service.ErrQuestionNotInModule) {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   err.Error(),
+			})
+			return
+		}
 		log.WithError(err).Error("error updating module progress")
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Success:   false,
@@ -477,6 +741,102 @@ func (h *moduleHandler) UpdateModuleProgress(c *gin.Context) {
 	c.JSON(http.StatusOK, models.Response{
 		Success: true,
 		Message: "module progress updated successfully",
+		Payload: result,
+	})
+}
+
+func (h *moduleHandler) SubmitExercise(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "SubmitExercise")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to submit an exercise",
+		})
+		return
+	}
+
+	moduleID, err := strconv.ParseInt(c.Param("moduleId"), 10, 64)
+	if err != nil || moduleID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid module ID: must be a positive integer",
+		})
+		return
+	}
+
+	sectionID, err := strconv.ParseInt(c.Param("sectionId"), 10, 64)
+	if err != nil || sectionID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid section ID: must be a positive integer",
+		})
+		return
+	}
+
+	var req models.ExerciseSubmissionRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if strings.TrimSpace(req.Code) == "" || len(req.Code) > models.MaxExerciseCodeBytes {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "code is required and must be at most 64 KB",
+		})
+		return
+	}
+
+	result, err := h.moduleRepo.SubmitExercise(ctx, int64(userID), moduleID, sectionID, req.Code)
+	if err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "exercise not found",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrCodeRunnerUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, models.Response{
+				Success:   false,
+				ErrorCode: httperr.ServiceUnavailable,
+				Message:   "code exercises are not available right now",
+			})
+			return
+		}
+		if errors.Is(err, sandbox.ErrUnsupportedLanguage) {
+			c.JSON(http.StatusUnprocessableEntity, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NotImplemented,
+				Message:   "this exercise's language cannot be run on this server",
+			})
+			return
+		}
+		log.WithError(err).Error("error submitting exercise")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InternalError,
+			Message:   "internal server error while submitting exercise",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "exercise submitted successfully",
+		Payload: result,
 	})
 }
 
@@ -586,9 +946,22 @@ func (h *moduleHandler) RegisterRoutes(r *gin.RouterGroup) {
 	authorized := modules.Group("", middleware.Auth())
 	{
 		authorized.GET("/:moduleId", h.GetModuleWithProgress)
-		authorized.POST("", h.CreateModule)
-		authorized.PUT("/:moduleId", h.UpdateModule)
-		authorized.DELETE("/:moduleId", h.DeleteModule)
 		authorized.PUT("/:moduleId/progress", h.UpdateModuleProgress)
+		authorized.POST("/:moduleId/sections/:sectionId/submissions",
+			h.limiter.RateLimit(middleware.ExerciseRateLimit), h.SubmitExercise)
+	}
+
+	authors := authorized.Group("",
+		middleware.RequireRole(models.RoleAdmin, models.RoleInstructor),
+		RequireCourseAuthor(h.courseRepo))
+	{
+		authors.POST("", h.CreateModule)
+		authors.PUT("/:moduleId", h.UpdateModule)
+		authors.DELETE("/:moduleId", h.DeleteModule)
+		authors.GET("/:moduleId/sections", h.GetModuleSections)
+		authors.PATCH("/:moduleId/sections", h.PatchSections)
+		authors.PUT("/order", h.ReorderModules)
+		authors.POST("/:moduleId/clone", h.CloneModule)
+		authors.PUT("/:moduleId/sections/order", h.ReorderSections)
 	}
 }
@@ -8,30 +8,92 @@ import (
 	"algolearn/pkg/logger"
 	"algolearn/pkg/middleware"
 
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	defaultNotificationsLimit = 20
+	maxNotificationsLimit     = 100
+
+	// maxReplayedNotifications is how many missed events a reconnecting
+	// stream client is sent per query while it is caught up.
+	maxReplayedNotifications = 100
+	streamHeartbeatInterval  = 30 * time.Second
+)
+
 type NotificationsHandler interface {
-	GetAllNotifications(c *gin.Context)
+	GetNotifications(c *gin.Context)
+	GetUnreadCount(c *gin.Context)
+	MarkAsRead(c *gin.Context)
+	MarkAllAsRead(c *gin.Context)
+	DeleteNotification(c *gin.Context)
+	StreamNotifications(c *gin.Context)
 	RegisterRoutes(r *gin.RouterGroup)
 }
 
 type notificationsHandler struct {
-	repo service.NotificationsService
-	log  *logger.Logger
+	repo   service.NotificationsService
+	broker service.NotificationBroker
+	log    *logger.Logger
 }
 
-func NewNotificationsHandler(repo service.NotificationsService) NotificationsHandler {
-	return &notificationsHandler{repo: repo, log: logger.Get()}
+func NewNotificationsHandler(repo service.NotificationsService, broker service.NotificationBroker) NotificationsHandler {
+	return &notificationsHandler{repo: repo, broker: broker, log: logger.Get()}
 }
 
-func (h *notificationsHandler) GetAllNotifications(c *gin.Context) {
-	log := h.log.WithBaseFields(logger.Handler, "GetAllNotifications")
-	notifications, err := h.repo.GetAllNotifications()
+func (h *notificationsHandler) GetNotifications(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetNotifications")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
 	if err != nil {
-		log.WithError(err).Error("failed to get all notifications")
+		log.Debug("unauthorized user tried to get notifications")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to access notifications",
+		})
+		return
+	}
+
+	var cursor int64
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor, err = strconv.ParseInt(cursorStr, 10, 32)
+		if err != nil || cursor < 1 {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   "invalid cursor: must be a positive integer",
+			})
+			return
+		}
+	}
+
+	limit := int64(defaultNotificationsLimit)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || limit < 1 || limit > maxNotificationsLimit {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   "invalid limit: must be between 1 and 100",
+			})
+			return
+		}
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+
+	page, err := h.repo.GetNotifications(ctx, userID, int32(cursor), int32(limit), unreadOnly)
+	if err != nil {
+		log.WithError(err).Error("failed to get notifications")
 		c.JSON(http.StatusInternalServerError,
 			models.Response{
 				Success:   false,
@@ -44,13 +106,310 @@ func (h *notificationsHandler) GetAllNotifications(c *gin.Context) {
 	response := models.Response{
 		Success: true,
 		Message: "Notifications retrieved successfully",
-		Payload: map[string]interface{}{"notifications": notifications},
+		Payload: page,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *notificationsHandler) GetUnreadCount(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetUnreadCount")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		log.Debug("unauthorized user tried to get unread count")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to access notifications",
+		})
+		return
+	}
+
+	count, err := h.repo.GetUnreadCount(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get unread count")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			Message:   "Internal server error",
+			ErrorCode: httperr.InternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "unread count retrieved successfully",
+		Payload: map[string]interface{}{"unreadCount": count},
+	})
+}
+
+func (h *notificationsHandler) MarkAsRead(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "MarkAsRead")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		log.Debug("unauthorized user tried to mark a notification as read")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to update notifications",
+		})
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(c.Param("notificationId"), 10, 32)
+	if err != nil || notificationID < 1 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid notification ID: must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.repo.MarkAsRead(ctx, userID, int32(notificationID)); err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "notification not found",
+			})
+			return
+		}
+		log.WithError(err).Error("failed to mark notification as read")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			Message:   "Internal server error",
+			ErrorCode: httperr.InternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "notification marked as read",
+	})
+}
+
+func (h *notificationsHandler) MarkAllAsRead(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "MarkAllAsRead")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		log.Debug("unauthorized user tried to mark notifications as read")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to update notifications",
+		})
+		return
+	}
+
+	updated, err := h.repo.MarkAllAsRead(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to mark all notifications as read")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			Message:   "Internal server error",
+			ErrorCode: httperr.InternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "notifications marked as read",
+		Payload: map[string]interface{}{"updated": updated},
+	})
+}
+
+func (h *notificationsHandler) DeleteNotification(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "DeleteNotification")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		log.Debug("unauthorized user tried to delete a notification")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to delete notifications",
+		})
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(c.Param("notificationId"), 10, 32)
+	if err != nil || notificationID < 1 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid notification ID: must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.repo.DeleteNotification(ctx, userID, int32(notificationID)); err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "notification not found",
+			})
+			return
+		}
+		log.WithError(err).Error("failed to delete notification")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			Message:   "Internal server error",
+			ErrorCode: httperr.InternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "notification deleted successfully",
+	})
+}
+
+// StreamNotifications pushes the user's new notifications as server-sent
+// events. Clients reconnecting with a Last-Event-ID header (or lastEventId
+// query parameter, for clients that cannot set headers) first receive the
+// notifications they missed.
+func (h *notificationsHandler) StreamNotifications(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "StreamNotifications")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		log.Debug("unauthorized user tried to stream notifications")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to access notifications",
+		})
+		return
+	}
+
+	lastEventIDStr := c.GetHeader("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = c.Query("lastEventId")
+	}
+
+	var lastEventID int64
+	if lastEventIDStr != "" {
+		lastEventID, err = strconv.ParseInt(lastEventIDStr, 10, 32)
+		if err != nil || lastEventID < 0 {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   "invalid Last-Event-ID: must be a non-negative integer",
+			})
+			return
+		}
+	}
+
+	// subscribe before replaying so nothing created in between is lost
+	events, unsubscribe := h.broker.Subscribe(userID)
+	defer unsubscribe()
+
+	var missed []models.Notification
+	if lastEventID > 0 {
+		missed, err = h.repo.GetNotificationsAfter(ctx, userID, int32(lastEventID), maxReplayedNotifications)
+		if err != nil {
+			log.WithError(err).Error("failed to replay notifications")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				Message:   "Internal server error",
+				ErrorCode: httperr.InternalError,
+			})
+			return
+		}
+	}
+
+	// the stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.WithError(err).Warn("failed to clear write deadline")
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// a full page may not be all that was missed, so replay continues page
+	// by page until it catches up with the live events
+	lastSentID := int32(lastEventID)
+	for len(missed) > 0 {
+		for _, n := range missed {
+			if err := writeNotificationEvent(c, n); err != nil {
+				return
+			}
+			lastSentID = n.ID
+		}
+		c.Writer.Flush()
+
+		if len(missed) < maxReplayedNotifications {
+			break
+		}
+		missed, err = h.repo.GetNotificationsAfter(ctx, userID, lastSentID, maxReplayedNotifications)
+		if err != nil {
+			log.WithError(err).Error("failed to replay notifications")
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-events:
+			// the broker drops subscribers that fall behind; the client
+			// reconnects with Last-Event-ID and replays what it missed
+			if !ok {
+				return
+			}
+			if n.ID <= lastSentID {
+				continue
+			}
+			if err := writeNotificationEvent(c, n); err != nil {
+				return
+			}
+			lastSentID = n.ID
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeNotificationEvent(c *gin.Context, n models.Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: notification\ndata: %s\n\n", n.ID, data)
+	return err
+}
+
 func (h *notificationsHandler) RegisterRoutes(r *gin.RouterGroup) {
 	authorized := r.Group("/notifications", middleware.Auth())
-	authorized.GET("", h.GetAllNotifications)
+	authorized.GET("", h.GetNotifications)
+	authorized.GET("/unread-count", h.GetUnreadCount)
+	authorized.GET("/stream", h.StreamNotifications)
+	authorized.PATCH("/read-all", h.MarkAllAsRead)
+	authorized.PATCH("/:notificationId/read", h.MarkAsRead)
+	authorized.DELETE("/:notificationId", h.DeleteNotification)
 }
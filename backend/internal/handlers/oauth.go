@@ -1,19 +1,21 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"algolearn/internal/config"
+	httperr "algolearn/internal/errors"
 	"algolearn/internal/models"
 	"algolearn/internal/service"
 	"algolearn/pkg/logger"
 	"algolearn/pkg/security"
 
+	"github.com/golang-jwt/jwt"
 	"golang.org/x/oauth2"
 
 	"github.com/gin-gonic/gin"
@@ -27,56 +29,116 @@ type OauthHandler interface {
 }
 
 type oauthHandler struct {
-	userRepo service.UserService
-	log      *logger.Logger
+	userRepo     service.UserService
+	stateRepo    service.OAuthStateService
+	identityRepo service.IdentityService
+	mfaRepo      service.MFAService
+	log          *logger.Logger
 }
 
-func NewOauthHandler(userRepo service.UserService) OauthHandler {
-	return &oauthHandler{userRepo: userRepo, log: logger.Get()}
+func NewOauthHandler(userRepo service.UserService, stateRepo service.OAuthStateService,
+	identityRepo service.IdentityService, mfaRepo service.MFAService) OauthHandler {
+	return &oauthHandler{
+		userRepo:     userRepo,
+		stateRepo:    stateRepo,
+		identityRepo: identityRepo,
+		mfaRepo:      mfaRepo,
+		log:          logger.Get(),
+	}
 }
 
+const appleIssuer = "https://appleid.apple.com"
+
+// HandleOAuthLogin redirects to the provider with a server-issued state and a
+// PKCE challenge. The client's own state is kept server-side and handed back
+// in the final app redirect.
 func (h *oauthHandler) HandleOAuthLogin(c *gin.Context) {
 	log := h.log.WithBaseFields(logger.Handler, "HandleOAuthLogin")
 	provider := c.Query("provider")
-	state := c.Query("state")
-	if state == "" {
+	clientState := c.Query("state")
+	if clientState == "" {
 		log.WithError(errors.New("state parameter is missing")).Error("invalid request")
 		c.JSON(http.StatusBadRequest, models.Response{Success: false, Message: "State parameter is missing"})
 		return
 	}
-	fmt.Printf("HandleOAuthLogin - State: %s\n", state) // Debugging state parameter
-	var url string
-	switch provider {
-	case "google":
-		url = config.GetGoogleOAuthConfig().AuthCodeURL(state, oauth2.AccessTypeOffline)
-	case "apple":
-		url = config.GetAppleOAuthConfig().AuthCodeURL(state, oauth2.AccessTypeOffline)
-	default:
+
+	if !isOAuthProvider(provider) {
 		log.WithError(errors.New("unknown provider")).Error("invalid request")
 		c.JSON(http.StatusBadRequest, models.Response{Success: false, Message: "Unknown provider"})
 		return
 	}
-	c.Redirect(http.StatusTemporaryRedirect, url)
+
+	state, verifier, err := h.stateRepo.CreateState(c.Request.Context(), provider, clientState, 0)
+	if err != nil {
+		log.WithError(err).Error("failed to create OAuth state")
+		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to start login"})
+		return
+	}
+
+	c.Redirect(http.StatusTemporaryRedirect, oauthAuthCodeURL(provider, state, verifier))
 }
 
-func (h *oauthHandler) GoogleCallback(c *gin.Context) {
-	log := h.log.WithBaseFields(logger.Handler, "GoogleCallback")
-	code := c.Query("code")
-	state := c.Query("state")
+func isOAuthProvider(provider string) bool {
+	return provider == models.ProviderGoogle || provider == models.ProviderApple
+}
+
+// oauthAuthCodeURL returns the provider's consent page URL for a flow started
+// with OAuthStateService.CreateState.
+func oauthAuthCodeURL(provider, state, verifier string) string {
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)}
+	if provider == models.ProviderApple {
+		// Apple only returns the email scope through a form post
+		opts = append(opts, oauth2.SetAuthURLParam("response_mode", "form_post"))
+		return config.GetAppleOAuthConfig().AuthCodeURL(state, opts...)
+	}
+	opts = append(opts, oauth2.AccessTypeOffline)
+	return config.GetGoogleOAuthConfig().AuthCodeURL(state, opts...)
+}
+
+// consumeCallbackState validates the state a provider sent back and returns
+// the flow it started. It writes the error response itself.
+func (h *oauthHandler) consumeCallbackState(c *gin.Context, provider string) (*service.PendingOAuthLogin, bool) {
+	log := h.log.WithBaseFields(logger.Handler, "consumeCallbackState")
+
+	state := callbackParam(c, "state")
 	if state == "" {
 		log.WithError(errors.New("state parameter is missing")).Error("invalid request")
 		c.JSON(http.StatusBadRequest, models.Response{Success: false, Message: "State parameter is missing"})
+		return nil, false
+	}
+
+	pending, err := h.stateRepo.ConsumeState(c.Request.Context(), provider, state)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidOAuthState) {
+			log.Warnf("rejected %s callback with an invalid state", provider)
+			c.JSON(http.StatusBadRequest, models.Response{Success: false, Message: "Invalid or expired state"})
+			return nil, false
+		}
+		log.WithError(err).Error("failed to verify OAuth state")
+		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to verify state"})
+		return nil, false
+	}
+
+	return pending, true
+}
+
+func (h *oauthHandler) GoogleCallback(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GoogleCallback")
+	ctx := c.Request.Context()
+
+	pending, ok := h.consumeCallbackState(c, models.ProviderGoogle)
+	if !ok {
 		return
 	}
-	fmt.Printf("GoogleCallback - State: %s\n", state) // Debugging state parameter
-	token, err := config.GetGoogleOAuthConfig().Exchange(context.Background(), code)
+
+	token, err := config.GetGoogleOAuthConfig().Exchange(ctx, c.Query("code"), oauth2.VerifierOption(pending.Verifier))
 	if err != nil {
 		log.WithError(err).Error("failed to exchange token")
 		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to exchange token: " + err.Error()})
 		return
 	}
 
-	client := config.GetGoogleOAuthConfig().Client(context.Background(), token)
+	client := config.GetGoogleOAuthConfig().Client(ctx, token)
 	response, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
 	if err != nil {
 		log.WithError(err).Error("failed to get user info")
@@ -86,8 +148,9 @@ func (h *oauthHandler) GoogleCallback(c *gin.Context) {
 	defer response.Body.Close()
 
 	var googleUser struct {
-		ID    string `json:"id"`
-		Email string `json:"email"`
+		ID            string `json:"id"`
+		Email         string `json:"email"`
+		VerifiedEmail bool   `json:"verified_email"`
 	}
 	if err := json.NewDecoder(response.Body).Decode(&googleUser); err != nil {
 		log.WithError(err).Error("failed to parse user info")
@@ -95,89 +158,178 @@ func (h *oauthHandler) GoogleCallback(c *gin.Context) {
 		return
 	}
 
-	h.handleOAuthUser(c, googleUser.Email, googleUser.ID, state)
+	h.completeOAuth(c, pending, models.OAuthIdentity{
+		Provider:      models.ProviderGoogle,
+		Subject:       googleUser.ID,
+		Email:         googleUser.Email,
+		EmailVerified: googleUser.VerifiedEmail,
+	})
 }
 
+// AppleCallback handles Apple's form post. Apple has no userinfo endpoint;
+// the user's identity comes from the id_token returned by the code exchange.
 func (h *oauthHandler) AppleCallback(c *gin.Context) {
 	log := h.log.WithBaseFields(logger.Handler, "AppleCallback")
-	code := c.Query("code")
-	state := c.Query("state")
-	if state == "" {
-		log.WithError(errors.New("state parameter is missing")).Error("invalid request")
-		c.JSON(http.StatusBadRequest, models.Response{Success: false, Message: "State parameter is missing"})
+	ctx := c.Request.Context()
+
+	pending, ok := h.consumeCallbackState(c, models.ProviderApple)
+	if !ok {
 		return
 	}
-	fmt.Printf("AppleCallback - State: %s\n", state) // Debugging state parameter
-	token, err := config.GetAppleOAuthConfig().Exchange(context.Background(), code)
+
+	token, err := config.GetAppleOAuthConfig().Exchange(ctx, callbackParam(c, "code"), oauth2.VerifierOption(pending.Verifier))
 	if err != nil {
 		log.WithError(err).Error("failed to exchange token")
 		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to exchange token: " + err.Error()})
 		return
 	}
 
-	client := config.GetAppleOAuthConfig().Client(context.Background(), token)
-	response, err := client.Get("https://appleid.apple.com/auth/userinfo")
+	idToken, _ := token.Extra("id_token").(string)
+	appleUser, err := parseAppleIDToken(idToken, config.GetAppleOAuthConfig().ClientID)
 	if err != nil {
-		log.WithError(err).Error("failed to get user info")
-		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to get user info: " + err.Error()})
+		log.WithError(err).Error("failed to parse id token")
+		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to parse user info: " + err.Error()})
 		return
 	}
-	defer response.Body.Close()
 
-	var appleUser struct {
-		ID    string `json:"id"`
-		Email string `json:"email"`
+	h.completeOAuth(c, pending, models.OAuthIdentity{
+		Provider:      models.ProviderApple,
+		Subject:       appleUser.Subject,
+		Email:         appleUser.Email,
+		EmailVerified: appleUser.emailVerified(),
+	})
+}
+
+// callbackParam reads a callback parameter from the form post body, falling
+// back to the query string.
+func callbackParam(c *gin.Context, key string) string {
+	if value := c.PostForm(key); value != "" {
+		return value
 	}
-	if err := json.NewDecoder(response.Body).Decode(&appleUser); err != nil {
-		log.WithError(err).Error("failed to parse user info")
-		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to parse user info: " + err.Error()})
-		return
+	return c.Query(key)
+}
+
+type appleIDClaims struct {
+	Email string `json:"email"`
+	// Apple sends email_verified as either a boolean or a string
+	EmailVerified interface{} `json:"email_verified"`
+	jwt.StandardClaims
+}
+
+func (c *appleIDClaims) emailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// parseAppleIDToken reads the user's identity from an Apple id_token. The
+// token comes straight from Apple's token endpoint over TLS, which OpenID
+// Connect accepts in place of a signature check, but the issuer, audience
+// and expiry are still validated.
+func parseAppleIDToken(idToken, clientID string) (*appleIDClaims, error) {
+	if idToken == "" {
+		return nil, errors.New("token response has no id_token")
 	}
 
-	h.handleOAuthUser(c, appleUser.Email, appleUser.ID, state)
+	claims := &appleIDClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(idToken, claims); err != nil {
+		return nil, fmt.Errorf("malformed id_token: %w", err)
+	}
+
+	if claims.Issuer != appleIssuer {
+		return nil, fmt.Errorf("unexpected id_token issuer %q", claims.Issuer)
+	}
+	if !claims.VerifyAudience(clientID, true) {
+		return nil, fmt.Errorf("id_token was not issued for this client")
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("id_token is expired")
+	}
+	if claims.Subject == "" || claims.Email == "" {
+		return nil, errors.New("id_token is missing the subject or email")
+	}
+
+	return claims, nil
 }
 
-func (h *oauthHandler) handleOAuthUser(c *gin.Context, email, oauthID, state string) {
-	fmt.Printf("handleOAuthUser - State: %s\n", state) // Debugging state parameter
-	log := h.log.WithBaseFields(logger.Handler, "handleOAuthUser")
-	user, err := h.userRepo.GetUserByEmail(c.Request.Context(), email)
-	if err != nil {
-		if err.Error() == "user not found" {
-			log.WithError(err).Error("user not found")
-			// User does not exist, we create a new one
-			newUser := &models.User{
-				CreatedAt:       time.Now(),
-				UpdatedAt:       time.Now(),
-				Email:           email,
-				OAuthID:         oauthID,
-				Role:            "user",
-				IsActive:        true,
-				IsEmailVerified: true,
-				CPUs:            0,
-			}
-			_, err := h.userRepo.CreateUser(c.Request.Context(), newUser)
-			if err != nil {
-				log.WithError(err).Error("failed to create user")
-				c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Could not create user: " + err.Error()})
-				return
+// completeOAuth finishes a flow once the provider has identified the user,
+// either linking the identity to the user who started it or signing in.
+func (h *oauthHandler) completeOAuth(c *gin.Context, pending *service.PendingOAuthLogin, identity models.OAuthIdentity) {
+	log := h.log.WithBaseFields(logger.Handler, "completeOAuth")
+	ctx := c.Request.Context()
+
+	if pending.LinkUserID != 0 {
+		status := "linked"
+		if err := h.identityRepo.LinkIdentity(ctx, pending.LinkUserID, identity); err != nil {
+			switch {
+			case errors.Is(err, service.ErrIdentityInUse):
+				status = "identity_in_use"
+			case errors.Is(err, service.ErrProviderLinked):
+				status = "provider_already_linked"
+			default:
+				log.WithError(err).Error("failed to link identity")
+				status = "error"
 			}
-			user = newUser
-		} else {
-			log.WithError(err).Error("database error")
-			c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Database error: " + err.Error()})
+		}
+		c.Redirect(http.StatusTemporaryRedirect, "app.algolearn://link?provider="+identity.Provider+
+			"&status="+status+"&state="+url.QueryEscape(pending.ClientState))
+		return
+	}
+
+	userID, err := h.identityRepo.ResolveOAuthLogin(ctx, identity)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailNotVerified) || errors.Is(err, service.ErrProviderLinked) {
+			c.JSON(http.StatusConflict, models.Response{
+				Success:   false,
+				ErrorCode: httperr.AccountExists,
+				Message:   "an account with this email already exists; sign in to it and link this provider instead",
+			})
 			return
 		}
+		log.WithError(err).Error("failed to resolve OAuth login")
+		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Could not sign in: " + err.Error()})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get user")
+		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Database error: " + err.Error()})
+		return
+	}
+
+	mfaEnabled, err := h.mfaRepo.IsEnabled(ctx, user.ID)
+	if err != nil {
+		log.WithError(err).Error("failed to check MFA settings")
+		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Database error: " + err.Error()})
+		return
+	}
+
+	// the app finishes the sign-in through /users/sign-in/mfa
+	if mfaEnabled {
+		challenge, err := h.mfaRepo.IssueChallenge(user.ID)
+		if err != nil {
+			log.WithError(err).Error("failed to issue MFA challenge")
+			c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to generate MFA challenge: " + err.Error()})
+			return
+		}
+		c.Redirect(http.StatusTemporaryRedirect, "app.algolearn://auth?mfaToken="+challenge+"&state="+url.QueryEscape(pending.ClientState))
+		return
 	}
 
-	token, err := security.GenerateJWT(user.ID)
+	token, err := security.GenerateJWT(user.ID, user.Role, false)
 	if err != nil {
 		log.WithError(err).Error("failed to generate JWT")
 		c.JSON(http.StatusInternalServerError, models.Response{Success: false, Message: "Failed to generate JWT: " + err.Error()})
 		return
 	}
 
-	// Include the state parameter in the redirect URL
-	c.Redirect(http.StatusTemporaryRedirect, "app.algolearn://auth?token="+token+"&state="+state)
+	// Include the client's state parameter in the redirect URL
+	c.Redirect(http.StatusTemporaryRedirect, "app.algolearn://auth?token="+token+"&state="+url.QueryEscape(pending.ClientState))
 }
 
 func (h *oauthHandler) RegisterRoutes(r *gin.RouterGroup) {
@@ -187,4 +339,5 @@ func (h *oauthHandler) RegisterRoutes(r *gin.RouterGroup) {
 	callback := r.Group("/callback")
 	callback.GET("/google", h.GoogleCallback)
 	callback.GET("/apple", h.AppleCallback)
+	callback.POST("/apple", h.AppleCallback)
 }
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/internal/service"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDueReviewsLimit = 20
+	maxDueReviewsLimit     = 100
+)
+
+type ReviewHandler interface {
+	GetDueReviews(c *gin.Context)
+	ReviewQuestion(c *gin.Context)
+	RegisterRoutes(r *gin.RouterGroup)
+}
+
+type reviewHandler struct {
+	repo service.ReviewService
+	log  *logger.Logger
+}
+
+func NewReviewHandler(repo service.ReviewService) ReviewHandler {
+	return &reviewHandler{repo: repo, log: logger.Get()}
+}
+
+func (h *reviewHandler) GetDueReviews(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetDueReviews")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to access reviews",
+		})
+		return
+	}
+
+	limit := int64(defaultDueReviewsLimit)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || limit < 1 || limit > maxDueReviewsLimit {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   "invalid limit: must be between 1 and 100",
+			})
+			return
+		}
+	}
+
+	reviews, err := h.repo.GetDueReviews(ctx, userID, int32(limit))
+	if err != nil {
+		log.WithError(err).Error("failed to get due reviews")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to get due reviews",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "due reviews retrieved successfully",
+		Payload: reviews,
+	})
+}
+
+func (h *reviewHandler) ReviewQuestion(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ReviewQuestion")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to review questions",
+		})
+		return
+	}
+
+	questionID, err := strconv.ParseInt(c.Param("questionId"), 10, 32)
+	if err != nil || questionID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid question ID: must be a positive integer",
+		})
+		return
+	}
+
+	var req models.ReviewRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := h.repo.ReviewQuestion(ctx, userID, questionID, req)
+	if err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "question is not in your review queue",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrReviewNotDue) {
+			c.JSON(http.StatusConflict, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidRequest,
+				Message:   err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidAnswer) {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   err.Error(),
+			})
+			return
+		}
+		log.WithError(err).Error("failed to review question")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to review question",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "question reviewed successfully",
+		Payload: result,
+	})
+}
+
+func (h *reviewHandler) RegisterRoutes(r *gin.RouterGroup) {
+	users := r.Group("/users", middleware.Auth())
+	users.GET("/me/reviews/due", h.GetDueReviews)
+
+	reviews := r.Group("/reviews", middleware.Auth())
+	reviews.POST("/:questionId", h.ReviewQuestion)
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	codes "algolearn/internal/errors"
+	"algolearn/internal/models"
 	"algolearn/internal/service"
 	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
 	"fmt"
 	"net/http"
 	"path/filepath"
@@ -22,18 +24,100 @@ type StorageHandler interface {
 }
 
 type storageHandler struct {
-	storage service.StorageService
-	log     *logger.Logger
+	storage    service.StorageService
+	courseRepo service.CourseService
+	limiter    *middleware.RateLimiter
+	log        *logger.Logger
 }
 
-func NewUploadHandler(storageService service.StorageService) StorageHandler {
+func NewUploadHandler(storageService service.StorageService, courseRepo service.CourseService, limiter *middleware.RateLimiter) StorageHandler {
 
 	return &storageHandler{
-		storage: storageService,
-		log:     logger.Get(),
+		storage:    storageService,
+		courseRepo: courseRepo,
+		limiter:    limiter,
+		log:        logger.Get(),
 	}
 }
 
+// mediaResources are the folders media is stored under, each holding one
+// subfolder per user, course, unit or module named after its folder object
+// key.
+var mediaResources = map[string]bool{
+	"users":   true,
+	"courses": true,
+	"units":   true,
+	"modules": true,
+}
+
+// authorizeMediaFolder reports whether the caller may change what is stored
+// in folder/subFolder, and responds with the error if not. Students may only
+// manage their own profile folder, and instructors the folders of the courses
+// they author.
+func (h *storageHandler) authorizeMediaFolder(c *gin.Context, folder, subFolder string) bool {
+	if !mediaResources[folder] {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":   false,
+			"error":     "unknown folder",
+			"errorCode": codes.InvalidInput,
+		})
+		return false
+	}
+
+	folderKey, err := uuid.Parse(subFolder)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":   false,
+			"error":     "subFolder must be a folder object key",
+			"errorCode": codes.InvalidInput,
+		})
+		return false
+	}
+
+	role := GetUserRole(c)
+	if role == models.RoleAdmin && c.GetBool(middleware.MFAKey) {
+		return true
+	}
+	if role == models.RoleStudent && folder != "users" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":   false,
+			"error":     "insufficient permissions",
+			"errorCode": codes.Forbidden,
+		})
+		return false
+	}
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":   false,
+			"error":     "authentication required",
+			"errorCode": codes.Unauthorized,
+		})
+		return false
+	}
+
+	allowed, err := h.courseRepo.CanManageMediaFolder(c.Request.Context(), userID, folder, folderKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success":   false,
+			"error":     "internal server error while verifying user permissions",
+			"errorCode": codes.DatabaseFail,
+		})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success":   false,
+			"error":     "insufficient permissions",
+			"errorCode": codes.Forbidden,
+		})
+		return false
+	}
+
+	return true
+}
+
 type UploadRequest struct {
 	Folder      string `json:"folder"`
 	SubFolder   string `json:"subFolder"`
@@ -57,16 +141,14 @@ func (h *storageHandler) GetPresignedURL(c *gin.Context) {
 		return
 	}
 
+	if !h.authorizeMediaFolder(c, req.Folder, req.SubFolder) {
+		return
+	}
+
 	ext := filepath.Ext(req.Filename)
 
-	var finalPath string
 	var uniqueFileName = uuid.New().String()
-
-	if req.SubFolder == "" {
-		finalPath = fmt.Sprintf("%s/%s%s", req.Folder, uniqueFileName, ext)
-	} else {
-		finalPath = fmt.Sprintf("%s/%s/%s%s", req.Folder, req.SubFolder, uniqueFileName, ext)
-	}
+	finalPath := fmt.Sprintf("%s/%s/%s%s", req.Folder, req.SubFolder, uniqueFileName, ext)
 
 	url, err := h.storage.GeneratePresignedPutURL(finalPath, req.ContentType, 15*time.Minute)
 	if err != nil {
@@ -113,6 +195,20 @@ func (h *storageHandler) DeleteFromS3(c *gin.Context) {
 		return
 	}
 
+	// the object key names a single object within the folder
+	if req.ObjectKey == "" || strings.Contains(req.ObjectKey, "/") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":   false,
+			"error":     "invalid object key",
+			"errorCode": codes.InvalidInput,
+		})
+		return
+	}
+
+	if !h.authorizeMediaFolder(c, req.FolderName, req.SubFolder) {
+		return
+	}
+
 	err := h.storage.DeleteFromS3(ctx, req.FolderName, req.SubFolder, req.ObjectKey)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -140,6 +236,10 @@ func (h *storageHandler) CountObjectsInFolder(c *gin.Context) {
 		return
 	}
 
+	if !h.authorizeMediaFolder(c, req.FolderName, req.SubFolder) {
+		return
+	}
+
 	count, err := h.storage.CountObjectsInFolder(ctx, req.FolderName, req.SubFolder)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -159,8 +259,10 @@ func (h *storageHandler) CountObjectsInFolder(c *gin.Context) {
 }
 
 func (h *storageHandler) RegisterRoutes(r *gin.RouterGroup) {
-	uploads := r.Group("/storage")
+	uploads := r.Group("/storage", middleware.Auth(), h.limiter.RateLimit(middleware.UploadRateLimit))
 	uploads.POST("/presign", h.GetPresignedURL)
-	uploads.POST("/delete", h.DeleteFromS3)
-	uploads.POST("/count", h.CountObjectsInFolder)
+
+	instructors := uploads.Group("", middleware.RequireRole(models.RoleAdmin, models.RoleInstructor))
+	instructors.POST("/delete", h.DeleteFromS3)
+	instructors.POST("/count", h.CountObjectsInFolder)
 }
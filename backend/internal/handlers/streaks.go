@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/internal/service"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+type StreakHandler interface {
+	GetMyStreak(c *gin.Context)
+	GetLeaderboard(c *gin.Context)
+	RegisterRoutes(r *gin.RouterGroup)
+}
+
+type streakHandler struct {
+	repo service.StreakService
+	log  *logger.Logger
+}
+
+func NewStreakHandler(repo service.StreakService) StreakHandler {
+	return &streakHandler{repo: repo, log: logger.Get()}
+}
+
+func (h *streakHandler) GetMyStreak(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetMyStreak")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		log.Debug("unauthorized user tried to get streak")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to access streak",
+		})
+		return
+	}
+
+	streak, err := h.repo.GetUserStreak(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get user streak")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to get streak",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "streak retrieved successfully",
+		Payload: streak,
+	})
+}
+
+func (h *streakHandler) GetLeaderboard(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetLeaderboard")
+	ctx := c.Request.Context()
+
+	limit := int64(defaultLeaderboardLimit)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || limit < 1 || limit > maxLeaderboardLimit {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   "invalid limit: must be between 1 and 100",
+			})
+			return
+		}
+	}
+
+	entries, err := h.repo.GetLeaderboard(ctx, int32(limit))
+	if err != nil {
+		log.WithError(err).Error("failed to get streak leaderboard")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "failed to get leaderboard",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "leaderboard retrieved successfully",
+		Payload: map[string]interface{}{"leaderboard": entries},
+	})
+}
+
+func (h *streakHandler) RegisterRoutes(r *gin.RouterGroup) {
+	authorized := r.Group("/users", middleware.Auth())
+
+	authorized.GET("/me/streak", h.GetMyStreak)
+	authorized.GET("/streaks/leaderboard", h.GetLeaderboard)
+}
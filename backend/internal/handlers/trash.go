@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/internal/service"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler interface {
+	GetTrash(c *gin.Context)
+	RestoreCourse(c *gin.Context)
+	RestoreUnit(c *gin.Context)
+	RestoreModule(c *gin.Context)
+	RegisterRoutes(r *gin.RouterGroup)
+}
+
+type trashHandler struct {
+	repo service.TrashService
+	log  *logger.Logger
+}
+
+func NewTrashHandler(repo service.TrashService) TrashHandler {
+	return &trashHandler{repo: repo, log: logger.Get()}
+}
+
+func (h *trashHandler) GetTrash(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "GetTrash")
+
+	items, err := h.repo.GetTrash(c.Request.Context())
+	if err != nil {
+		log.WithError(err).Error("error fetching trash")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while retrieving the trash",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "trash retrieved successfully",
+		Payload: items,
+	})
+}
+
+func (h *trashHandler) RestoreCourse(c *gin.Context) {
+	h.restore(c, "course", "courseId", h.repo.RestoreCourse)
+}
+
+func (h *trashHandler) RestoreUnit(c *gin.Context) {
+	h.restore(c, "unit", "unitId", h.repo.RestoreUnit)
+}
+
+func (h *trashHandler) RestoreModule(c *gin.Context) {
+	h.restore(c, "module", "moduleId", h.repo.RestoreModule)
+}
+
+// restore takes the item named by the param out of the trash with fn.
+func (h *trashHandler) restore(c *gin.Context, item, param string, fn func(ctx context.Context, id int64) error) {
+	log := h.log.WithBaseFields(logger.Handler, "Restore")
+
+	id, err := strconv.ParseInt(c.Param(param), 10, 32)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid " + item + " ID: must be a positive integer",
+		})
+		return
+	}
+
+	if err := fn(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, httperr.ErrNotFound):
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   item + " not found in the trash",
+			})
+		case errors.Is(err, service.ErrParentDeleted):
+			c.JSON(http.StatusConflict, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidRequest,
+				Message:   err.Error(),
+			})
+		default:
+			log.WithError(err).Errorf("error restoring %s", item)
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.DatabaseFail,
+				Message:   "internal server error while restoring the " + item,
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: item + " restored successfully",
+	})
+}
+
+func (h *trashHandler) RegisterRoutes(r *gin.RouterGroup) {
+	trash := r.Group("/trash", middleware.Auth(), middleware.RequireRole(models.RoleAdmin), middleware.RequireMFA())
+	trash.GET("", h.GetTrash)
+	trash.POST("/courses/:courseId/restore", h.RestoreCourse)
+	trash.POST("/units/:unitId/restore", h.RestoreUnit)
+	trash.POST("/modules/:moduleId/restore", h.RestoreModule)
+}
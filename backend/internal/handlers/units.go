@@ -5,8 +5,11 @@ import (
 	"algolearn/internal/models"
 	"algolearn/internal/service"
 	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -19,23 +22,44 @@ type UnitHandler interface {
 	GetUnitsByCourseID(c *gin.Context)
 	UpdateUnit(c *gin.Context)
 	UpdateUnitNumber(c *gin.Context)
+	ReorderUnits(c *gin.Context)
+	CloneUnit(c *gin.Context)
 	DeleteUnit(c *gin.Context)
 	GetUnitsCount(c *gin.Context)
 	RegisterRoutes(r *gin.RouterGroup)
 }
 
 type unitHandler struct {
-	unitRepo service.UnitService
-	log      *logger.Logger
+	unitRepo    service.UnitService
+	courseRepo  service.CourseService
+	versionRepo service.CourseVersionService
+	log         *logger.Logger
 }
 
-func NewUnitHandler(unitRepo service.UnitService) UnitHandler {
+func NewUnitHandler(unitRepo service.UnitService, courseRepo service.CourseService,
+	versionRepo service.CourseVersionService) UnitHandler {
 	return &unitHandler{
-		unitRepo: unitRepo,
-		log:      logger.Get(),
+		unitRepo:    unitRepo,
+		courseRepo:  courseRepo,
+		versionRepo: versionRepo,
+		log:         logger.Get(),
 	}
 }
 
+// learnerCourseID returns the course whose units a student is shown when
+// they ask for courseID, which is the published version they are pinned to.
+// Everyone else works with courseID itself.
+func (h *unitHandler) learnerCourseID(c *gin.Context, courseID int64) (int64, error) {
+	if GetUserRole(c) != models.RoleStudent {
+		return courseID, nil
+	}
+	userID, err := GetUserID(c)
+	if err != nil {
+		return 0, err
+	}
+	return h.versionRepo.GetLearnerCourseID(c.Request.Context(), userID, courseID)
+}
+
 func (h *unitHandler) CreateUnit(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -88,6 +112,16 @@ func (h *unitHandler) CreateUnit(c *gin.Context) {
 func (h *unitHandler) GetUnitByID(c *gin.Context) {
 	ctx := c.Request.Context()
 
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid course ID: must be a positive integer",
+		})
+		return
+	}
+
 	unitID, err := strconv.ParseInt(c.Param("unitId"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.Response{
@@ -98,7 +132,18 @@ func (h *unitHandler) GetUnitByID(c *gin.Context) {
 		return
 	}
 
-	unit, err := h.unitRepo.GetUnitByID(ctx, unitID)
+	courseID, err = h.learnerCourseID(c, courseID)
+	if err != nil {
+		h.log.WithError(err).Error("failed to resolve course version")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while getting unit by ID",
+		})
+		return
+	}
+
+	unit, err := h.unitRepo.GetCourseUnit(ctx, courseID, unitID)
 	if errors.Is(err, sql.ErrNoRows) {
 		h.log.WithError(err).Warn("unit not found")
 		c.JSON(http.StatusOK, models.Response{
@@ -137,6 +182,17 @@ func (h *unitHandler) GetUnitsByCourseID(c *gin.Context) {
 		return
 	}
 
+	courseID, err = h.learnerCourseID(c, courseID)
+	if err != nil {
+		h.log.WithError(err).Error("failed to resolve course version")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while getting units by course ID",
+		})
+		return
+	}
+
 	units, err := h.unitRepo.GetUnitsByCourseID(ctx, courseID)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		h.log.WithError(err).Error("failed to get units by course ID")
@@ -237,6 +293,104 @@ func (h *unitHandler) UpdateUnitNumber(c *gin.Context) {
 	})
 }
 
+func (h *unitHandler) ReorderUnits(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	courseID, err := strconv.ParseInt(c.Param("courseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid course ID: must be a positive integer",
+		})
+		return
+	}
+
+	var order models.Reorder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	units, err := h.unitRepo.ReorderUnits(ctx, courseID, order.IDs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidOrder) {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidInput,
+				Message:   err.Error(),
+			})
+			return
+		}
+		h.log.WithError(err).Error("failed to reorder units")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while reordering units",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "units reordered successfully",
+		Payload: units,
+	})
+}
+
+func (h *unitHandler) CloneUnit(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	unitID, err := strconv.ParseInt(c.Param("unitId"), 10, 64)
+	if err != nil || unitID <= 0 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid unit ID: must be a positive integer",
+		})
+		return
+	}
+
+	var req models.CloneRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	unit, err := h.unitRepo.CloneUnit(ctx, unitID, req.Name)
+	if err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "unit not found",
+			})
+			return
+		}
+		h.log.WithError(err).Error("failed to clone unit")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error while cloning unit",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "unit cloned successfully",
+		Payload: unit,
+	})
+}
+
 func (h *unitHandler) DeleteUnit(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -252,6 +406,14 @@ func (h *unitHandler) DeleteUnit(c *gin.Context) {
 
 	err = h.unitRepo.DeleteUnit(ctx, unitID)
 	if err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.NoData,
+				Message:   "unit not found",
+			})
+			return
+		}
 		h.log.WithError(err).Error("failed to delete unit")
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Success:   false,
@@ -282,13 +444,24 @@ func (h *unitHandler) RegisterRoutes(r *gin.RouterGroup) {
 	units := r.Group("/courses/:courseId/units")
 
 	units.GET("/count", h.GetUnitsCount)
+
+	// Reads need the caller's role to serve students the version of the
+	// course they are enrolled in.
+	authorized := units.Group("", middleware.Auth())
 	{
-		units.POST("", h.CreateUnit)
-		units.GET("/:unitId", h.GetUnitByID)
-		units.GET("", h.GetUnitsByCourseID)
-		units.PUT("/:unitId", h.UpdateUnit)
-		units.PUT("/:unitId/number", h.UpdateUnitNumber)
-		units.DELETE("/:unitId", h.DeleteUnit)
+		authorized.GET("/:unitId", h.GetUnitByID)
+		authorized.GET("", h.GetUnitsByCourseID)
+	}
 
+	authors := authorized.Group("",
+		middleware.RequireRole(models.RoleAdmin, models.RoleInstructor),
+		RequireCourseAuthor(h.courseRepo))
+	{
+		authors.POST("", h.CreateUnit)
+		authors.PUT("/:unitId", h.UpdateUnit)
+		authors.PUT("/:unitId/number", h.UpdateUnitNumber)
+		authors.PUT("/order", h.ReorderUnits)
+		authors.POST("/:unitId/clone", h.CloneUnit)
+		authors.DELETE("/:unitId", h.DeleteUnit)
 	}
 }
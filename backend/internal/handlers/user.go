@@ -7,6 +7,7 @@ import (
 	"algolearn/pkg/logger"
 	"algolearn/pkg/middleware"
 	"algolearn/pkg/security"
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -26,6 +27,14 @@ type UserHandler interface {
 	RegisterUser(c *gin.Context)
 	LoginUser(c *gin.Context)
 	RefreshToken(c *gin.Context)
+	SignOut(c *gin.Context)
+	SignOutEverywhere(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerificationEmail(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	CompleteMFASignIn(c *gin.Context)
+	UnlockUser(c *gin.Context)
 	UpdateUser(c *gin.Context)
 	GetUser(c *gin.Context)
 	GetUsers(c *gin.Context)
@@ -36,12 +45,36 @@ type UserHandler interface {
 }
 
 type userHandler struct {
-	repo service.UserService
-	log  *logger.Logger
+	repo     service.UserService
+	sessions service.SessionService
+	accounts service.AccountService
+	attempts service.LoginAttemptService
+	mfa      service.MFAService
+	limiter  *middleware.RateLimiter
+	log      *logger.Logger
 }
 
-func NewUserHandler(repo service.UserService) UserHandler {
-	return &userHandler{repo: repo, log: logger.Get()}
+func NewUserHandler(repo service.UserService, sessions service.SessionService, accounts service.AccountService, attempts service.LoginAttemptService, mfa service.MFAService, limiter *middleware.RateLimiter) UserHandler {
+	return &userHandler{repo: repo, sessions: sessions, accounts: accounts, attempts: attempts, mfa: mfa, limiter: limiter, log: logger.Get()}
+}
+
+const minPasswordLength = 8
+
+// maxDeviceNameLength matches refresh_tokens.device_name.
+const maxDeviceNameLength = 255
+
+// deviceInfo describes the calling client. Apps may name the device with the
+// X-Device-Name header so users can tell their sessions apart.
+func deviceInfo(c *gin.Context) models.DeviceInfo {
+	name := c.GetHeader("X-Device-Name")
+	if len(name) > maxDeviceNameLength {
+		name = name[:maxDeviceNameLength]
+	}
+	return models.DeviceInfo{
+		DeviceName: name,
+		UserAgent:  c.Request.UserAgent(),
+		IPAddress:  c.ClientIP(),
+	}
 }
 
 func (h *userHandler) ValidateEmail(email string) bool {
@@ -54,7 +87,7 @@ func (h *userHandler) validateRegistrationInput(req models.RegistrationRequest)
 	if len(req.Username) < 5 || len(req.Username) > 20 {
 		return false, "username must be between 5 and 20 characters long"
 	}
-	if len(req.Password) < 8 {
+	if len(req.Password) < minPasswordLength {
 		return false, "password must be at least 8 characters long"
 	}
 	if !h.ValidateEmail(req.Email) {
@@ -154,7 +187,7 @@ func (h *userHandler) RegisterUser(c *gin.Context) {
 		Username:        req.Username,
 		Email:           req.Email,
 		PasswordHash:    hashedPassword,
-		Role:            "admin",
+		Role:            models.RoleStudent,
 		IsActive:        true,
 		IsEmailVerified: false,
 		CPUs:            0,
@@ -171,7 +204,7 @@ func (h *userHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
-	token, err := security.GenerateJWT(newUser.ID)
+	token, err := security.GenerateJWT(newUser.ID, newUser.Role, false)
 	if err != nil {
 		log.WithError(err).Error("failed to generate JWT")
 		c.JSON(http.StatusInternalServerError,
@@ -183,9 +216,9 @@ func (h *userHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
-	refreshToken, err := security.GenerateRefreshToken(newUser.ID)
+	refreshToken, err := h.sessions.IssueRefreshToken(ctx, newUser.ID, false, deviceInfo(c))
 	if err != nil {
-		log.WithError(err).Error("failed to generate JWT")
+		log.WithError(err).Error("failed to issue refresh token")
 		c.JSON(http.StatusInternalServerError,
 			models.Response{
 				Success:   false,
@@ -195,6 +228,13 @@ func (h *userHandler) RegisterUser(c *gin.Context) {
 		return
 	}
 
+	// mail the verification link without holding up the signup response
+	go func(userID int32) {
+		if err := h.accounts.SendEmailVerification(context.WithoutCancel(ctx), userID); err != nil {
+			log.WithError(err).Error("failed to send verification email")
+		}
+	}(newUser.ID)
+
 	response := models.Response{
 		Success: true,
 		Message: "user created successfully",
@@ -220,9 +260,31 @@ func (h *userHandler) LoginUser(c *gin.Context) {
 		return
 	}
 
+	retryAfter, err := h.attempts.CheckLockout(c.Request.Context(), req.Email, c.ClientIP())
+	if err != nil {
+		log.WithError(err).Error("failed to check login lockout")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error",
+		})
+		return
+	}
+	if retryAfter > 0 {
+		log.Debugf("login for %s from %s rejected while locked", req.Email, c.ClientIP())
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		c.JSON(http.StatusTooManyRequests, models.Response{
+			Success:   false,
+			ErrorCode: httperr.AccountLocked,
+			Message:   "too many failed sign-in attempts, try again later",
+		})
+		return
+	}
+
 	user, err := h.repo.GetUserByEmail(c.Request.Context(), req.Email)
 	if err != nil {
 		log.WithError(err).Error("failed to get user by email")
+		h.recordLoginFailure(c, req.Email)
 		c.JSON(http.StatusUnauthorized,
 			models.Response{
 				Success:   false,
@@ -234,6 +296,7 @@ func (h *userHandler) LoginUser(c *gin.Context) {
 
 	if !security.CheckPasswordHash(req.Password, user.PasswordHash) {
 		log.Printf("login failed for user %s: invalid password", req.Email)
+		h.recordLoginFailure(c, req.Email)
 		c.JSON(http.StatusUnauthorized, models.Response{
 			Success:   false,
 			ErrorCode: httperr.InvalidCredentials,
@@ -242,8 +305,148 @@ func (h *userHandler) LoginUser(c *gin.Context) {
 		return
 	}
 
+	if err := h.attempts.RecordSuccess(c.Request.Context(), req.Email); err != nil {
+		log.WithError(err).Warn("failed to clear failed login attempts")
+	}
+
+	mfaEnabled, err := h.mfa.IsEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		log.WithError(err).Error("failed to check MFA settings")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error",
+		})
+		return
+	}
+
+	// With MFA on, the password only earns a challenge for CompleteMFASignIn
+	if mfaEnabled {
+		challenge, err := h.mfa.IssueChallenge(user.ID)
+		if err != nil {
+			log.WithError(err).Error("failed to issue MFA challenge")
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InternalError,
+				Message:   "Failed to generate authentication token",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.Response{
+			Success: true,
+			Message: "multi-factor authentication required",
+			Payload: models.MFAChallengeResponse{
+				MFARequired: true,
+				MFAToken:    challenge,
+			},
+		})
+		return
+	}
+
+	h.completeSignIn(c, user, false)
+}
+
+// CompleteMFASignIn finishes a sign-in started by LoginUser by checking a
+// TOTP or recovery code against its challenge token.
+func (h *userHandler) CompleteMFASignIn(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "CompleteMFASignIn")
+	ctx := c.Request.Context()
+
+	var req models.MFASignInRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid JSON",
+		})
+		return
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.MissingFields,
+			Message:   "mfaToken and code are required",
+		})
+		return
+	}
+
+	userID, err := h.mfa.ValidateChallenge(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidToken,
+			Message:   "invalid or expired MFA token",
+		})
+		return
+	}
+
+	user, err := h.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get user")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.AccountNotFound,
+			Message:   "User account not found",
+		})
+		return
+	}
+
+	// codes are guessable, so they count towards the same lockout as passwords
+	retryAfter, err := h.attempts.CheckLockout(ctx, user.Email, c.ClientIP())
+	if err != nil {
+		log.WithError(err).Error("failed to check login lockout")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error",
+		})
+		return
+	}
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		c.JSON(http.StatusTooManyRequests, models.Response{
+			Success:   false,
+			ErrorCode: httperr.AccountLocked,
+			Message:   "too many failed sign-in attempts, try again later",
+		})
+		return
+	}
+
+	if err := h.mfa.VerifyCode(ctx, user.ID, req.Code); err != nil {
+		if errors.Is(err, service.ErrInvalidMFACode) || errors.Is(err, service.ErrMFANotEnabled) {
+			h.recordLoginFailure(c, user.Email)
+			c.JSON(http.StatusUnauthorized, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidMFACode,
+				Message:   "invalid authentication code",
+			})
+			return
+		}
+		log.WithError(err).Error("failed to verify MFA code")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error",
+		})
+		return
+	}
+
+	if err := h.attempts.RecordSuccess(ctx, user.Email); err != nil {
+		log.WithError(err).Warn("failed to clear failed login attempts")
+	}
+
+	h.completeSignIn(c, user, true)
+}
+
+// completeSignIn issues the access and refresh tokens for a user who passed
+// every sign-in step.
+func (h *userHandler) completeSignIn(c *gin.Context, user *models.User, mfaVerified bool) {
+	log := h.log.WithBaseFields(logger.Handler, "completeSignIn")
+
 	// Generate access token
-	accessToken, err := security.GenerateJWT(user.ID)
+	accessToken, err := security.GenerateJWT(user.ID, user.Role, mfaVerified)
 	if err != nil {
 		log.WithError(err).Error("Failed to generate access token")
 		c.JSON(http.StatusInternalServerError, models.Response{
@@ -255,7 +458,7 @@ func (h *userHandler) LoginUser(c *gin.Context) {
 	}
 
 	// Generate refresh token
-	refreshToken, err := security.GenerateRefreshToken(user.ID)
+	refreshToken, err := h.sessions.IssueRefreshToken(c.Request.Context(), user.ID, mfaVerified, deviceInfo(c))
 	if err != nil {
 		log.WithError(err).Error("Failed to generate refresh token")
 		c.JSON(http.StatusInternalServerError, models.Response{
@@ -280,8 +483,17 @@ func (h *userHandler) LoginUser(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// recordLoginFailure counts a failed sign-in. A failure to record it is only
+// logged; the caller still gets the invalid credentials response.
+func (h *userHandler) recordLoginFailure(c *gin.Context, email string) {
+	if err := h.attempts.RecordFailure(c.Request.Context(), email, c.ClientIP()); err != nil {
+		h.log.WithBaseFields(logger.Handler, "recordLoginFailure").WithError(err).Warn("failed to record failed login attempt")
+	}
+}
+
 func (h *userHandler) RefreshToken(c *gin.Context) {
 	log := h.log.WithBaseFields(logger.Handler, "RefreshToken")
+	ctx := c.Request.Context()
 	var req models.RefreshTokenRequest
 	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.Response{
@@ -292,20 +504,29 @@ func (h *userHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// Validate refresh token
-	claims, err := security.ValidateRefreshToken(req.RefreshToken)
+	// Rotate the refresh token; reuse of a rotated token revokes its family
+	rotated, err := h.sessions.RotateRefreshToken(ctx, req.RefreshToken, deviceInfo(c))
 	if err != nil {
-		log.WithError(err).Warn("Invalid refresh token")
-		c.JSON(http.StatusUnauthorized, models.Response{
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			log.WithError(err).Warn("Invalid refresh token")
+			c.JSON(http.StatusUnauthorized, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidToken,
+				Message:   "Invalid or expired refresh token",
+			})
+			return
+		}
+		log.WithError(err).Error("Failed to rotate refresh token")
+		c.JSON(http.StatusInternalServerError, models.Response{
 			Success:   false,
-			ErrorCode: httperr.InvalidToken,
-			Message:   "Invalid or expired refresh token",
+			ErrorCode: httperr.InternalError,
+			Message:   "Failed to generate new refresh token",
 		})
 		return
 	}
 
 	// Get user data
-	user, err := h.repo.GetUserByID(c.Request.Context(), claims.UserID)
+	user, err := h.repo.GetUserByID(ctx, rotated.UserID)
 	if err != nil {
 		log.WithError(err).Error("Failed to get user data during token refresh")
 		c.JSON(http.StatusUnauthorized, models.Response{
@@ -317,7 +538,7 @@ func (h *userHandler) RefreshToken(c *gin.Context) {
 	}
 
 	// Generate new access token
-	accessToken, err := security.GenerateJWT(claims.UserID)
+	accessToken, err := security.GenerateJWT(user.ID, user.Role, rotated.MFAVerified)
 	if err != nil {
 		log.WithError(err).Error("Failed to generate new access token")
 		c.JSON(http.StatusInternalServerError, models.Response{
@@ -328,26 +549,245 @@ func (h *userHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// Generate new refresh token
-	newRefreshToken, err := security.GenerateRefreshToken(claims.UserID)
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Tokens refreshed successfully",
+		Payload: models.AuthResponse{
+			Token:        accessToken,
+			RefreshToken: rotated.Token,
+			User:         *user,
+		},
+	})
+}
+
+// SignOut revokes the session of the given refresh token. Access tokens
+// already issued for it stay valid until they expire.
+func (h *userHandler) SignOut(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "SignOut")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
 	if err != nil {
-		log.WithError(err).Error("Failed to generate new refresh token")
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to sign out",
+		})
+		return
+	}
+
+	var req models.RefreshTokenRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid JSON",
+		})
+		return
+	}
+
+	if err := h.sessions.RevokeRefreshToken(ctx, userID, req.RefreshToken); err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidToken,
+				Message:   "invalid refresh token",
+			})
+			return
+		}
+		log.WithError(err).Error("failed to revoke refresh token")
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Success:   false,
 			ErrorCode: httperr.InternalError,
-			Message:   "Failed to generate new refresh token",
+			Message:   "failed to sign out",
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, models.Response{
 		Success: true,
-		Message: "Tokens refreshed successfully",
-		Payload: models.AuthResponse{
-			Token:        accessToken,
-			RefreshToken: newRefreshToken,
-			User:         *user,
-		},
+		Message: "signed out successfully",
+	})
+}
+
+// SignOutEverywhere revokes every refresh token the user holds.
+func (h *userHandler) SignOutEverywhere(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "SignOutEverywhere")
+	ctx := c.Request.Context()
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to sign out",
+		})
+		return
+	}
+
+	revoked, err := h.sessions.RevokeAllRefreshTokens(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to revoke refresh tokens")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InternalError,
+			Message:   "failed to sign out",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "signed out of all devices",
+		Payload: map[string]interface{}{"revoked": revoked},
+	})
+}
+
+func (h *userHandler) VerifyEmail(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "VerifyEmail")
+	var req models.VerifyEmailRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid JSON",
+		})
+		return
+	}
+
+	if err := h.accounts.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, security.ErrInvalidActionToken) {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidToken,
+				Message:   "invalid or expired verification link",
+			})
+			return
+		}
+		log.WithError(err).Error("failed to verify email")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InternalError,
+			Message:   "failed to verify email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "email verified successfully",
+	})
+}
+
+func (h *userHandler) ResendVerificationEmail(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ResendVerificationEmail")
+
+	userID, err := GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.Response{
+			Success:   false,
+			ErrorCode: httperr.Unauthorized,
+			Message:   "authentication required to verify email",
+		})
+		return
+	}
+
+	if err := h.accounts.SendEmailVerification(c.Request.Context(), userID); err != nil {
+		log.WithError(err).Error("failed to send verification email")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InternalError,
+			Message:   "failed to send verification email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "verification email sent",
+	})
+}
+
+// ForgotPassword always reports success so it cannot be used to find out
+// which emails have accounts.
+func (h *userHandler) ForgotPassword(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ForgotPassword")
+	ctx := c.Request.Context()
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid JSON",
+		})
+		return
+	}
+
+	if !h.ValidateEmail(req.Email) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidFormData,
+			Message:   "invalid email format",
+		})
+		return
+	}
+
+	// send in the background so response times do not reveal whether the
+	// account exists
+	go func(email string) {
+		if err := h.accounts.RequestPasswordReset(context.WithoutCancel(ctx), email); err != nil {
+			log.WithError(err).Error("failed to request password reset")
+		}
+	}(req.Email)
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "if an account exists for this email, a reset link has been sent",
+	})
+}
+
+func (h *userHandler) ResetPassword(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "ResetPassword")
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidJson,
+			Message:   "invalid JSON",
+		})
+		return
+	}
+
+	if len(req.Password) < minPasswordLength {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidFormData,
+			Message:   "password must be at least 8 characters long",
+		})
+		return
+	}
+
+	if err := h.accounts.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, security.ErrInvalidActionToken) {
+			c.JSON(http.StatusBadRequest, models.Response{
+				Success:   false,
+				ErrorCode: httperr.InvalidToken,
+				Message:   "invalid or expired reset link",
+			})
+			return
+		}
+		log.WithError(err).Error("failed to reset password")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InternalError,
+			Message:   "failed to reset password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "password reset successfully",
 	})
 }
 
@@ -378,7 +818,8 @@ func (h *userHandler) UpdateUser(c *gin.Context) {
 	}
 
 	user.ID = userID
-	if err := h.repo.UpdateUser(ctx, &user); err != nil {
+	emailChanged, err := h.repo.UpdateUser(ctx, &user)
+	if err != nil {
 		log.WithError(err).Error("failed to update user")
 		c.JSON(http.StatusInternalServerError,
 			models.Response{
@@ -389,6 +830,15 @@ func (h *userHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if emailChanged {
+		// the new address has to be verified before it counts as verified
+		go func(userID int32) {
+			if err := h.accounts.SendEmailVerification(context.WithoutCancel(ctx), userID); err != nil {
+				log.WithError(err).Error("failed to send verification email")
+			}
+		}(user.ID)
+	}
+
 	updatedUser, err := h.repo.GetUserByID(ctx, user.ID)
 	if err != nil {
 		log.WithError(err).Error("failed to fetch updated user")
@@ -480,7 +930,7 @@ func (h *userHandler) GetUsers(c *gin.Context) {
 		return
 	}
 
-	if user.Role != "admin" && user.Role != "instructor" {
+	if user.Role != models.RoleAdmin && user.Role != models.RoleInstructor {
 		c.JSON(http.StatusUnauthorized, models.Response{Success: false, Message: "Unauthorized"})
 		return
 	}
@@ -589,6 +1039,45 @@ func (h *userHandler) GetReceivedAchievementsCount(c *gin.Context) {
 	c.JSON(http.StatusOK, models.Response{Success: true, Message: "user achievements count retrieved successfully", Payload: count})
 }
 
+// UnlockUser lets an admin clear an account's failed sign-in attempts and
+// lift its lockout.
+func (h *userHandler) UnlockUser(c *gin.Context) {
+	log := h.log.WithBaseFields(logger.Handler, "UnlockUser")
+
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 32)
+	if err != nil || userID < 1 {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success:   false,
+			ErrorCode: httperr.InvalidInput,
+			Message:   "invalid user ID: must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.attempts.UnlockAccount(c.Request.Context(), int32(userID)); err != nil {
+		if errors.Is(err, httperr.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.Response{
+				Success:   false,
+				ErrorCode: httperr.AccountNotFound,
+				Message:   "user not found",
+			})
+			return
+		}
+		log.WithError(err).Error("failed to unlock user")
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success:   false,
+			ErrorCode: httperr.DatabaseFail,
+			Message:   "internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "user unlocked successfully",
+	})
+}
+
 func (h *userHandler) RegisterRoutes(r *gin.RouterGroup) {
 	// Route Groups
 	users := r.Group("/users")
@@ -596,15 +1085,27 @@ func (h *userHandler) RegisterRoutes(r *gin.RouterGroup) {
 
 	// Public routes
 	users.GET("/achievements/count", h.GetReceivedAchievementsCount)
-	users.POST("/sign-up", h.RegisterUser)
-	users.POST("/sign-in", h.LoginUser)
-	users.GET("/check-email", h.CheckEmailExists)
-	users.POST("/refresh-token", h.RefreshToken)
+
+	accounts := users.Group("", h.limiter.RateLimit(middleware.AuthRateLimit))
+	accounts.POST("/sign-up", h.RegisterUser)
+	accounts.POST("/sign-in", h.LoginUser)
+	accounts.POST("/sign-in/mfa", h.CompleteMFASignIn)
+	accounts.GET("/check-email", h.CheckEmailExists)
+	accounts.POST("/refresh-token", h.RefreshToken)
+	accounts.POST("/verify-email", h.VerifyEmail)
+	accounts.POST("/forgot-password", h.ForgotPassword)
+	accounts.POST("/reset-password", h.ResetPassword)
 
 	// Protected routes (require authentication)
 	authorized.GET("", h.GetUsers)
 	authorized.GET("/me", h.GetUser)
 	authorized.GET("/count", h.GetUsersCount)
 	authorized.PUT("/me", h.UpdateUser)
+	authorized.POST("/sign-out", h.SignOut)
+	authorized.POST("/sign-out-everywhere", h.SignOutEverywhere)
+	authorized.POST("/me/verify-email", h.ResendVerificationEmail)
+
+	admins := authorized.Group("", middleware.RequireRole(models.RoleAdmin), middleware.RequireMFA())
+	admins.POST("/:userId/unlock", h.UnlockUser)
 	// authorized.PUT("/me/preferences", h.UpdateUserPreferences)
 }
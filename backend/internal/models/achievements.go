@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type Streak struct {
 	ID            int32     `json:"id"`
@@ -13,15 +16,49 @@ type Streak struct {
 	UpdatedAt     time.Time `json:"updatedAt"`
 }
 
+type StreakLeaderboardEntry struct {
+	Rank              int    `json:"rank"`
+	UserID            int32  `json:"userId"`
+	Username          string `json:"username"`
+	ProfilePictureURL string `json:"profilePictureUrl"`
+	Streak            int32  `json:"streak"`
+	CPUs              int32  `json:"cpus"`
+}
+
+// Achievement criteria understood by the awarding engine. An achievement is
+// granted once the user's metric for its criteria reaches Threshold.
+const (
+	CriteriaModulesCompleted = "modules_completed"
+	CriteriaCoursesCompleted = "courses_completed"
+	CriteriaStreakDays       = "streak_days"
+	CriteriaPerfectQuiz      = "perfect_quiz"
+)
+
 type Achievement struct {
 	ID          int32     `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Points      int32     `json:"points"`
+	Criteria    string    `json:"criteria,omitempty"`
+	Threshold   int32     `json:"threshold"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 }
 
+func (a *Achievement) Validate() error {
+	switch a.Criteria {
+	case "", CriteriaModulesCompleted, CriteriaCoursesCompleted, CriteriaStreakDays, CriteriaPerfectQuiz:
+	default:
+		return errors.New("unknown achievement criteria")
+	}
+
+	if a.Criteria != "" && a.Threshold <= 0 {
+		return errors.New("achievement threshold must be positive")
+	}
+
+	return nil
+}
+
 type UserAchievement struct {
 	ID            int32     `json:"id"`
 	UserID        int64     `json:"userId"`
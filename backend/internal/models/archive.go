@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CourseArchiveVersion is the version of the archive format written by course
+// exports. Archives of this version or older can be imported.
+const CourseArchiveVersion = 1
+
+// A course archive is a zip file holding the manifest and, under the media
+// directory, every media object the course refers to at its storage key.
+const (
+	CourseArchiveManifest = "manifest.json"
+	CourseArchiveMediaDir = "media/"
+	// MaxCourseArchiveBytes is the largest archive accepted for import.
+	MaxCourseArchiveBytes = 512 << 20
+)
+
+// CourseArchive is the manifest of a course archive. IDs and object keys are
+// those of the environment the course was exported from; imports give
+// everything new ones.
+type CourseArchive struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	Course     ArchiveCourse  `json:"course"`
+	Media      []ArchiveMedia `json:"media"`
+}
+
+// ArchiveMedia is a media object stored in the archive at
+// CourseArchiveMediaDir + Key.
+type ArchiveMedia struct {
+	Key         string `json:"key"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+type ArchiveCourse struct {
+	ID              int64           `json:"id"`
+	FolderObjectKey uuid.NullUUID   `json:"folderObjectKey"`
+	ImgKey          uuid.NullUUID   `json:"imgKey"`
+	MediaExt        string          `json:"mediaExt"`
+	Name            string          `json:"name"`
+	Description     string          `json:"description"`
+	Requirements    string          `json:"requirements"`
+	WhatYouLearn    string          `json:"whatYouLearn"`
+	BackgroundColor string          `json:"backgroundColor"`
+	Duration        int32           `json:"duration"`
+	DifficultyLevel DifficultyLevel `json:"difficultyLevel"`
+	Rating          float64         `json:"rating"`
+	Tags            []string        `json:"tags"`
+	Units           []ArchiveUnit   `json:"units"`
+}
+
+type ArchiveUnit struct {
+	ID              int64           `json:"id"`
+	FolderObjectKey uuid.NullUUID   `json:"folderObjectKey"`
+	ImgKey          uuid.NullUUID   `json:"imgKey"`
+	MediaExt        string          `json:"mediaExt"`
+	UnitNumber      int32           `json:"unitNumber"`
+	Name            string          `json:"name"`
+	Description     string          `json:"description"`
+	Modules         []ArchiveModule `json:"modules"`
+}
+
+type ArchiveModule struct {
+	ID              int64            `json:"id"`
+	FolderObjectKey uuid.NullUUID    `json:"folderObjectKey"`
+	ImgKey          uuid.NullUUID    `json:"imgKey"`
+	MediaExt        string           `json:"mediaExt"`
+	ModuleNumber    int32            `json:"moduleNumber"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	Sections        []ArchiveSection `json:"sections"`
+}
+
+// ArchiveSection holds its content in the shape accepted when a module is
+// created with content, answer keys and hidden test cases included.
+type ArchiveSection struct {
+	ID       int64           `json:"id"`
+	Type     SectionType     `json:"type"`
+	Position int16           `json:"position"`
+	Content  json.RawMessage `json:"content"`
+}
+
+// CourseImportResult describes an import. IDMap is only set once the import
+// has been committed.
+type CourseImportResult struct {
+	DryRun   bool          `json:"dryRun"`
+	CourseID int64         `json:"courseId,omitempty"`
+	Units    int           `json:"units"`
+	Modules  int           `json:"modules"`
+	Sections int           `json:"sections"`
+	Media    int           `json:"media"`
+	IDMap    *ArchiveIDMap `json:"idMap,omitempty"`
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+// ArchiveIDMap maps the IDs and media keys of an archive to those they were
+// imported as.
+type ArchiveIDMap struct {
+	Units    map[int64]int64   `json:"units"`
+	Modules  map[int64]int64   `json:"modules"`
+	Sections map[int64]int64   `json:"sections"`
+	Media    map[string]string `json:"media"`
+}
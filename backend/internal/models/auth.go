@@ -24,3 +24,23 @@ type AuthResponse struct {
 	RefreshToken string `json:"refreshToken"`
 	User         User   `json:"user"`
 }
+
+// DeviceInfo describes the client a refresh token was issued to.
+type DeviceInfo struct {
+	DeviceName string `json:"deviceName"`
+	UserAgent  string `json:"userAgent"`
+	IPAddress  string `json:"ipAddress"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// CourseVersion is a published version of a course. Publishing copies the
+// course into a snapshot course that learners read and record progress
+// against, while the course itself stays the working copy authors edit.
+type CourseVersion struct {
+	ID               int64     `json:"id"`
+	CourseID         int64     `json:"courseId"`
+	Version          int32     `json:"version"`
+	SnapshotCourseID int64     `json:"snapshotCourseId"`
+	PublishedBy      *int64    `json:"publishedBy,omitempty"`
+	PublishedAt      time.Time `json:"publishedAt"`
+}
+
+// CourseVersions lists the published versions of a course, newest first.
+// Current is the version the user's progress is pinned to, or 0 if they are
+// not enrolled in a published version.
+type CourseVersions struct {
+	Versions []CourseVersion `json:"versions"`
+	Current  int32           `json:"current"`
+}
+
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// ItemChange is a unit, module or section that differs between two versions.
+// FromID and ToID are its IDs in each version's snapshot, and Fields names
+// what changed.
+type ItemChange struct {
+	Change ChangeKind `json:"change"`
+	FromID int64      `json:"fromId,omitempty"`
+	ToID   int64      `json:"toId,omitempty"`
+	Name   string     `json:"name"`
+	Fields []string   `json:"fields,omitempty"`
+}
+
+type CourseVersionDiff struct {
+	CourseID int64        `json:"courseId"`
+	From     int32        `json:"from"`
+	To       int32        `json:"to"`
+	Units    []ItemChange `json:"units"`
+	Modules  []ItemChange `json:"modules"`
+	Sections []ItemChange `json:"sections"`
+}
+
+// CourseMigration describes moving a learner's progress to the latest version
+// of a course. FromVersion is 0 when they were enrolled in the working copy.
+// Progress is carried over for sections whose content did not change, except
+// questions and exercises, which are reset to be answered again.
+type CourseMigration struct {
+	CourseID         int64 `json:"courseId"`
+	FromVersion      int32 `json:"fromVersion"`
+	ToVersion        int32 `json:"toVersion"`
+	SnapshotCourseID int64 `json:"snapshotCourseId"`
+	CarriedSections  int   `json:"carriedSections"`
+	ResetSections    int   `json:"resetSections"`
+}
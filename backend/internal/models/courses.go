@@ -52,6 +52,18 @@ type Unit struct {
 	Modules         []Module      `json:"modules"`
 }
 
+// Reorder is the new order of a course's units, a unit's modules or a
+// module's sections, as their IDs from first to last.
+type Reorder struct {
+	IDs []int64 `json:"ids"`
+}
+
+// CloneRequest names the copy of a course, unit or module. An empty name
+// names it after the original.
+type CloneRequest struct {
+	Name string `json:"name"`
+}
+
 type CourseQuery struct {
 	Page     int         `json:"page"`
 	PageSize int         `json:"page_size"`
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExerciseLanguages are the languages exercises can be written in.
+var ExerciseLanguages = []string{"python", "javascript"}
+
+const (
+	DefaultExerciseTimeLimitMs   = 2000
+	DefaultExerciseMemoryLimitKb = 256 * 1024
+
+	MaxExerciseTestCases = 20
+	// MaxExerciseCodeBytes is the largest submission accepted.
+	MaxExerciseCodeBytes = 64 << 10
+	// MaxExerciseRunTime bounds the time limit summed over all test cases, so
+	// a submission is graded within the request timeout.
+	MaxExerciseRunTime = 8 * time.Second
+)
+
+// TestCase feeds Input to a submission on stdin and expects ExpectedOutput
+// on stdout. Hidden test cases are never shown to learners.
+type TestCase struct {
+	Input          string `json:"input"`
+	ExpectedOutput string `json:"expectedOutput"`
+	Hidden         bool   `json:"hidden"`
+}
+
+// ExerciseContent is a coding exercise. Learners only receive the visible
+// test cases, and Passed once they have a passing submission.
+type ExerciseContent struct {
+	Prompt        string        `json:"prompt"`
+	Language      string        `json:"language"`
+	StarterCode   string        `json:"starterCode"`
+	TimeLimitMs   int32         `json:"timeLimitMs"`
+	MemoryLimitKb int32         `json:"memoryLimitKb"`
+	TestCases     []TestCase    `json:"testCases"`
+	Passed        bool          `json:"passed"`
+	ObjectKey     uuid.NullUUID `json:"objectKey"`
+	MediaExt      string        `json:"mediaExt"`
+}
+
+// ApplyDefaults fills in the limits left unset.
+func (e *ExerciseContent) ApplyDefaults() {
+	if e.TimeLimitMs == 0 {
+		e.TimeLimitMs = DefaultExerciseTimeLimitMs
+	}
+	if e.MemoryLimitKb == 0 {
+		e.MemoryLimitKb = DefaultExerciseMemoryLimitKb
+	}
+}
+
+// Validate checks that the exercise can be run and graded.
+func (e *ExerciseContent) Validate() error {
+	if strings.TrimSpace(e.Prompt) == "" {
+		return errors.New("exercise prompt is required")
+	}
+	if !slices.Contains(ExerciseLanguages, e.Language) {
+		return fmt.Errorf("exercise language must be one of %s", strings.Join(ExerciseLanguages, ", "))
+	}
+	if e.TimeLimitMs < 100 || e.TimeLimitMs > 5000 {
+		return errors.New("exercise time limit must be between 100 and 5000 ms")
+	}
+	if e.MemoryLimitKb < 16*1024 || e.MemoryLimitKb > 512*1024 {
+		return errors.New("exercise memory limit must be between 16 and 512 MB")
+	}
+	if len(e.TestCases) == 0 || len(e.TestCases) > MaxExerciseTestCases {
+		return fmt.Errorf("exercises need between 1 and %d test cases", MaxExerciseTestCases)
+	}
+	if time.Duration(e.TimeLimitMs)*time.Millisecond*time.Duration(len(e.TestCases)) > MaxExerciseRunTime {
+		return fmt.Errorf("exercise time limit times test cases must not exceed %s", MaxExerciseRunTime)
+	}
+	return nil
+}
+
+type ExerciseSection struct {
+	BaseModel
+	Type            SectionType      `json:"type"`
+	Position        int16            `json:"position"`
+	Content         ExerciseContent  `json:"content"`
+	SectionProgress *SectionProgress `json:"sectionProgress"`
+}
+
+func (es *ExerciseSection) GetType() SectionType { return es.Type }
+func (es *ExerciseSection) GetPosition() int16   { return es.Position }
+
+type ExerciseSubmissionRequest struct {
+	Code string `json:"code"`
+}
+
+// ExerciseTestResult is how a submission did on one test case. The input and
+// outputs are left out for hidden test cases.
+type ExerciseTestResult struct {
+	Position       int16  `json:"position"`
+	Hidden         bool   `json:"hidden"`
+	Passed         bool   `json:"passed"`
+	Status         string `json:"status"`
+	DurationMs     int64  `json:"durationMs"`
+	Input          string `json:"input,omitempty"`
+	ExpectedOutput string `json:"expectedOutput,omitempty"`
+	ActualOutput   string `json:"actualOutput,omitempty"`
+	Stderr         string `json:"stderr,omitempty"`
+}
+
+type ExerciseResult struct {
+	SubmissionID int64                `json:"submissionId"`
+	Passed       bool                 `json:"passed"`
+	PassedCount  int                  `json:"passedCount"`
+	TotalCount   int                  `json:"totalCount"`
+	Tests        []ExerciseTestResult `json:"tests"`
+}
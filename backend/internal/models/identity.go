@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// OAuth providers users can sign in with.
+const (
+	ProviderGoogle = "google"
+	ProviderApple  = "apple"
+)
+
+// UserIdentity is an OAuth identity linked to an account.
+type UserIdentity struct {
+	ID        int32     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+}
+
+// OAuthIdentity is the identity a provider asserted at the end of an OAuth
+// flow.
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// OrphanedMedia is a stored media object nothing in the database refers to.
+type OrphanedMedia struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// MediaGCResult is the outcome of collecting orphaned media. Orphans modified
+// within the grace period are only counted in Recent, as they may belong to
+// an upload whose content has not been saved yet.
+type MediaGCResult struct {
+	DryRun        bool            `json:"dryRun"`
+	Scanned       int             `json:"scanned"`
+	Recent        int             `json:"recent"`
+	Orphaned      []OrphanedMedia `json:"orphaned"`
+	OrphanedBytes int64           `json:"orphanedBytes"`
+	Deleted       int             `json:"deleted"`
+}
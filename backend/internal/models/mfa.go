@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// MFAStatus describes a user's second factor.
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabledAt,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recoveryCodesRemaining"`
+}
+
+// TOTPEnrolment is handed to the user to set up their authenticator app.
+type TOTPEnrolment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+// MFACodeRequest carries a TOTP code or a recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// MFASignInRequest completes a sign-in that LoginUser answered with an
+// MFAChallengeResponse.
+type MFASignInRequest struct {
+	MFAToken string `json:"mfaToken"`
+	Code     string `json:"code"`
+}
+
+// MFAChallengeResponse is returned instead of an AuthResponse when the
+// account requires a second factor.
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"`
+}
+
+// RecoveryCodesResponse carries newly generated recovery codes.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
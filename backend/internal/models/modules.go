@@ -120,6 +120,12 @@ func (m *Module) UnmarshalJSON(data []byte) error {
 				return fmt.Errorf("failed to unmarshal image section: %w", err)
 			}
 			section = &s
+		case "exercise":
+			var s ExerciseSection
+			if err := json.Unmarshal(rawSection, &s); err != nil {
+				return fmt.Errorf("failed to unmarshal exercise section: %w", err)
+			}
+			section = &s
 		default:
 			return fmt.Errorf("unknown section type: %s", baseSection.Type)
 		}
@@ -156,6 +162,7 @@ const (
 	SectionTypeVideo    SectionType = "video"
 	SectionTypeImage    SectionType = "image"
 	SectionTypeLottie   SectionType = "lottie"
+	SectionTypeExercise SectionType = "exercise"
 )
 
 type SectionInterface interface {
@@ -172,13 +179,16 @@ type SectionProgress struct {
 	Progress    float32   `json:"progress"`
 }
 
+// QuestionProgress is a learner's answer to a question. Answer holds the
+// response in the shape the question type expects; OptionID alone is still
+// accepted for single option answers. Answers are graded by the server.
 type QuestionProgress struct {
-	QuestionID  int64     `json:"questionId"`
-	OptionID    *int64    `json:"optionId"`
-	HasAnswered bool      `json:"hasAnswered"`
-	IsCorrect   *bool     `json:"isCorrect,omitempty"`
-	AnsweredAt  time.Time `json:"answeredAt"`
-	Progress    float32   `json:"progress"`
+	QuestionID  int64           `json:"questionId"`
+	OptionID    *int64          `json:"optionId"`
+	Answer      *QuestionAnswer `json:"answer,omitempty"`
+	HasAnswered bool            `json:"hasAnswered"`
+	AnsweredAt  time.Time       `json:"answeredAt"`
+	Progress    float32         `json:"progress"`
 }
 
 type BatchModuleProgress struct {
@@ -201,20 +211,27 @@ type VideoContent struct {
 }
 
 type QuestionContent struct {
-	ID                 int64         `json:"id"`
-	Question           string        `json:"question"`
-	Type               string        `json:"type"`
-	Options            []Option      `json:"options"`
-	Tags               []string      `json:"tags"`
-	UserQuestionAnswer *UserAnswer   `json:"userQuestionAnswer,omitempty"`
-	ObjectKey          uuid.NullUUID `json:"objectKey"`
-	MediaExt           string        `json:"mediaExt"`
+	ID                 int64          `json:"id"`
+	Question           string         `json:"question"`
+	Type               QuestionType   `json:"type"`
+	Options            []Option       `json:"options"`
+	Blanks             []Blank        `json:"blanks,omitempty"`
+	BlankCount         int            `json:"blankCount,omitempty"`
+	Numeric            *NumericAnswer `json:"numeric,omitempty"`
+	Explanation        string         `json:"explanation,omitempty"`
+	Tags               []string       `json:"tags"`
+	UserQuestionAnswer *UserAnswer    `json:"userQuestionAnswer,omitempty"`
+	ObjectKey          uuid.NullUUID  `json:"objectKey"`
+	MediaExt           string         `json:"mediaExt"`
 }
 
+// Option is an answer option. IsCorrect is only sent to the authors of a
+// question and Feedback to learners once they have answered it.
 type Option struct {
 	ID        int64  `json:"id"`
 	Content   string `json:"content"`
-	IsCorrect bool   `json:"isCorrect"`
+	IsCorrect bool   `json:"isCorrect,omitempty"`
+	Feedback  string `json:"feedback,omitempty"`
 }
 
 type CodeContent struct {
@@ -251,10 +268,12 @@ type ImageContent struct {
 }
 
 type UserAnswer struct {
-	OptionID   *int64    `json:"optionId"`
-	AnsweredAt time.Time `json:"answeredAt"`
-	IsCorrect  bool      `json:"isCorrect"`
-	Progress   float32   `json:"progress"`
+	OptionID   *int64          `json:"optionId"`
+	Answer     *QuestionAnswer `json:"answer,omitempty"`
+	AnsweredAt time.Time       `json:"answeredAt"`
+	IsCorrect  bool            `json:"isCorrect"`
+	Attempts   int32           `json:"attempts"`
+	Progress   float32         `json:"progress"`
 }
 
 type Section struct {
@@ -268,6 +287,24 @@ type Section struct {
 	Progress        json.RawMessage  `json:"progress"`
 }
 
+// SectionPatch edits the sections of a module in one go. Sections lists the
+// sections the module keeps and adds, in their new order, and Remove the IDs
+// of the sections it drops. Every section of the module must be in one of
+// the two.
+type SectionPatch struct {
+	Sections []SectionEdit `json:"sections"`
+	Remove   []int64       `json:"remove"`
+}
+
+// SectionEdit is a section in a SectionPatch. Sections with an ID keep it and
+// have their content replaced when Content is set; sections without one are
+// added and need a Type and Content. The type of a section cannot change.
+type SectionEdit struct {
+	ID      int64           `json:"id"`
+	Type    SectionType     `json:"type"`
+	Content json.RawMessage `json:"content"`
+}
+
 func (s *Section) UnmarshalJSON(data []byte) error {
 	type TempSection struct {
 		ID              int64            `json:"id"`
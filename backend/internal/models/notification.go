@@ -9,3 +9,9 @@ type Notification struct {
 	Read      bool      `json:"read"`
 	CreatedAt time.Time `json:"createdAt"`
 }
+
+type NotificationPage struct {
+	Items       []Notification `json:"items"`
+	NextCursor  *int32         `json:"nextCursor"`
+	UnreadCount int64          `json:"unreadCount"`
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+type QuestionType string
+
+const (
+	// QuestionTypeMultipleChoice has exactly one correct option.
+	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
+	// QuestionTypeMultiSelect is answered by picking every correct option.
+	QuestionTypeMultiSelect QuestionType = "multi_select"
+	// QuestionTypeTrueFalse has two options, one of them correct.
+	QuestionTypeTrueFalse QuestionType = "true_false"
+	// QuestionTypeFillBlank is answered with free text for each blank.
+	QuestionTypeFillBlank QuestionType = "fill_blank"
+	// QuestionTypeOrdering is answered by sorting the options; they are
+	// authored in their correct order and shown in a fixed shuffled one.
+	QuestionTypeOrdering QuestionType = "ordering"
+	// QuestionTypeNumeric is answered with a number.
+	QuestionTypeNumeric QuestionType = "numeric"
+)
+
+// Blank is one gap in a fill_blank question. A response is accepted if it
+// equals one of AcceptedAnswers, or fully matches one of Patterns (regular
+// expressions). Leading, trailing and repeated spaces never matter; case only
+// does when CaseSensitive is set.
+type Blank struct {
+	AcceptedAnswers []string `json:"acceptedAnswers"`
+	Patterns        []string `json:"patterns,omitempty"`
+	CaseSensitive   bool     `json:"caseSensitive,omitempty"`
+}
+
+// NumericAnswer is the answer to a numeric question. Responses within
+// Tolerance of Value are accepted.
+type NumericAnswer struct {
+	Value     float64 `json:"value"`
+	Tolerance float64 `json:"tolerance"`
+}
+
+// QuestionAnswerKey is the grading data that does not fit in the options,
+// stored with the question.
+type QuestionAnswerKey struct {
+	Blanks  []Blank        `json:"blanks,omitempty"`
+	Numeric *NumericAnswer `json:"numeric,omitempty"`
+}
+
+// QuestionAnswer is a learner's response. The field used depends on the
+// question type:
+//
+//	multiple_choice, true_false: OptionID
+//	multi_select: OptionIDs, in any order
+//	ordering: OptionIDs, in the order given by the learner
+//	fill_blank: Blanks, one response per blank
+//	numeric: Value
+type QuestionAnswer struct {
+	OptionID  *int64   `json:"optionId,omitempty"`
+	OptionIDs []int64  `json:"optionIds,omitempty"`
+	Blanks    []string `json:"blanks,omitempty"`
+	Value     *float64 `json:"value,omitempty"`
+}
+
+// QuestionResult is the outcome of grading a submitted answer, with the
+// explanation and the feedback of the chosen options.
+type QuestionResult struct {
+	QuestionID  int64            `json:"questionId"`
+	IsCorrect   bool             `json:"isCorrect"`
+	Attempts    int32            `json:"attempts"`
+	Explanation string           `json:"explanation,omitempty"`
+	Feedback    []OptionFeedback `json:"feedback,omitempty"`
+}
+
+type OptionFeedback struct {
+	OptionID int64  `json:"optionId"`
+	Feedback string `json:"feedback"`
+}
+
+// ModuleProgressResult is returned when module progress is saved.
+type ModuleProgressResult struct {
+	Questions []QuestionResult `json:"questions"`
+}
+
+// Validate checks that the question has what its type needs to be graded.
+func (q *QuestionContent) Validate() error {
+	if strings.TrimSpace(q.Question) == "" {
+		return errors.New("question text is required")
+	}
+
+	correct := 0
+	for _, opt := range q.Options {
+		if strings.TrimSpace(opt.Content) == "" {
+			return errors.New("question options must not be empty")
+		}
+		if opt.IsCorrect {
+			correct++
+		}
+	}
+
+	if q.Type != QuestionTypeFillBlank && len(q.Blanks) > 0 {
+		return fmt.Errorf("%s questions cannot have blanks", q.Type)
+	}
+	if q.Type != QuestionTypeNumeric && q.Numeric != nil {
+		return fmt.Errorf("%s questions cannot have a numeric answer", q.Type)
+	}
+
+	switch q.Type {
+	case QuestionTypeMultipleChoice:
+		if len(q.Options) < 2 || correct != 1 {
+			return errors.New("multiple_choice questions need at least two options and exactly one correct")
+		}
+	case QuestionTypeTrueFalse:
+		if len(q.Options) != 2 || correct != 1 {
+			return errors.New("true_false questions need two options and exactly one correct")
+		}
+	case QuestionTypeMultiSelect:
+		if len(q.Options) < 2 || correct < 1 {
+			return errors.New("multi_select questions need at least two options and one or more correct")
+		}
+	case QuestionTypeOrdering:
+		if len(q.Options) < 2 {
+			return errors.New("ordering questions need at least two options")
+		}
+	case QuestionTypeFillBlank:
+		if len(q.Options) > 0 {
+			return errors.New("fill_blank questions cannot have options")
+		}
+		if len(q.Blanks) == 0 {
+			return errors.New("fill_blank questions need at least one blank")
+		}
+		for i, blank := range q.Blanks {
+			if len(blank.AcceptedAnswers) == 0 && len(blank.Patterns) == 0 {
+				return fmt.Errorf("blank %d needs an accepted answer or pattern", i+1)
+			}
+			for _, pattern := range blank.Patterns {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return fmt.Errorf("blank %d has an invalid pattern: %w", i+1, err)
+				}
+			}
+		}
+	case QuestionTypeNumeric:
+		if len(q.Options) > 0 {
+			return errors.New("numeric questions cannot have options")
+		}
+		if q.Numeric == nil {
+			return errors.New("numeric questions need a numeric answer")
+		}
+		if math.IsNaN(q.Numeric.Value) || math.IsInf(q.Numeric.Value, 0) {
+			return errors.New("numeric answer must be a finite number")
+		}
+		if !(q.Numeric.Tolerance >= 0) || math.IsInf(q.Numeric.Tolerance, 0) {
+			return errors.New("numeric tolerance must be a finite number of zero or more")
+		}
+	default:
+		return fmt.Errorf("unknown question type %q", q.Type)
+	}
+
+	return nil
+}
+
+// AnswerKey returns the part of the question stored as its answer key.
+func (q *QuestionContent) AnswerKey() QuestionAnswerKey {
+	return QuestionAnswerKey{Blanks: q.Blanks, Numeric: q.Numeric}
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// RecallGrade is how hard it was to recall a correct answer during a review.
+type RecallGrade string
+
+const (
+	RecallHard RecallGrade = "hard"
+	RecallGood RecallGrade = "good"
+	RecallEasy RecallGrade = "easy"
+)
+
+// ReviewSchedule is where a question stands in a user's review queue.
+type ReviewSchedule struct {
+	QuestionID   int64     `json:"questionId"`
+	EaseFactor   float64   `json:"easeFactor"`
+	IntervalDays int32     `json:"intervalDays"`
+	Repetitions  int32     `json:"repetitions"`
+	Lapses       int32     `json:"lapses"`
+	DueAt        time.Time `json:"dueAt"`
+}
+
+// DueReview is a question due for review, without its answer key.
+type DueReview struct {
+	Schedule ReviewSchedule  `json:"schedule"`
+	Question QuestionContent `json:"question"`
+}
+
+type DueReviews struct {
+	Reviews []DueReview `json:"reviews"`
+	Total   int64       `json:"total"`
+}
+
+// ReviewRequest answers a question in the review queue. Recall is only used
+// when the answer is correct and defaults to good.
+type ReviewRequest struct {
+	Answer QuestionAnswer `json:"answer"`
+	Recall RecallGrade    `json:"recall"`
+}
+
+// ReviewResult is the graded review and when the question is due next.
+type ReviewResult struct {
+	QuestionID  int64            `json:"questionId"`
+	IsCorrect   bool             `json:"isCorrect"`
+	Explanation string           `json:"explanation,omitempty"`
+	Feedback    []OptionFeedback `json:"feedback,omitempty"`
+	Schedule    ReviewSchedule   `json:"schedule"`
+}
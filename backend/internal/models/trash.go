@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TrashItem is a deleted course, unit or module. It can be restored until
+// PurgeAt, when it is deleted for good along with its media. Units and
+// modules deleted along with their course or unit are not listed; they come
+// back when it is restored.
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CourseID  int64     `json:"courseId"`
+	UnitID    int64     `json:"unitId,omitempty"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
@@ -6,6 +6,13 @@ import (
 	"github.com/google/uuid"
 )
 
+// User roles, matching the user_role enum.
+const (
+	RoleAdmin      = "admin"
+	RoleInstructor = "instructor"
+	RoleStudent    = "student"
+)
+
 type User struct {
 	ID                int32             `json:"id,omitempty"`
 	CreatedAt         time.Time         `json:"createdAt"`
@@ -0,0 +1,289 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/mailer"
+	"algolearn/pkg/security"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	emailVerificationExpiry = time.Hour * 24 // 24 hours
+	passwordResetExpiry     = time.Hour * 1  // 1 hour
+)
+
+// AccountService handles the account flows that are completed through a
+// signed, single-use link sent by email.
+type AccountService interface {
+	SendEmailVerification(ctx context.Context, userID int32) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type accountService struct {
+	queries     *gen.Queries
+	db          *sql.DB
+	mailer      mailer.Mailer
+	linkBaseURL string
+	log         *logger.Logger
+}
+
+func NewAccountService(db *sql.DB, m mailer.Mailer, linkBaseURL string) AccountService {
+	return &accountService{
+		queries:     gen.New(db),
+		db:          db,
+		mailer:      m,
+		linkBaseURL: linkBaseURL,
+		log:         logger.Get(),
+	}
+}
+
+// SendEmailVerification mails the user a link to verify their current
+// address. It does nothing for users who are already verified.
+func (s *accountService) SendEmailVerification(ctx context.Context, userID int32) error {
+	log := s.log.WithBaseFields(logger.Service, "SendEmailVerification")
+
+	user, err := s.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get user")
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.IsEmailVerified {
+		return nil
+	}
+
+	token, err := s.issueActionToken(ctx, user.ID, security.PurposeVerifyEmail, user.Email, emailVerificationExpiry)
+	if err != nil {
+		log.WithError(err).Error("failed to issue verification token")
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your AlgoLearn email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening this link:\n\n%s\n\nThe link expires in 24 hours.\n",
+			user.Username, s.link("verify-email", token)),
+	}); err != nil {
+		log.WithError(err).Error("failed to send verification email")
+		return err
+	}
+
+	return nil
+}
+
+// VerifyEmail marks the address the token was mailed to as verified. Tokens
+// for an address the account no longer has are refused.
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	log := s.log.WithBaseFields(logger.Service, "VerifyEmail")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	consumed, err := consumeActionToken(ctx, qtx, token, security.PurposeVerifyEmail)
+	if err != nil {
+		if !errors.Is(err, security.ErrInvalidActionToken) {
+			log.WithError(err).Error("failed to consume verification token")
+		}
+		return err
+	}
+
+	if !consumed.Email.Valid {
+		return security.ErrInvalidActionToken
+	}
+
+	verified, err := qtx.SetUserEmailVerified(ctx, gen.SetUserEmailVerifiedParams{
+		ID:    consumed.UserID,
+		Email: consumed.Email.String,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to mark email as verified")
+		return fmt.Errorf("failed to mark email as verified: %w", err)
+	}
+	if verified == 0 {
+		return security.ErrInvalidActionToken
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset mails a reset link to the account with the given
+// email. Unknown addresses are ignored so callers cannot probe for accounts.
+func (s *accountService) RequestPasswordReset(ctx context.Context, email string) error {
+	log := s.log.WithBaseFields(logger.Service, "RequestPasswordReset")
+
+	user, err := s.queries.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		log.WithError(err).Error("failed to get user by email")
+		return fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	token, err := s.issueActionToken(ctx, user.ID, security.PurposeResetPassword, "", passwordResetExpiry)
+	if err != nil {
+		log.WithError(err).Error("failed to issue password reset token")
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your AlgoLearn password",
+		Body: fmt.Sprintf("Hi %s,\n\nChoose a new password by opening this link:\n\n%s\n\nThe link expires in 1 hour. If you did not ask to reset your password, you can ignore this email.\n",
+			user.Username, s.link("reset-password", token)),
+	}); err != nil {
+		log.WithError(err).Error("failed to send password reset email")
+		return err
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password and signs the user out everywhere.
+func (s *accountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	log := s.log.WithBaseFields(logger.Service, "ResetPassword")
+
+	passwordHash, err := security.HashPassword(newPassword)
+	if err != nil {
+		log.WithError(err).Error("failed to hash password")
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	consumed, err := consumeActionToken(ctx, qtx, token, security.PurposeResetPassword)
+	if err != nil {
+		if !errors.Is(err, security.ErrInvalidActionToken) {
+			log.WithError(err).Error("failed to consume password reset token")
+		}
+		return err
+	}
+	userID := consumed.UserID
+
+	if err := qtx.UpdateUserPassword(ctx, gen.UpdateUserPasswordParams{
+		PasswordHash: passwordHash,
+		ID:           userID,
+	}); err != nil {
+		log.WithError(err).Error("failed to update password")
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if _, err := qtx.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		log.WithError(err).Error("failed to revoke refresh tokens")
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// issueActionToken records a new token for purpose, invalidating any earlier
+// unused ones so only the latest link works. A non-empty email binds the
+// token to that address.
+func (s *accountService) issueActionToken(ctx context.Context, userID int32, purpose, email string, expiry time.Duration) (string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	if err := qtx.InvalidateUserActionTokens(ctx, gen.InvalidateUserActionTokensParams{
+		UserID:  userID,
+		Purpose: purpose,
+	}); err != nil {
+		return "", fmt.Errorf("failed to invalidate previous tokens: %w", err)
+	}
+
+	jti := uuid.New()
+	if err := qtx.CreateUserActionToken(ctx, gen.CreateUserActionTokenParams{
+		ID:        jti,
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(expiry),
+		Email:     sql.NullString{String: email, Valid: email != ""},
+	}); err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	token, err := security.GenerateActionToken(userID, purpose, jti.String(), expiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *accountService) link(path, token string) string {
+	return s.linkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// consumeActionToken validates token and marks it used, returning the user it
+// was issued to and the address it is bound to. It returns
+// security.ErrInvalidActionToken for tokens that are forged, expired, already
+// used or meant for another purpose.
+func consumeActionToken(ctx context.Context, qtx *gen.Queries, token, purpose string) (gen.ConsumeUserActionTokenRow, error) {
+	claims, err := security.ValidateActionToken(token, purpose)
+	if err != nil {
+		return gen.ConsumeUserActionTokenRow{}, err
+	}
+
+	jti, err := uuid.Parse(claims.Id)
+	if err != nil {
+		return gen.ConsumeUserActionTokenRow{}, security.ErrInvalidActionToken
+	}
+
+	consumed, err := qtx.ConsumeUserActionToken(ctx, gen.ConsumeUserActionTokenParams{
+		ID:      jti,
+		Purpose: purpose,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return gen.ConsumeUserActionTokenRow{}, security.ErrInvalidActionToken
+		}
+		return gen.ConsumeUserActionTokenRow{}, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	if consumed.UserID != claims.UserID {
+		return gen.ConsumeUserActionTokenRow{}, security.ErrInvalidActionToken
+	}
+
+	return consumed, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// achievementMetric reports the user's current value for a single achievement
+// criteria. An achievement is earned once the metric reaches its threshold.
+type achievementMetric func(ctx context.Context, qtx *gen.Queries, userID int32) (int64, error)
+
+var achievementMetrics = map[string]achievementMetric{
+	models.CriteriaModulesCompleted: func(ctx context.Context, qtx *gen.Queries, userID int32) (int64, error) {
+		return qtx.CountCompletedModules(ctx, userID)
+	},
+	models.CriteriaCoursesCompleted: func(ctx context.Context, qtx *gen.Queries, userID int32) (int64, error) {
+		return qtx.CountCompletedCourses(ctx, userID)
+	},
+	models.CriteriaStreakDays: func(ctx context.Context, qtx *gen.Queries, userID int32) (int64, error) {
+		streak, err := qtx.GetUserStreakDays(ctx, userID)
+		return int64(streak), err
+	},
+	models.CriteriaPerfectQuiz: func(ctx context.Context, qtx *gen.Queries, userID int32) (int64, error) {
+		return qtx.CountPerfectQuizzes(ctx, userID)
+	},
+}
+
+// awardAchievements grants every achievement whose criteria the user now
+// satisfies, credits its points to the user's cpus and notifies the user. It
+// is meant to run inside the transaction that recorded the triggering
+// progress, so grants are rolled back together with it. The
+// uniq_user_achievement constraint guarantees an achievement is granted at
+// most once, even under concurrency.
+func awardAchievements(ctx context.Context, qtx *gen.Queries, userID int32) ([]gen.Achievement, error) {
+	candidates, err := qtx.GetUnearnedAchievements(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unearned achievements: %w", err)
+	}
+
+	metrics := make(map[string]int64)
+	awarded := []gen.Achievement{}
+
+	for _, achievement := range candidates {
+		criteria := achievement.Criteria.String
+
+		value, ok := metrics[criteria]
+		if !ok {
+			metric, known := achievementMetrics[criteria]
+			if !known {
+				continue
+			}
+
+			value, err = metric(ctx, qtx, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate %s criteria: %w", criteria, err)
+			}
+			metrics[criteria] = value
+		}
+
+		if value < int64(achievement.Threshold) {
+			continue
+		}
+
+		_, err = qtx.GrantUserAchievement(ctx, gen.GrantUserAchievementParams{
+			UserID:        userID,
+			AchievementID: achievement.ID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// already granted by a concurrent request
+				continue
+			}
+			return nil, fmt.Errorf("failed to grant achievement: %w", err)
+		}
+
+		if err := qtx.AddUserCpus(ctx, gen.AddUserCpusParams{
+			Points: achievement.Points,
+			UserID: userID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to add achievement points: %w", err)
+		}
+
+		if _, err := notifyUser(ctx, qtx, userID, fmt.Sprintf("You earned the %q achievement!", achievement.Name)); err != nil {
+			return nil, err
+		}
+
+		awarded = append(awarded, achievement)
+	}
+
+	return awarded, nil
+}
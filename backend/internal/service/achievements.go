@@ -15,6 +15,7 @@ type AchievementsService interface {
 	CreateAchievement(achievement *models.Achievement) error
 	UpdateAchievement(achievement *models.Achievement) error
 	DeleteAchievement(id int32) error
+	GetUserAchievements(userID int32) ([]models.UserAchievement, error)
 }
 
 type achievementsService struct {
@@ -39,6 +40,8 @@ func (h *achievementsService) GetAllAchievements() ([]models.Achievement, error)
 			Name:        a.Name,
 			Description: a.Description,
 			Points:      a.Points,
+			Criteria:    a.Criteria.String,
+			Threshold:   a.Threshold,
 			CreatedAt:   a.CreatedAt,
 			UpdatedAt:   a.UpdatedAt,
 		}
@@ -59,6 +62,8 @@ func (h *achievementsService) GetAchievementByID(id int32) (*models.Achievement,
 		Name:        achievement.Name,
 		Description: achievement.Description,
 		Points:      achievement.Points,
+		Criteria:    achievement.Criteria.String,
+		Threshold:   achievement.Threshold,
 		CreatedAt:   achievement.CreatedAt,
 		UpdatedAt:   achievement.UpdatedAt,
 	}, nil
@@ -70,12 +75,15 @@ func (h *achievementsService) CreateAchievement(achievement *models.Achievement)
 		Name:        achievement.Name,
 		Description: achievement.Description,
 		Points:      achievement.Points,
+		Criteria:    sql.NullString{String: achievement.Criteria, Valid: achievement.Criteria != ""},
+		Threshold:   achievementThreshold(achievement.Threshold),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create achievement: %v", err)
 	}
 
 	achievement.ID = result.ID
+	achievement.Threshold = result.Threshold
 	achievement.CreatedAt = result.CreatedAt
 	achievement.UpdatedAt = result.UpdatedAt
 	return nil
@@ -87,6 +95,8 @@ func (h *achievementsService) UpdateAchievement(achievement *models.Achievement)
 		Name:        achievement.Name,
 		Description: achievement.Description,
 		Points:      achievement.Points,
+		Criteria:    sql.NullString{String: achievement.Criteria, Valid: achievement.Criteria != ""},
+		Threshold:   achievementThreshold(achievement.Threshold),
 		ID:          achievement.ID,
 	})
 	if err != nil {
@@ -103,3 +113,35 @@ func (h *achievementsService) DeleteAchievement(id int32) error {
 	}
 	return nil
 }
+
+func (h *achievementsService) GetUserAchievements(userID int32) ([]models.UserAchievement, error) {
+	ctx := context.Background()
+	achievements, err := h.db.GetUserAchievements(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user achievements: %v", err)
+	}
+
+	result := make([]models.UserAchievement, len(achievements))
+	for i, a := range achievements {
+		result[i] = models.UserAchievement{
+			ID:            a.ID,
+			UserID:        int64(a.UserID),
+			AchievementID: a.AchievementID,
+			AchievedAt:    a.AchievedAt,
+			Name:          a.Name,
+			Description:   a.Description,
+			Points:        a.Points,
+		}
+	}
+
+	return result, nil
+}
+
+// achievementThreshold defaults an unset threshold to 1 so that criteria like
+// "first module completed" only need the criteria to be specified.
+func achievementThreshold(threshold int32) int32 {
+	if threshold <= 0 {
+		return 1
+	}
+	return threshold
+}
@@ -0,0 +1,962 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidArchive is returned when a course archive cannot be read or its
+// manifest is inconsistent.
+var ErrInvalidArchive = errors.New("invalid course archive")
+
+// sectionTypes are the types of section a module can hold.
+var sectionTypes = []models.SectionType{
+	models.SectionTypeMarkdown,
+	models.SectionTypeCode,
+	models.SectionTypeQuestion,
+	models.SectionTypeVideo,
+	models.SectionTypeImage,
+	models.SectionTypeLottie,
+	models.SectionTypeExercise,
+}
+
+// maxArchiveManifestBytes bounds the manifest read from an archive.
+const maxArchiveManifestBytes = 32 << 20
+
+// Media objects are stored as <resource>/<folder>/<object>.<ext>, where folder
+// is the folder object key of the course, unit or module they belong to.
+// Section media live in their module's folder.
+const (
+	courseMediaResource = "courses"
+	unitMediaResource   = "units"
+	moduleMediaResource = "modules"
+)
+
+type CourseArchiveService interface {
+	ExportCourse(ctx context.Context, courseID int32, w io.Writer) error
+	ImportCourse(ctx context.Context, r io.ReaderAt, size int64, authorID int32, dryRun bool) (*models.CourseImportResult, error)
+}
+
+type courseArchiveService struct {
+	queries *gen.Queries
+	db      *sql.DB
+	storage StorageService
+	log     *logger.Logger
+}
+
+func NewCourseArchiveService(db *sql.DB, storage StorageService) CourseArchiveService {
+	return &courseArchiveService{
+		queries: gen.New(db),
+		db:      db,
+		storage: storage,
+		log:     logger.Get(),
+	}
+}
+
+// ExportCourse writes the course as a zip archive to w. Nothing is written if
+// the course cannot be read. Media objects missing from storage are left out
+// of the archive.
+func (s *courseArchiveService) ExportCourse(ctx context.Context, courseID int32, w io.Writer) error {
+	log := s.log.WithBaseFields(logger.Service, "ExportCourse")
+
+	archive, mediaKeys, err := buildArchive(ctx, s.queries, courseID)
+	if err != nil {
+		if !errors.Is(err, httperr.ErrNotFound) {
+			log.WithError(err).Error(err.Error())
+		}
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	archive.Media = []models.ArchiveMedia{}
+	for _, key := range mediaKeys {
+		media, err := s.exportMedia(ctx, zw, key)
+		if errors.Is(err, ErrObjectNotFound) {
+			log.WithField("key", key).Warn("media object missing from storage")
+			continue
+		}
+		if err != nil {
+			log.WithError(err).Error(err.Error())
+			return err
+		}
+		archive.Media = append(archive.Media, media)
+	}
+
+	manifest, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive manifest: %w", err)
+	}
+
+	f, err := zw.Create(models.CourseArchiveManifest)
+	if err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+	if _, err := f.Write(manifest); err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return nil
+}
+
+func (s *courseArchiveService) exportMedia(ctx context.Context, zw *zip.Writer, key string) (models.ArchiveMedia, error) {
+	obj, err := s.storage.GetObject(ctx, key)
+	if err != nil {
+		return models.ArchiveMedia{}, err
+	}
+	defer obj.Close()
+
+	// Media is already compressed, so it is stored as is.
+	f, err := zw.CreateHeader(&zip.FileHeader{
+		Name:   models.CourseArchiveMediaDir + key,
+		Method: zip.Store,
+	})
+	if err != nil {
+		return models.ArchiveMedia{}, fmt.Errorf("failed to add media to archive: %w", err)
+	}
+
+	n, err := io.Copy(f, obj)
+	if err != nil {
+		return models.ArchiveMedia{}, fmt.Errorf("failed to copy media %s: %w", key, err)
+	}
+
+	return models.ArchiveMedia{Key: key, ContentType: obj.ContentType, Size: n}, nil
+}
+
+// buildArchive reads the course into an archive manifest and returns the
+// storage keys of the media it refers to.
+func buildArchive(ctx context.Context, q *gen.Queries, courseID int32) (*models.CourseArchive, []string, error) {
+	course, err := q.GetCourseByID(ctx, courseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, httperr.ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get course: %w", err)
+	}
+
+	tags, err := q.GetCourseTags(ctx, courseID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get course tags: %w", err)
+	}
+
+	archive := &models.CourseArchive{
+		Version:    models.CourseArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+		Course: models.ArchiveCourse{
+			ID:              int64(course.ID),
+			FolderObjectKey: course.FolderObjectKey,
+			ImgKey:          course.ImgKey,
+			MediaExt:        course.MediaExt.String,
+			Name:            course.Name,
+			Description:     course.Description,
+			Requirements:    course.Requirements.String,
+			WhatYouLearn:    course.WhatYouLearn.String,
+			BackgroundColor: course.BackgroundColor.String,
+			Duration:        course.Duration.Int32,
+			DifficultyLevel: models.DifficultyLevel(course.DifficultyLevel.DifficultyLevel),
+			Rating:          course.Rating.Float64,
+			Tags:            make([]string, len(tags)),
+			Units:           []models.ArchiveUnit{},
+		},
+	}
+	for i, tag := range tags {
+		archive.Course.Tags[i] = tag.Name
+	}
+
+	units, err := q.GetCourseUnits(ctx, courseID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get course units: %w", err)
+	}
+
+	for _, unit := range units {
+		archiveUnit := models.ArchiveUnit{
+			ID:              int64(unit.ID),
+			FolderObjectKey: unit.FolderObjectKey,
+			ImgKey:          unit.ImgKey,
+			MediaExt:        unit.MediaExt.String,
+			UnitNumber:      unit.UnitNumber,
+			Name:            unit.Name,
+			Description:     unit.Description,
+		}
+		if err := archiveUnitModules(ctx, q, &archiveUnit); err != nil {
+			return nil, nil, err
+		}
+		archive.Course.Units = append(archive.Course.Units, archiveUnit)
+	}
+
+	var mediaKeys []string
+	courseMedia(archive.Course, func(resource string, folder, object uuid.NullUUID, ext string) {
+		if key := mediaKey(resource, folder, object, ext); key != "" {
+			mediaKeys = append(mediaKeys, key)
+		}
+	})
+
+	return archive, mediaKeys, nil
+}
+
+// archiveUnitModules reads the modules of unit and their sections into it.
+func archiveUnitModules(ctx context.Context, q *gen.Queries, unit *models.ArchiveUnit) error {
+	modules, err := q.GetUnitModules(ctx, int32(unit.ID))
+	if err != nil {
+		return fmt.Errorf("failed to get unit modules: %w", err)
+	}
+
+	unit.Modules = []models.ArchiveModule{}
+	for _, module := range modules {
+		archiveModule := models.ArchiveModule{
+			ID:              int64(module.ID),
+			FolderObjectKey: module.FolderObjectKey,
+			ImgKey:          module.ImgKey,
+			MediaExt:        module.MediaExt.String,
+			ModuleNumber:    module.ModuleNumber,
+			Name:            module.Name,
+			Description:     module.Description,
+		}
+		if err := archiveModuleSections(ctx, q, &archiveModule); err != nil {
+			return err
+		}
+		unit.Modules = append(unit.Modules, archiveModule)
+	}
+
+	return nil
+}
+
+// archiveModuleSections reads the sections of module into it.
+func archiveModuleSections(ctx context.Context, q *gen.Queries, module *models.ArchiveModule) error {
+	sections, err := q.GetModuleSections(ctx, int32(module.ID))
+	if err != nil {
+		return fmt.Errorf("failed to get module sections: %w", err)
+	}
+
+	module.Sections = []models.ArchiveSection{}
+	for _, section := range sections {
+		content, err := sectionContent(ctx, q, section, false)
+		if err != nil {
+			return err
+		}
+		module.Sections = append(module.Sections, models.ArchiveSection{
+			ID:       int64(section.ID),
+			Type:     models.SectionType(section.Type),
+			Position: int16(section.Position),
+			Content:  content,
+		})
+	}
+
+	return nil
+}
+
+// mediaFunc is called with the parts of the storage key of a media object.
+type mediaFunc func(resource string, folder, object uuid.NullUUID, ext string)
+
+// courseMedia calls add for the media of a course and everything in it.
+func courseMedia(course models.ArchiveCourse, add mediaFunc) {
+	add(courseMediaResource, course.FolderObjectKey, course.ImgKey, course.MediaExt)
+	for _, unit := range course.Units {
+		unitMedia(unit, add)
+	}
+}
+
+// unitMedia calls add for the media of a unit and everything in it.
+func unitMedia(unit models.ArchiveUnit, add mediaFunc) {
+	add(unitMediaResource, unit.FolderObjectKey, unit.ImgKey, unit.MediaExt)
+	for _, module := range unit.Modules {
+		moduleMedia(module, add)
+	}
+}
+
+// moduleMedia calls add for the media of a module and its sections, which
+// live in the module's folder.
+func moduleMedia(module models.ArchiveModule, add mediaFunc) {
+	add(moduleMediaResource, module.FolderObjectKey, module.ImgKey, module.MediaExt)
+	for _, section := range module.Sections {
+		var media sectionMedia
+		if err := json.Unmarshal(section.Content, &media); err == nil {
+			add(moduleMediaResource, module.FolderObjectKey, media.ObjectKey, media.MediaExt)
+		}
+	}
+}
+
+// sectionContent returns the content of a section in the shape it is created
+// with, including answer keys and hidden test cases. withIDs adds the IDs of
+// a question and its options, which authors edit them by; archives and
+// snapshots leave them out so that copies of a section compare equal.
+func sectionContent(ctx context.Context, q *gen.Queries, section gen.Section, withIDs bool) (json.RawMessage, error) {
+	var content any
+
+	switch section.Type {
+	case gen.SectionTypeMarkdown:
+		markdown, err := q.GetMarkdownSection(ctx, section.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get markdown section: %w", err)
+		}
+		content = models.MarkdownContent{
+			Markdown:  markdown.Markdown,
+			ObjectKey: markdown.ObjectKey,
+			MediaExt:  markdown.MediaExt.String,
+		}
+
+	case gen.SectionTypeVideo:
+		video, err := q.GetVideoSection(ctx, section.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get video section: %w", err)
+		}
+		content = models.VideoContent{
+			URL:       video.Url,
+			ObjectKey: video.ObjectKey,
+			MediaExt:  video.MediaExt.String,
+		}
+
+	case gen.SectionTypeCode:
+		code, err := q.GetCodeSection(ctx, section.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get code section: %w", err)
+		}
+		content = models.CodeContent{
+			Code:      code.Code,
+			Language:  code.Language.String,
+			ObjectKey: code.ObjectKey,
+			MediaExt:  code.MediaExt.String,
+		}
+
+	case gen.SectionTypeImage:
+		image, err := q.GetImageSection(ctx, section.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image section: %w", err)
+		}
+		content = models.ImageContent{
+			URL:       image.Url.String,
+			Width:     int(image.Width.Int32),
+			Height:    int(image.Height.Int32),
+			AltText:   image.AltText.String,
+			Headline:  image.Headline.String,
+			Caption:   image.Caption.String,
+			ObjectKey: image.ObjectKey,
+			MediaExt:  image.MediaExt.String,
+		}
+
+	case gen.SectionTypeLottie:
+		lottie, err := q.GetLottieSection(ctx, section.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get lottie section: %w", err)
+		}
+		content = models.LottieContent{
+			Caption:     lottie.Caption.String,
+			Description: lottie.Description.String,
+			Width:       int(lottie.Width.Int32),
+			Height:      int(lottie.Height.Int32),
+			AltText:     lottie.AltText.String,
+			FallbackURL: lottie.FallbackUrl.String,
+			Autoplay:    lottie.Autoplay,
+			Loop:        lottie.Loop,
+			Speed:       float32(lottie.Speed),
+			ObjectKey:   lottie.ObjectKey,
+			MediaExt:    lottie.MediaExt.String,
+		}
+
+	case gen.SectionTypeQuestion:
+		question, err := questionContent(ctx, q, section.ID, withIDs)
+		if err != nil {
+			return nil, err
+		}
+		content = question
+
+	case gen.SectionTypeExercise:
+		exercise, err := q.GetExerciseSection(ctx, section.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exercise section: %w", err)
+		}
+		tests, err := q.GetExerciseTestCases(ctx, section.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exercise test cases: %w", err)
+		}
+		testCases := make([]models.TestCase, len(tests))
+		for i, test := range tests {
+			testCases[i] = models.TestCase{
+				Input:          test.Input,
+				ExpectedOutput: test.ExpectedOutput,
+				Hidden:         test.Hidden,
+			}
+		}
+		content = models.ExerciseContent{
+			Prompt:        exercise.Prompt,
+			Language:      exercise.Language,
+			StarterCode:   exercise.StarterCode,
+			TimeLimitMs:   exercise.TimeLimitMs,
+			MemoryLimitKb: exercise.MemoryLimitKb,
+			TestCases:     testCases,
+			ObjectKey:     exercise.ObjectKey,
+			MediaExt:      exercise.MediaExt.String,
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown section type: %s", section.Type)
+	}
+
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal section content: %w", err)
+	}
+	return raw, nil
+}
+
+func questionContent(ctx context.Context, q *gen.Queries, sectionID int32, withIDs bool) (*models.QuestionContent, error) {
+	question, err := q.GetSectionQuestion(ctx, sectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get question section: %w", err)
+	}
+
+	var key models.QuestionAnswerKey
+	if err := json.Unmarshal(question.AnswerSchema, &key); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal question answer key: %w", err)
+	}
+
+	// Options come back in their authored order, which is the answer to an
+	// ordering question.
+	options, err := q.GetQuestionOptions(ctx, question.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get question options: %w", err)
+	}
+
+	tags, err := q.GetQuestionTagNames(ctx, question.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get question tags: %w", err)
+	}
+
+	content := &models.QuestionContent{
+		Question:    question.Question,
+		Type:        models.QuestionType(question.Type),
+		Options:     make([]models.Option, len(options)),
+		Blanks:      key.Blanks,
+		Numeric:     key.Numeric,
+		Explanation: question.Explanation,
+		Tags:        tags,
+		ObjectKey:   question.ObjectKey,
+		MediaExt:    question.MediaExt.String,
+	}
+	for i, opt := range options {
+		content.Options[i] = models.Option{
+			Content:   opt.Content,
+			IsCorrect: opt.IsCorrect,
+			Feedback:  opt.Feedback,
+		}
+		if withIDs {
+			content.Options[i].ID = int64(opt.ID)
+		}
+	}
+	if withIDs {
+		content.ID = int64(question.ID)
+	}
+
+	return content, nil
+}
+
+// ImportCourse creates a draft course authored by authorID from an archive,
+// with new IDs and new object keys for all of its media. The media is
+// uploaded before the course is written, so the transaction is not held open
+// while it streams, and deleted again if the import fails. With dryRun the
+// archive is imported inside a transaction that is rolled back and no media
+// is uploaded, so the result reports what would be created and any problems.
+func (s *courseArchiveService) ImportCourse(ctx context.Context, r io.ReaderAt, size int64, authorID int32, dryRun bool) (*models.CourseImportResult, error) {
+	log := s.log.WithBaseFields(logger.Service, "ImportCourse")
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	archive, err := readManifest(files)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateArchive(archive, files); err != nil {
+		return nil, err
+	}
+
+	inArchive := make(map[string]bool, len(archive.Media))
+	for _, media := range archive.Media {
+		inArchive[media.Key] = true
+	}
+	plan := newImportPlan(func(key string) bool { return inArchive[key] })
+	courseMedia(archive.Course, plan.addMedia)
+	result := &models.CourseImportResult{
+		DryRun:   dryRun,
+		Warnings: plan.warnings,
+	}
+
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := plan.discard(ctx, s.storage); err != nil {
+			log.WithError(err).Error("failed to delete media of failed import")
+		}
+	}()
+
+	if !dryRun {
+		for _, media := range archive.Media {
+			newKey, ok := plan.media[media.Key]
+			if !ok {
+				continue
+			}
+			if err := s.importMedia(ctx, files[models.CourseArchiveMediaDir+media.Key], newKey, media); err != nil {
+				log.WithError(err).Error(err.Error())
+				return nil, err
+			}
+			plan.stored = append(plan.stored, newKey)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	courseID, idMap, err := insertCourseTree(ctx, qtx, archive.Course, plan)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidArchive) && !isInvalidContent(err) {
+			log.WithError(err).Error(err.Error())
+		}
+		return nil, err
+	}
+	idMap.Media = plan.media
+
+	if err := qtx.InsertCourseAuthor(ctx, gen.InsertCourseAuthorParams{
+		CourseID: courseID,
+		UserID:   authorID,
+	}); err != nil {
+		log.WithError(err).Error("failed to insert course author")
+		return nil, fmt.Errorf("failed to insert course author: %w", err)
+	}
+
+	result.Units = len(archive.Course.Units)
+	for _, unit := range archive.Course.Units {
+		result.Modules += len(unit.Modules)
+		for _, module := range unit.Modules {
+			result.Sections += len(module.Sections)
+		}
+	}
+	result.Media = len(plan.media)
+	if dryRun {
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	result.CourseID = int64(courseID)
+	result.IDMap = idMap
+	return result, nil
+}
+
+func (s *courseArchiveService) importMedia(ctx context.Context, f *zip.File, key string, media models.ArchiveMedia) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: failed to open media %s: %v", ErrInvalidArchive, media.Key, err)
+	}
+	defer rc.Close()
+
+	if err := s.storage.PutObject(ctx, key, rc, media.Size, media.ContentType); err != nil {
+		return fmt.Errorf("failed to upload media %s: %w", media.Key, err)
+	}
+	return nil
+}
+
+// objectKeys gives the object keys a course tree is inserted with.
+type objectKeys interface {
+	// key returns the key to insert in place of old, in the form the insert
+	// queries take.
+	key(old uuid.NullUUID) uuid.UUID
+	// sectionContent rewrites the object key in a section's content.
+	sectionContent(content json.RawMessage) (json.RawMessage, error)
+}
+
+func newArchiveIDMap() *models.ArchiveIDMap {
+	return &models.ArchiveIDMap{
+		Units:    map[int64]int64{},
+		Modules:  map[int64]int64{},
+		Sections: map[int64]int64{},
+	}
+}
+
+// insertCourseTree creates a draft course with the tags, units, modules and
+// sections of course. It returns the new course ID and a map from the IDs in
+// course to the IDs they were inserted as. Authors are left to the caller.
+func insertCourseTree(ctx context.Context, qtx *gen.Queries, course models.ArchiveCourse, keys objectKeys) (int32, *models.ArchiveIDMap, error) {
+	idMap := newArchiveIDMap()
+
+	difficulty := course.DifficultyLevel
+	if difficulty == "" {
+		difficulty = models.DifficultyLevel(gen.DifficultyLevelBeginner)
+	}
+
+	courseID, err := qtx.CreateCourse(ctx, gen.CreateCourseParams{
+		Name:            course.Name,
+		Description:     course.Description,
+		Requirements:    course.Requirements,
+		WhatYouLearn:    course.WhatYouLearn,
+		BackgroundColor: course.BackgroundColor,
+		Duration:        course.Duration,
+		DifficultyLevel: gen.DifficultyLevel(difficulty),
+		Rating:          course.Rating,
+		FolderObjectKey: keys.key(course.FolderObjectKey),
+		ImgKey:          keys.key(course.ImgKey),
+		MediaExt:        course.MediaExt,
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create course: %w", err)
+	}
+
+	for _, name := range course.Tags {
+		tagID, err := qtx.CreateCourseTag(ctx, name)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create course tag: %w", err)
+		}
+		if err := qtx.InsertCourseTag(ctx, gen.InsertCourseTagParams{CourseID: courseID, TagID: tagID}); err != nil {
+			return 0, nil, fmt.Errorf("failed to insert course tag: %w", err)
+		}
+	}
+
+	for _, unit := range course.Units {
+		if _, err := insertUnitTree(ctx, qtx, courseID, unit, keys, idMap); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return courseID, idMap, nil
+}
+
+// insertUnitTree creates a unit of courseID with the modules and sections of
+// unit, and adds their new IDs to idMap.
+func insertUnitTree(ctx context.Context, qtx *gen.Queries, courseID int32, unit models.ArchiveUnit, keys objectKeys, idMap *models.ArchiveIDMap) (int32, error) {
+	unitID, err := qtx.CreateUnit(ctx, gen.CreateUnitParams{
+		CourseID:        courseID,
+		UnitNumber:      unit.UnitNumber,
+		Name:            unit.Name,
+		Description:     unit.Description,
+		FolderObjectKey: keys.key(unit.FolderObjectKey),
+		ImgKey:          keys.key(unit.ImgKey),
+		MediaExt:        unit.MediaExt,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create unit %d: %w", unit.UnitNumber, err)
+	}
+	idMap.Units[unit.ID] = int64(unitID)
+
+	for _, module := range unit.Modules {
+		if _, err := insertModuleTree(ctx, qtx, unitID, module, keys, idMap); err != nil {
+			return 0, fmt.Errorf("unit %d: %w", unit.UnitNumber, err)
+		}
+	}
+
+	return unitID, nil
+}
+
+// insertModuleTree creates a module of unitID with the sections of module,
+// and adds their new IDs to idMap.
+func insertModuleTree(ctx context.Context, qtx *gen.Queries, unitID int32, module models.ArchiveModule, keys objectKeys, idMap *models.ArchiveIDMap) (int32, error) {
+	created, err := qtx.InsertModule(ctx, gen.InsertModuleParams{
+		FolderObjectKey: keys.key(module.FolderObjectKey),
+		ImgKey:          keys.key(module.ImgKey),
+		MediaExt:        module.MediaExt,
+		ModuleNumber:    module.ModuleNumber,
+		UnitID:          unitID,
+		Name:            module.Name,
+		Description:     module.Description,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert module %d: %w", module.ModuleNumber, err)
+	}
+	idMap.Modules[module.ID] = int64(created.ID)
+
+	sections := make([]models.Section, len(module.Sections))
+	for i, section := range module.Sections {
+		content, err := keys.sectionContent(section.Content)
+		if err != nil {
+			return 0, err
+		}
+		sections[i] = models.Section{
+			Type:     section.Type,
+			Position: section.Position,
+			Content:  content,
+		}
+	}
+
+	sectionIDs, err := insertSections(ctx, qtx, created.ID, sections)
+	if err != nil {
+		return 0, fmt.Errorf("module %d: %w", module.ModuleNumber, err)
+	}
+	for i, id := range sectionIDs {
+		idMap.Sections[module.Sections[i].ID] = int64(id)
+	}
+
+	return created.ID, nil
+}
+
+func readManifest(files map[string]*zip.File) (*models.CourseArchive, error) {
+	f, ok := files[models.CourseArchiveManifest]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, models.CourseArchiveManifest)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer rc.Close()
+
+	var archive models.CourseArchive
+	if err := json.NewDecoder(io.LimitReader(rc, maxArchiveManifestBytes)).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("%w: failed to read manifest: %v", ErrInvalidArchive, err)
+	}
+	return &archive, nil
+}
+
+// validateArchive checks the structure of the manifest and that every media
+// object it lists is in the archive. Section content is validated when it is
+// inserted.
+func validateArchive(archive *models.CourseArchive, files map[string]*zip.File) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, fmt.Sprintf(format, args...))
+	}
+
+	if archive.Version < 1 || archive.Version > models.CourseArchiveVersion {
+		return invalid("unsupported version %d", archive.Version)
+	}
+
+	course := archive.Course
+	if strings.TrimSpace(course.Name) == "" {
+		return invalid("course name is required")
+	}
+
+	unitNumbers := map[int32]bool{}
+	for _, unit := range course.Units {
+		if unit.UnitNumber < 1 {
+			return invalid("unit number %d is not positive", unit.UnitNumber)
+		}
+		if unitNumbers[unit.UnitNumber] {
+			return invalid("unit number %d is used twice", unit.UnitNumber)
+		}
+		unitNumbers[unit.UnitNumber] = true
+
+		moduleNumbers := map[int32]bool{}
+		for _, module := range unit.Modules {
+			if module.ModuleNumber < 1 {
+				return invalid("module number %d in unit %d is not positive", module.ModuleNumber, unit.UnitNumber)
+			}
+			if moduleNumbers[module.ModuleNumber] {
+				return invalid("module number %d is used twice in unit %d", module.ModuleNumber, unit.UnitNumber)
+			}
+			moduleNumbers[module.ModuleNumber] = true
+
+			for _, section := range module.Sections {
+				if !slices.Contains(sectionTypes, section.Type) {
+					return invalid("unknown section type %q in module %d of unit %d", section.Type, module.ModuleNumber, unit.UnitNumber)
+				}
+			}
+		}
+	}
+
+	for _, media := range archive.Media {
+		if media.Key == "" || path.Clean(media.Key) != media.Key || strings.HasPrefix(media.Key, "/") || strings.HasPrefix(media.Key, "..") {
+			return invalid("bad media key %q", media.Key)
+		}
+		f, ok := files[models.CourseArchiveMediaDir+media.Key]
+		if !ok {
+			return invalid("media %s is missing", media.Key)
+		}
+		if int64(f.UncompressedSize64) != media.Size {
+			return invalid("media %s is %d bytes, expected %d", media.Key, f.UncompressedSize64, media.Size)
+		}
+	}
+
+	return nil
+}
+
+// sectionMedia is the media reference every kind of section content has.
+type sectionMedia struct {
+	ObjectKey uuid.NullUUID `json:"objectKey"`
+	MediaExt  string        `json:"mediaExt"`
+}
+
+// mediaKey returns the storage key of a media object, or "" if the object
+// has no key.
+func mediaKey(resource string, folder, object uuid.NullUUID, ext string) string {
+	if !folder.Valid || folder.UUID == uuid.Nil || !object.Valid || object.UUID == uuid.Nil || ext == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s.%s", resource, folder.UUID, object.UUID, ext)
+}
+
+// importPlan gives every object key in a course tree a new one, so an
+// imported, cloned or published course never shares media with the course it
+// was copied from or with an earlier copy of it.
+type importPlan struct {
+	keys map[uuid.UUID]uuid.UUID
+	// media maps the media keys of the archive to their new storage keys.
+	media map[string]string
+	// stored lists the new keys written to storage so far.
+	stored    []string
+	warnings  []string
+	available func(key string) bool
+}
+
+// newImportPlan returns a plan that gives new keys to the media objects for
+// which available returns true, and a warning for the others.
+func newImportPlan(available func(key string) bool) *importPlan {
+	return &importPlan{
+		keys:      map[uuid.UUID]uuid.UUID{},
+		media:     map[string]string{},
+		available: available,
+	}
+}
+
+// addMedia plans the copy of a media object to its new key. It is a
+// mediaFunc.
+func (p *importPlan) addMedia(resource string, folder, object uuid.NullUUID, ext string) {
+	oldKey := mediaKey(resource, folder, object, ext)
+	if oldKey == "" {
+		return
+	}
+	if !p.available(oldKey) {
+		p.warnings = append(p.warnings, fmt.Sprintf("media %s is not in the archive", oldKey))
+		return
+	}
+	p.media[oldKey] = mediaKey(resource, p.nullKey(folder), p.nullKey(object), ext)
+}
+
+// copyMedia copies the planned media objects that are not stored yet to their
+// new keys in storage and returns the keys of the ones that were missing.
+func (p *importPlan) copyMedia(ctx context.Context, storage StorageService) ([]string, error) {
+	stored := make(map[string]bool, len(p.stored))
+	for _, key := range p.stored {
+		stored[key] = true
+	}
+
+	var missing []string
+	for oldKey, newKey := range p.media {
+		if stored[newKey] {
+			continue
+		}
+		err := storage.CopyObject(ctx, oldKey, newKey)
+		if errors.Is(err, ErrObjectNotFound) {
+			missing = append(missing, oldKey)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy media %s: %w", oldKey, err)
+		}
+		p.stored = append(p.stored, newKey)
+	}
+	return missing, nil
+}
+
+// replan plans the media of course, a newer read of the course whose media
+// was already copied. Media the course gained is copied by the next
+// copyMedia, and the new keys of stored media it no longer uses are returned
+// so they can be deleted once the course is saved. The object keys the plan
+// gave out stay the same.
+func (p *importPlan) replan(course models.ArchiveCourse) []string {
+	p.media = map[string]string{}
+	courseMedia(course, p.addMedia)
+
+	used := make(map[string]bool, len(p.media))
+	for _, newKey := range p.media {
+		used[newKey] = true
+	}
+
+	var unused []string
+	for _, newKey := range p.stored {
+		if !used[newKey] {
+			unused = append(unused, newKey)
+		}
+	}
+	return unused
+}
+
+// discard deletes the media stored for the plan, for when the content it
+// belongs to is not saved after all. It does not give up when ctx is
+// cancelled, since that may be why the save failed.
+func (p *importPlan) discard(ctx context.Context, storage StorageService) error {
+	if len(p.stored) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+	return storage.DeleteObjects(ctx, p.stored)
+}
+
+// key returns the new key for an archive key, in the form the insert queries
+// take.
+func (p *importPlan) key(old uuid.NullUUID) uuid.UUID {
+	return p.nullKey(old).UUID
+}
+
+func (p *importPlan) nullKey(old uuid.NullUUID) uuid.NullUUID {
+	if !old.Valid || old.UUID == uuid.Nil {
+		return uuid.NullUUID{}
+	}
+	if _, ok := p.keys[old.UUID]; !ok {
+		p.keys[old.UUID] = uuid.New()
+	}
+	return uuid.NullUUID{UUID: p.keys[old.UUID], Valid: true}
+}
+
+// sectionContent rewrites the object key in a section's content.
+func (p *importPlan) sectionContent(content json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, fmt.Errorf("%w: bad section content: %v", ErrInvalidArchive, err)
+	}
+
+	var media sectionMedia
+	if err := json.Unmarshal(content, &media); err != nil {
+		return nil, fmt.Errorf("%w: bad section content: %v", ErrInvalidArchive, err)
+	}
+	if !media.ObjectKey.Valid {
+		return content, nil
+	}
+
+	objectKey, err := json.Marshal(p.nullKey(media.ObjectKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal object key: %w", err)
+	}
+	fields["objectKey"] = objectKey
+
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal section content: %w", err)
+	}
+	return rewritten, nil
+}
@@ -0,0 +1,767 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ErrAlreadyLatestVersion is returned when migrating progress that is already
+// on the latest version of a course.
+var ErrAlreadyLatestVersion = errors.New("already on the latest version of the course")
+
+type CourseVersionService interface {
+	PublishCourse(ctx context.Context, courseID int64, publishedBy int32) (*models.CourseVersion, error)
+	GetCourseVersions(ctx context.Context, userID int32, courseID int64) (*models.CourseVersions, error)
+	DiffCourseVersions(ctx context.Context, courseID int64, from, to int32) (*models.CourseVersionDiff, error)
+	MigrateCourseProgress(ctx context.Context, userID int32, courseID int64) (*models.CourseMigration, error)
+	GetLearnerCourseID(ctx context.Context, userID int32, courseID int64) (int64, error)
+}
+
+type courseVersionService struct {
+	queries *gen.Queries
+	db      *sql.DB
+	storage StorageService
+	log     *logger.Logger
+}
+
+// NewCourseVersionService returns a CourseVersionService that copies the
+// media of published versions within storage.
+func NewCourseVersionService(db *sql.DB, storage StorageService) CourseVersionService {
+	return &courseVersionService{
+		queries: gen.New(db),
+		db:      db,
+		storage: storage,
+		log:     logger.Get(),
+	}
+}
+
+// PublishCourse copies the course's units, modules and sections into a new
+// snapshot course and records it as the next version. The course stays a
+// draft, and the snapshot and the course get media of their own so that
+// editing the course never changes a published version. Learners enrolled in
+// the course itself, as they are before its first publish, are moved onto
+// the new version with their progress.
+func (s *courseVersionService) PublishCourse(ctx context.Context, courseID int64, publishedBy int32) (*models.CourseVersion, error) {
+	log := s.log.WithBaseFields(logger.Service, "PublishCourse")
+
+	archive, _, err := buildArchive(ctx, s.queries, int32(courseID))
+	if err != nil {
+		if !errors.Is(err, httperr.ErrNotFound) {
+			log.WithError(err).Error(err.Error())
+		}
+		return nil, err
+	}
+
+	plan := newImportPlan(func(string) bool { return true })
+	courseMedia(archive.Course, plan.addMedia)
+
+	// Media copied for a publish that is rolled back is deleted again.
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := plan.discard(ctx, s.storage); err != nil {
+			log.WithError(err).Error("failed to delete media of failed publish")
+		}
+	}()
+
+	// The media is copied before the transaction so that the course is not
+	// locked while storage is slow.
+	if _, err := plan.copyMedia(ctx, s.storage); err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	// Publishes of the same course are serialized so the course cannot change
+	// while it is copied and version numbers are handed out in order.
+	if err := qtx.LockCourse(ctx, int32(courseID)); err != nil {
+		log.WithError(err).Error("failed to lock course")
+		return nil, fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	// The course is read again now that it is locked, and only the media it
+	// gained since is copied here.
+	archive, _, err = buildArchive(ctx, qtx, int32(courseID))
+	if err != nil {
+		if !errors.Is(err, httperr.ErrNotFound) {
+			log.WithError(err).Error(err.Error())
+		}
+		return nil, err
+	}
+
+	unused := plan.replan(archive.Course)
+	missing, err := plan.copyMedia(ctx, s.storage)
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	for _, key := range missing {
+		log.WithField("key", key).Warn("media object missing from storage")
+	}
+
+	learners, err := qtx.CourseHasLearners(ctx, int32(courseID))
+	if err != nil {
+		log.WithError(err).Error("failed to check course learners")
+		return nil, fmt.Errorf("failed to check course learners: %w", err)
+	}
+
+	var snapshotID int32
+	var idMap *models.ArchiveIDMap
+	if learners {
+		snapshotID, idMap, err = moveWorkingCopy(ctx, qtx, int32(courseID), archive.Course, plan)
+	} else {
+		snapshotID, idMap, err = insertCourseTree(ctx, qtx, archive.Course, plan)
+	}
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	for sourceID, id := range idMap.Units {
+		if err := qtx.SetUnitSource(ctx, gen.SetUnitSourceParams{SourceUnitID: int32(sourceID), UnitID: int32(id)}); err != nil {
+			log.WithError(err).Error("failed to set unit source")
+			return nil, fmt.Errorf("failed to set unit source: %w", err)
+		}
+	}
+	for sourceID, id := range idMap.Modules {
+		if err := qtx.SetModuleSource(ctx, gen.SetModuleSourceParams{SourceModuleID: int32(sourceID), ModuleID: int32(id)}); err != nil {
+			log.WithError(err).Error("failed to set module source")
+			return nil, fmt.Errorf("failed to set module source: %w", err)
+		}
+	}
+	for sourceID, id := range idMap.Sections {
+		if err := qtx.SetSectionSource(ctx, gen.SetSectionSourceParams{SourceSectionID: int32(sourceID), SectionID: int32(id)}); err != nil {
+			log.WithError(err).Error("failed to set section source")
+			return nil, fmt.Errorf("failed to set section source: %w", err)
+		}
+	}
+
+	if err := qtx.PublishCourse(ctx, snapshotID); err != nil {
+		log.WithError(err).Error("failed to publish course")
+		return nil, fmt.Errorf("failed to publish course: %w", err)
+	}
+
+	version, err := qtx.CreateCourseVersion(ctx, gen.CreateCourseVersionParams{
+		CourseID:         int32(courseID),
+		SnapshotCourseID: snapshotID,
+		PublishedBy:      sql.NullInt32{Int32: publishedBy, Valid: publishedBy != 0},
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to create course version")
+		return nil, fmt.Errorf("failed to create course version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	if len(unused) > 0 {
+		if err := s.storage.DeleteObjects(ctx, unused); err != nil {
+			log.WithError(err).Warn("failed to delete media the course stopped using while it was published")
+		}
+	}
+
+	return courseVersionFromGen(version), nil
+}
+
+// moveWorkingCopy publishes the working copy courseID to a new snapshot by
+// moving its units, modules and sections into the snapshot, together with the
+// learners enrolled in it and all of their progress, and putting copies of
+// them back in the working copy. The copies and the snapshot course get the
+// object keys of keys, so the moved rows keep their media to themselves. It
+// returns the snapshot ID and a map from the IDs of the working copy to the
+// IDs in the snapshot, the same as insertCourseTree returns for a plain copy.
+func moveWorkingCopy(ctx context.Context, qtx *gen.Queries, courseID int32, course models.ArchiveCourse, keys objectKeys) (int32, *models.ArchiveIDMap, error) {
+	empty := course
+	empty.Units = nil
+	snapshotID, _, err := insertCourseTree(ctx, qtx, empty, keys)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := qtx.MoveCourseUnits(ctx, gen.MoveCourseUnitsParams{
+		ToCourseID:   snapshotID,
+		FromCourseID: courseID,
+	}); err != nil {
+		return 0, nil, fmt.Errorf("failed to move course units: %w", err)
+	}
+
+	copies := newArchiveIDMap()
+	for _, unit := range course.Units {
+		unitID, err := insertUnitTree(ctx, qtx, courseID, unit, keys, copies)
+		if err != nil {
+			return 0, nil, err
+		}
+		// Deleted modules stay in the working copy, where they can be
+		// restored from the trash.
+		if err := qtx.MoveDeletedModules(ctx, gen.MoveDeletedModulesParams{
+			ToUnitID:   unitID,
+			FromUnitID: int32(unit.ID),
+		}); err != nil {
+			return 0, nil, fmt.Errorf("failed to move deleted modules: %w", err)
+		}
+	}
+
+	if err := qtx.MoveCourseLearners(ctx, gen.MoveCourseLearnersParams{
+		ToCourseID:   snapshotID,
+		FromCourseID: courseID,
+	}); err != nil {
+		return 0, nil, fmt.Errorf("failed to move course learners: %w", err)
+	}
+
+	idMap := newArchiveIDMap()
+	for id, copyID := range copies.Units {
+		idMap.Units[copyID] = id
+	}
+	for id, copyID := range copies.Modules {
+		idMap.Modules[copyID] = id
+	}
+	for id, copyID := range copies.Sections {
+		idMap.Sections[copyID] = id
+	}
+
+	return snapshotID, idMap, nil
+}
+
+func (s *courseVersionService) GetCourseVersions(ctx context.Context, userID int32, courseID int64) (*models.CourseVersions, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetCourseVersions")
+
+	versions, err := s.queries.GetCourseVersions(ctx, int32(courseID))
+	if err != nil {
+		log.WithError(err).Error("failed to get course versions")
+		return nil, fmt.Errorf("failed to get course versions: %w", err)
+	}
+
+	result := &models.CourseVersions{Versions: make([]models.CourseVersion, len(versions))}
+	for i, version := range versions {
+		result.Versions[i] = *courseVersionFromGen(version)
+	}
+
+	current, err := s.queries.GetUserCourseVersion(ctx, gen.GetUserCourseVersionParams{
+		CourseID: int32(courseID),
+		UserID:   userID,
+	})
+	switch {
+	case err == nil:
+		result.Current = current.Version
+	case !errors.Is(err, sql.ErrNoRows):
+		log.WithError(err).Error("failed to get user course version")
+		return nil, fmt.Errorf("failed to get user course version: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetLearnerCourseID returns the course a learner reads and records progress
+// against: the snapshot of the version they are enrolled in, or else the
+// snapshot of the latest version. Courses that were never published are
+// their own working copy, and learners enrolled in it stay there until the
+// next publish moves them onto the new version.
+func (s *courseVersionService) GetLearnerCourseID(ctx context.Context, userID int32, courseID int64) (int64, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetLearnerCourseID")
+
+	current, err := s.queries.GetUserCourseVersion(ctx, gen.GetUserCourseVersionParams{
+		CourseID: int32(courseID),
+		UserID:   userID,
+	})
+	if err == nil {
+		return int64(current.SnapshotCourseID), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).Error("failed to get user course version")
+		return 0, fmt.Errorf("failed to get user course version: %w", err)
+	}
+
+	enrolled, err := s.queries.IsEnrolledInCourse(ctx, gen.IsEnrolledInCourseParams{
+		UserID:   userID,
+		CourseID: int32(courseID),
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to check enrollment")
+		return 0, fmt.Errorf("failed to check enrollment: %w", err)
+	}
+	if enrolled {
+		return courseID, nil
+	}
+
+	latest, err := s.queries.GetLatestCourseVersion(ctx, int32(courseID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return courseID, nil
+		}
+		log.WithError(err).Error("failed to get latest course version")
+		return 0, fmt.Errorf("failed to get latest course version: %w", err)
+	}
+
+	return int64(latest.SnapshotCourseID), nil
+}
+
+// DiffCourseVersions compares two published versions of a course. Units,
+// modules and sections are matched across versions by the working copy rows
+// they were copied from.
+func (s *courseVersionService) DiffCourseVersions(ctx context.Context, courseID int64, from, to int32) (*models.CourseVersionDiff, error) {
+	log := s.log.WithBaseFields(logger.Service, "DiffCourseVersions")
+
+	var trees [2]*versionTree
+	for i, number := range []int32{from, to} {
+		version, err := s.queries.GetCourseVersion(ctx, gen.GetCourseVersionParams{
+			CourseID: int32(courseID),
+			Version:  number,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, httperr.ErrNotFound
+			}
+			log.WithError(err).Error("failed to get course version")
+			return nil, fmt.Errorf("failed to get course version: %w", err)
+		}
+
+		trees[i], err = readVersionTree(ctx, s.queries, version.SnapshotCourseID)
+		if err != nil {
+			log.WithError(err).Error(err.Error())
+			return nil, err
+		}
+	}
+
+	return &models.CourseVersionDiff{
+		CourseID: courseID,
+		From:     from,
+		To:       to,
+		Units:    diffVersionItems(trees[0].units, trees[1].units),
+		Modules:  diffVersionItems(trees[0].modules, trees[1].modules),
+		Sections: diffVersionItems(trees[0].sections, trees[1].sections),
+	}, nil
+}
+
+// MigrateCourseProgress moves the user from the version of the course they
+// are enrolled in to the latest one. Progress is carried over for sections
+// whose content is unchanged, along with the answers, reviews and exercise
+// submissions recorded for them, and the progress recorded against the old
+// version is removed.
+func (s *courseVersionService) MigrateCourseProgress(ctx context.Context, userID int32, courseID int64) (*models.CourseMigration, error) {
+	log := s.log.WithBaseFields(logger.Service, "MigrateCourseProgress")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	latest, err := qtx.GetLatestCourseVersion(ctx, int32(courseID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get latest course version")
+		return nil, fmt.Errorf("failed to get latest course version: %w", err)
+	}
+
+	result := &models.CourseMigration{
+		CourseID:         courseID,
+		ToVersion:        latest.Version,
+		SnapshotCourseID: int64(latest.SnapshotCourseID),
+	}
+
+	fromCourseID := int32(courseID)
+	current, err := qtx.GetUserCourseVersion(ctx, gen.GetUserCourseVersionParams{
+		CourseID: int32(courseID),
+		UserID:   userID,
+	})
+	switch {
+	case err == nil:
+		fromCourseID = current.SnapshotCourseID
+		result.FromVersion = current.Version
+	case errors.Is(err, sql.ErrNoRows):
+		enrolled, err := qtx.IsEnrolledInCourse(ctx, gen.IsEnrolledInCourseParams{
+			UserID:   userID,
+			CourseID: int32(courseID),
+		})
+		if err != nil {
+			log.WithError(err).Error("failed to check enrollment")
+			return nil, fmt.Errorf("failed to check enrollment: %w", err)
+		}
+		if !enrolled {
+			return nil, httperr.ErrNotFound
+		}
+	default:
+		log.WithError(err).Error("failed to get user course version")
+		return nil, fmt.Errorf("failed to get user course version: %w", err)
+	}
+
+	if fromCourseID == latest.SnapshotCourseID {
+		return nil, ErrAlreadyLatestVersion
+	}
+
+	fromTree, err := readVersionTree(ctx, qtx, fromCourseID)
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	toTree, err := readVersionTree(ctx, qtx, latest.SnapshotCourseID)
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	if err := qtx.StartCourseUserCourses(ctx, gen.StartCourseUserCoursesParams{
+		UserID:   userID,
+		CourseID: latest.SnapshotCourseID,
+	}); err != nil {
+		log.WithError(err).Error("failed to start course")
+		return nil, fmt.Errorf("failed to start course: %w", err)
+	}
+
+	first, err := qtx.GetFirstUnitAndModuleInCourse(ctx, latest.SnapshotCourseID)
+	switch {
+	case err == nil:
+		if err := qtx.InitializeModuleProgress(ctx, gen.InitializeModuleProgressParams{
+			UserID:   userID,
+			ModuleID: first.ModuleID,
+		}); err != nil {
+			log.WithError(err).Error("failed to initialize module progress")
+			return nil, fmt.Errorf("failed to initialize module progress: %w", err)
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		log.WithError(err).Error("failed to get first module")
+		return nil, fmt.Errorf("failed to get first module: %w", err)
+	}
+
+	for _, key := range sortedKeys(toTree.sections) {
+		to := toTree.sections[key]
+		from, ok := fromTree.sections[key]
+		if !ok {
+			continue
+		}
+		if !to.carriesProgress(from) {
+			result.ResetSections++
+			continue
+		}
+
+		// Module progress must exist before section progress is added for the
+		// progress triggers to roll it up.
+		if err := qtx.InitializeModuleProgress(ctx, gen.InitializeModuleProgressParams{
+			UserID:   userID,
+			ModuleID: to.moduleID,
+		}); err != nil {
+			log.WithError(err).Error("failed to initialize module progress")
+			return nil, fmt.Errorf("failed to initialize module progress: %w", err)
+		}
+		switch models.SectionType(to.name) {
+		case models.SectionTypeQuestion:
+			if err := carryQuestionAnswer(ctx, qtx, userID, from, to); err != nil {
+				log.WithError(err).Error(err.Error())
+				return nil, err
+			}
+		case models.SectionTypeExercise:
+			if err := qtx.CopyExerciseSubmissions(ctx, gen.CopyExerciseSubmissionsParams{
+				ToSectionID:   int32(to.id),
+				UserID:        userID,
+				FromSectionID: int32(from.id),
+			}); err != nil {
+				log.WithError(err).Error("failed to copy exercise submissions")
+				return nil, fmt.Errorf("failed to copy exercise submissions: %w", err)
+			}
+		}
+		if err := qtx.CopySectionProgress(ctx, gen.CopySectionProgressParams{
+			ToSectionID:   int32(to.id),
+			UserID:        userID,
+			FromSectionID: int32(from.id),
+		}); err != nil {
+			log.WithError(err).Error("failed to copy section progress")
+			return nil, fmt.Errorf("failed to copy section progress: %w", err)
+		}
+		result.CarriedSections++
+	}
+
+	if err := qtx.DeleteSectionProgress(ctx, gen.DeleteSectionProgressParams{
+		UserID:   userID,
+		CourseID: fromCourseID,
+	}); err != nil {
+		log.WithError(err).Error("failed to delete section progress")
+		return nil, fmt.Errorf("failed to delete section progress: %w", err)
+	}
+
+	if err := qtx.DeleteModuleProgress(ctx, gen.DeleteModuleProgressParams{
+		UserID:   userID,
+		CourseID: fromCourseID,
+	}); err != nil {
+		log.WithError(err).Error("failed to delete module progress")
+		return nil, fmt.Errorf("failed to delete module progress: %w", err)
+	}
+
+	if err := qtx.DeleteUserCourse(ctx, gen.DeleteUserCourseParams{
+		UserID:   userID,
+		CourseID: fromCourseID,
+	}); err != nil {
+		log.WithError(err).Error("failed to delete user course")
+		return nil, fmt.Errorf("failed to delete user course: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// carryQuestionAnswer copies the user's answer to the question of section
+// from, and their review of it, to the question of section to. The two have
+// the same content, so their options are matched by position.
+func carryQuestionAnswer(ctx context.Context, qtx *gen.Queries, userID int32, from, to versionItem) error {
+	answer, err := qtx.GetSectionQuestionAnswer(ctx, gen.GetSectionQuestionAnswerParams{
+		UserID:    userID,
+		SectionID: int32(from.id),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get question answer: %w", err)
+	}
+
+	question, err := qtx.GetSectionQuestion(ctx, int32(to.id))
+	if err != nil {
+		return fmt.Errorf("failed to get question section: %w", err)
+	}
+
+	fromOptions, err := qtx.GetQuestionOptions(ctx, answer.QuestionID)
+	if err != nil {
+		return fmt.Errorf("failed to get question options: %w", err)
+	}
+	toOptions, err := qtx.GetQuestionOptions(ctx, question.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get question options: %w", err)
+	}
+	optionIDs := make(map[int64]int64, len(fromOptions))
+	for i, opt := range fromOptions {
+		if i < len(toOptions) {
+			optionIDs[int64(opt.ID)] = int64(toOptions[i].ID)
+		}
+	}
+
+	var value models.QuestionAnswer
+	if err := json.Unmarshal(answer.Answer, &value); err != nil {
+		return fmt.Errorf("failed to unmarshal question answer: %w", err)
+	}
+	if value.OptionID != nil {
+		id := optionIDs[*value.OptionID]
+		value.OptionID = &id
+	}
+	for i, id := range value.OptionIDs {
+		value.OptionIDs[i] = optionIDs[id]
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal question answer: %w", err)
+	}
+
+	params := gen.CopyQuestionAnswerParams{
+		Answer:     raw,
+		IsCorrect:  answer.IsCorrect,
+		AnsweredAt: answer.AnsweredAt,
+		Attempts:   answer.Attempts,
+		UserID:     userID,
+		SectionID:  int32(to.id),
+	}
+	if answer.OptionID.Valid {
+		params.OptionID = sql.NullInt32{Int32: int32(optionIDs[int64(answer.OptionID.Int32)]), Valid: true}
+	}
+	if err := qtx.CopyQuestionAnswer(ctx, params); err != nil {
+		return fmt.Errorf("failed to copy question answer: %w", err)
+	}
+
+	if err := qtx.MoveQuestionReview(ctx, gen.MoveQuestionReviewParams{
+		ToQuestionID:   question.ID,
+		UserID:         userID,
+		FromQuestionID: answer.QuestionID,
+	}); err != nil {
+		return fmt.Errorf("failed to move question review: %w", err)
+	}
+
+	return nil
+}
+
+func courseVersionFromGen(version gen.CourseVersion) *models.CourseVersion {
+	result := &models.CourseVersion{
+		ID:               int64(version.ID),
+		CourseID:         int64(version.CourseID),
+		Version:          version.Version,
+		SnapshotCourseID: int64(version.SnapshotCourseID),
+		PublishedAt:      version.PublishedAt,
+	}
+	if version.PublishedBy.Valid {
+		publishedBy := int64(version.PublishedBy.Int32)
+		result.PublishedBy = &publishedBy
+	}
+	return result
+}
+
+// versionTree holds the units, modules and sections of a course keyed by the
+// working copy rows they were copied from. Rows of a course that was never
+// copied are keyed by their own IDs.
+type versionTree struct {
+	units    map[int32]versionItem
+	modules  map[int32]versionItem
+	sections map[int32]versionItem
+}
+
+// versionItem is a unit, module or section with the fields it is compared by,
+// in the same order for every item of its kind.
+type versionItem struct {
+	id       int64
+	moduleID int32
+	name     string
+	fields   []versionField
+}
+
+type versionField struct {
+	name  string
+	value string
+}
+
+// carriesProgress reports whether progress through from still holds for the
+// section it became.
+func (item versionItem) carriesProgress(from versionItem) bool {
+	for i, field := range item.fields {
+		if (field.name == "type" || field.name == "content") && field.value != from.fields[i].value {
+			return false
+		}
+	}
+	return true
+}
+
+func readVersionTree(ctx context.Context, q *gen.Queries, courseID int32) (*versionTree, error) {
+	archive, _, err := buildArchive(ctx, q, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.GetCourseSources(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course sources: %w", err)
+	}
+	sources := map[string]map[int64]int32{"unit": {}, "module": {}, "section": {}}
+	for _, row := range rows {
+		source := row.ID
+		if row.SourceID.Valid {
+			source = row.SourceID.Int32
+		}
+		sources[row.Kind][int64(row.ID)] = source
+	}
+
+	tree := &versionTree{
+		units:    map[int32]versionItem{},
+		modules:  map[int32]versionItem{},
+		sections: map[int32]versionItem{},
+	}
+	for _, unit := range archive.Course.Units {
+		unitKey := sources["unit"][unit.ID]
+		tree.units[unitKey] = versionItem{
+			id:   unit.ID,
+			name: unit.Name,
+			fields: []versionField{
+				{"name", unit.Name},
+				{"description", unit.Description},
+				{"number", strconv.Itoa(int(unit.UnitNumber))},
+				{"media", unit.ImgKey.UUID.String() + unit.MediaExt},
+			},
+		}
+
+		for _, module := range unit.Modules {
+			moduleKey := sources["module"][module.ID]
+			tree.modules[moduleKey] = versionItem{
+				id:   module.ID,
+				name: module.Name,
+				fields: []versionField{
+					{"name", module.Name},
+					{"description", module.Description},
+					{"number", strconv.Itoa(int(module.ModuleNumber))},
+					{"unit", strconv.Itoa(int(unitKey))},
+					{"media", module.ImgKey.UUID.String() + module.MediaExt},
+				},
+			}
+
+			for _, section := range module.Sections {
+				tree.sections[sources["section"][section.ID]] = versionItem{
+					id:       section.ID,
+					moduleID: int32(module.ID),
+					name:     string(section.Type),
+					fields: []versionField{
+						{"type", string(section.Type)},
+						{"position", strconv.Itoa(int(section.Position))},
+						{"module", strconv.Itoa(int(moduleKey))},
+						{"content", string(section.Content)},
+					},
+				}
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+func diffVersionItems(from, to map[int32]versionItem) []models.ItemChange {
+	changes := []models.ItemChange{}
+
+	for _, key := range sortedKeys(from) {
+		old := from[key]
+		item, ok := to[key]
+		if !ok {
+			changes = append(changes, models.ItemChange{Change: models.ChangeRemoved, FromID: old.id, Name: old.name})
+			continue
+		}
+
+		var fields []string
+		for i, field := range item.fields {
+			if field.value != old.fields[i].value {
+				fields = append(fields, field.name)
+			}
+		}
+		if len(fields) > 0 {
+			changes = append(changes, models.ItemChange{Change: models.ChangeChanged, FromID: old.id, ToID: item.id, Name: item.name, Fields: fields})
+		}
+	}
+
+	for _, key := range sortedKeys(to) {
+		if _, ok := from[key]; !ok {
+			item := to[key]
+			changes = append(changes, models.ItemChange{Change: models.ChangeAdded, ToID: item.id, Name: item.name})
+		}
+	}
+
+	return changes
+}
+
+func sortedKeys(items map[int32]versionItem) []int32 {
+	keys := make([]int32, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
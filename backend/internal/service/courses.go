@@ -2,6 +2,7 @@ package service
 
 import (
 	gen "algolearn/internal/database/generated"
+	httperr "algolearn/internal/errors"
 	"algolearn/internal/models"
 	"algolearn/pkg/logger"
 	"context"
@@ -9,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -22,9 +24,9 @@ type CourseService interface {
 	ListAllCoursesWithOptionalProgress(ctx context.Context, userID int64, query models.CourseQuery) (int64, []models.Course, error)
 	SearchCourses(ctx context.Context, query string, page int, pageSize int, useFullText bool) (int64, []models.Course, error)
 	StartCourse(ctx context.Context, userID int64, courseID int32) (int32, int32, error)
-	CreateCourse(ctx context.Context, course models.Course) (*models.Course, error)
+	CreateCourse(ctx context.Context, course models.Course, authorID int32) (*models.Course, error)
+	CloneCourse(ctx context.Context, courseID int64, name string, authorID int32) (*models.Course, error)
 	UpdateCourse(ctx context.Context, course models.Course) error
-	PublishCourse(ctx context.Context, courseID int64) error
 	DeleteCourse(ctx context.Context, id int64) error
 	ResetCourseProgress(ctx context.Context, userID int64, courseID int64) error
 	GetCourseTags(ctx context.Context, courseID int32) ([]models.Tag, error)
@@ -32,18 +34,26 @@ type CourseService interface {
 	CreateCourseTag(ctx context.Context, name string) (int64, error)
 	InsertCourseTag(ctx context.Context, courseID int32, tagID int32) error
 	RemoveCourseTag(ctx context.Context, courseID int32, tagID int32) error
+	IsCourseAuthor(ctx context.Context, userID int32, courseID, unitID, moduleID int64) (bool, error)
+	IsCourseSnapshot(ctx context.Context, courseID int64) (bool, error)
+	IsUnitSnapshot(ctx context.Context, unitID int64) (bool, error)
+	CanManageMediaFolder(ctx context.Context, userID int32, resource string, folder uuid.UUID) (bool, error)
 }
 
 type courseService struct {
 	queries *gen.Queries
 	db      *sql.DB
+	storage StorageService
 	log     *logger.Logger
 }
 
-func NewCourseService(db *sql.DB) CourseService {
+// NewCourseService returns a CourseService that copies the media of cloned
+// courses within storage.
+func NewCourseService(db *sql.DB, storage StorageService) CourseService {
 	return &courseService{
 		queries: gen.New(db),
 		db:      db,
+		storage: storage,
 		log:     logger.Get(),
 	}
 }
@@ -547,7 +557,7 @@ func (r *courseService) GetCourseWithProgress(ctx context.Context, userID int64,
 
 			course.Units[i].Modules[j].Sections = make([]models.SectionInterface, len(sections))
 			for k, section := range sections {
-				sectionWithContent, err := r.getSectionContent(ctx, section)
+				sectionWithContent, err := r.getSectionContent(ctx, userID, section)
 				if err != nil {
 					log.WithError(err).Error("failed to get section content")
 					return nil, fmt.Errorf("failed to get section content: %w", err)
@@ -560,7 +570,7 @@ func (r *courseService) GetCourseWithProgress(ctx context.Context, userID int64,
 	return course, nil
 }
 
-func (r *courseService) getSectionContent(ctx context.Context, section gen.GetModuleSectionsWithProgressRow) (models.SectionInterface, error) {
+func (r *courseService) getSectionContent(ctx context.Context, userID int64, section gen.GetModuleSectionsWithProgressRow) (models.SectionInterface, error) {
 	log := r.log.WithBaseFields(logger.Service, "getSectionContent")
 
 	var result models.SectionInterface
@@ -641,12 +651,13 @@ func (r *courseService) getSectionContent(ctx context.Context, section gen.GetMo
 			Type:     models.SectionType(section.Type),
 			Position: int16(section.Position),
 			Content: models.QuestionContent{
-				ID:        int64(questionContent.ID),
-				Question:  questionContent.Question,
-				Type:      questionContent.Type,
-				Options:   options,
-				ObjectKey: questionContent.ObjectKey,
-				MediaExt:  questionContent.MediaExt.String,
+				ID:         int64(questionContent.ID),
+				Question:   questionContent.Question,
+				Type:       models.QuestionType(questionContent.Type),
+				Options:    options,
+				BlankCount: int(questionContent.BlankCount),
+				ObjectKey:  questionContent.ObjectKey,
+				MediaExt:   questionContent.MediaExt.String,
 			},
 			SectionProgress: &models.SectionProgress{
 				SectionID:   int64(section.ID),
@@ -686,6 +697,67 @@ func (r *courseService) getSectionContent(ctx context.Context, section gen.GetMo
 				CompletedAt: section.CompletedAt.Time,
 			},
 		}
+	case "exercise":
+		exercise, err := r.queries.GetExerciseSection(ctx, section.ID)
+		if err != nil {
+			log.WithError(err).Error("failed to get exercise section content")
+			return nil, fmt.Errorf("failed to get exercise section content: %w", err)
+		}
+
+		tests, err := r.queries.GetExerciseTestCases(ctx, section.ID)
+		if err != nil {
+			log.WithError(err).Error("failed to get exercise test cases")
+			return nil, fmt.Errorf("failed to get exercise test cases: %w", err)
+		}
+
+		passed, err := r.queries.HasPassedExercise(ctx, gen.HasPassedExerciseParams{
+			UserID:    int32(userID),
+			SectionID: section.ID,
+		})
+		if err != nil {
+			log.WithError(err).Error("failed to check exercise submissions")
+			return nil, fmt.Errorf("failed to check exercise submissions: %w", err)
+		}
+
+		// Hidden test cases are only ever run, never shown.
+		visible := []models.TestCase{}
+		for _, test := range tests {
+			if !test.Hidden {
+				visible = append(visible, models.TestCase{
+					Input:          test.Input,
+					ExpectedOutput: test.ExpectedOutput,
+				})
+			}
+		}
+
+		result = &models.ExerciseSection{
+			BaseModel: models.BaseModel{
+				ID:        int64(section.ID),
+				CreatedAt: section.CreatedAt,
+				UpdatedAt: section.UpdatedAt,
+			},
+			Type:     models.SectionType(section.Type),
+			Position: int16(section.Position),
+			Content: models.ExerciseContent{
+				Prompt:        exercise.Prompt,
+				Language:      exercise.Language,
+				StarterCode:   exercise.StarterCode,
+				TimeLimitMs:   exercise.TimeLimitMs,
+				MemoryLimitKb: exercise.MemoryLimitKb,
+				TestCases:     visible,
+				Passed:        passed,
+				ObjectKey:     exercise.ObjectKey,
+				MediaExt:      exercise.MediaExt.String,
+			},
+			SectionProgress: &models.SectionProgress{
+				SectionID:   int64(section.ID),
+				SeenAt:      section.SeenAt.Time,
+				HasSeen:     section.HasSeen.Bool,
+				StartedAt:   section.StartedAt.Time,
+				CompletedAt: section.CompletedAt.Time,
+			},
+		}
+
 	case "image":
 		imageContent, err := r.queries.GetImageSection(ctx, section.ID)
 		if err != nil {
@@ -759,7 +831,8 @@ func (r *courseService) StartCourse(ctx context.Context, userID int64, courseID
 	return firstUnitAndModule.UnitID, firstUnitAndModule.ModuleID, nil
 }
 
-func (r *courseService) CreateCourse(ctx context.Context, course models.Course) (*models.Course, error) {
+// CreateCourse creates the course and records authorID as its author.
+func (r *courseService) CreateCourse(ctx context.Context, course models.Course, authorID int32) (*models.Course, error) {
 	log := r.log.WithBaseFields(logger.Service, "CreateCourse")
 
 	var params gen.CreateCourseParams
@@ -785,15 +858,120 @@ func (r *courseService) CreateCourse(ctx context.Context, course models.Course)
 		params.MediaExt = course.MediaExt
 	}
 
-	courseID, err := r.queries.CreateCourse(ctx, params)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	courseID, err := qtx.CreateCourse(ctx, params)
 	if err != nil {
 		log.WithError(err).Error("failed to create course")
 		return nil, fmt.Errorf("failed to create course: %w", err)
 	}
 
+	if err := qtx.InsertCourseAuthor(ctx, gen.InsertCourseAuthorParams{
+		CourseID: courseID,
+		UserID:   authorID,
+	}); err != nil {
+		log.WithError(err).Error("failed to insert course author")
+		return nil, fmt.Errorf("failed to insert course author: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return r.GetCourseByID(ctx, courseID)
 }
 
+// CloneCourse copies a course with its tags, units, modules, sections and
+// media into a new draft course authored by authorID. The copy is named name,
+// or "<course name> (copy)" if name is empty. It gets new object keys and its
+// media is copied in storage, so the two courses can be edited independently.
+func (r *courseService) CloneCourse(ctx context.Context, courseID int64, name string, authorID int32) (*models.Course, error) {
+	log := r.log.WithBaseFields(logger.Service, "CloneCourse")
+
+	archive, _, err := buildArchive(ctx, r.queries, int32(courseID))
+	if err != nil {
+		if !errors.Is(err, httperr.ErrNotFound) {
+			log.WithError(err).Error(err.Error())
+		}
+		return nil, err
+	}
+
+	course := archive.Course
+	course.Name = cloneName(name, course.Name)
+
+	plan := newImportPlan(func(string) bool { return true })
+	courseMedia(course, plan.addMedia)
+
+	// Media copied for a clone that is rolled back is deleted again.
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := plan.discard(ctx, r.storage); err != nil {
+			log.WithError(err).Error("failed to delete media of failed clone")
+		}
+	}()
+
+	// The media is copied before the transaction so that no locks are held
+	// while storage is slow.
+	missing, err := plan.copyMedia(ctx, r.storage)
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	for _, key := range missing {
+		log.WithField("key", key).Warn("media object missing from storage")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	cloneID, _, err := insertCourseTree(ctx, qtx, course, plan)
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	if err := qtx.InsertCourseAuthor(ctx, gen.InsertCourseAuthorParams{
+		CourseID: cloneID,
+		UserID:   authorID,
+	}); err != nil {
+		log.WithError(err).Error("failed to insert course author")
+		return nil, fmt.Errorf("failed to insert course author: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	return r.GetCourseByID(ctx, cloneID)
+}
+
+// cloneName returns the name of a copy of something named original.
+func cloneName(name, original string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return original + " (copy)"
+}
+
 func (r *courseService) GetCourseByID(ctx context.Context, courseID int32) (*models.Course, error) {
 	course, err := r.queries.GetCourseByID(ctx, int32(courseID))
 	if err != nil {
@@ -854,17 +1032,8 @@ func (r *courseService) UpdateCourse(ctx context.Context, course models.Course)
 	return nil
 }
 
-func (r *courseService) PublishCourse(ctx context.Context, courseID int64) error {
-	log := r.log.WithBaseFields(logger.Service, "PublishCourse")
-
-	if err := r.queries.PublishCourse(ctx, int32(courseID)); err != nil {
-		log.WithError(err).Error("failed to publish course")
-		return fmt.Errorf("failed to publish course: %w", err)
-	}
-
-	return nil
-}
-
+// DeleteCourse moves the course to the trash together with its published
+// versions. Learner progress is kept so restoring the course brings it back.
 func (r *courseService) DeleteCourse(ctx context.Context, id int64) error {
 	log := r.log.WithBaseFields(logger.Service, "DeleteCourse")
 
@@ -872,39 +1041,13 @@ func (r *courseService) DeleteCourse(ctx context.Context, id int64) error {
 		return fmt.Errorf("invalid course ID: %d", id)
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	deleted, err := r.queries.SoftDeleteCourse(ctx, int32(id))
 	if err != nil {
-		log.WithError(err).Error("failed to begin transaction")
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	qtx := r.queries.WithTx(tx)
-
-	if err = qtx.DeleteSectionProgress(ctx, gen.DeleteSectionProgressParams{
-		UserID:   0, // Delete for all users
-		CourseID: int32(id),
-	}); err != nil {
-		log.WithError(err).Error("failed to delete section progress")
-		return fmt.Errorf("failed to delete section progress: %w", err)
-	}
-
-	if err = qtx.DeleteModuleProgress(ctx, gen.DeleteModuleProgressParams{
-		UserID:   0, // Delete for all users
-		CourseID: int32(id),
-	}); err != nil {
-		log.WithError(err).Error("failed to delete module progress")
-		return fmt.Errorf("failed to delete module progress: %w", err)
-	}
-
-	if err = qtx.DeleteCourse(ctx, int32(id)); err != nil {
 		log.WithError(err).Error("failed to delete course")
 		return fmt.Errorf("failed to delete course: %w", err)
 	}
-
-	if err = tx.Commit(); err != nil {
-		log.WithError(err).Error("failed to commit transaction")
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if deleted == 0 {
+		return httperr.ErrNotFound
 	}
 
 	return nil
@@ -1147,3 +1290,71 @@ func (r *courseService) RemoveCourseTag(ctx context.Context, courseID int32, tag
 	}
 	return nil
 }
+
+// IsCourseAuthor reports whether the user authors the course and, when unitID
+// or moduleID are non-zero, whether that content belongs to the course.
+func (r *courseService) IsCourseAuthor(ctx context.Context, userID int32, courseID, unitID, moduleID int64) (bool, error) {
+	log := r.log.WithBaseFields(logger.Service, "IsCourseAuthor")
+
+	isAuthor, err := r.queries.IsCourseAuthor(ctx, gen.IsCourseAuthorParams{
+		CourseID: int32(courseID),
+		UserID:   userID,
+		UnitID:   sql.NullInt32{Int32: int32(unitID), Valid: unitID != 0},
+		ModuleID: sql.NullInt32{Int32: int32(moduleID), Valid: moduleID != 0},
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to check course authorship")
+		return false, fmt.Errorf("failed to check course authorship: %w", err)
+	}
+
+	return isAuthor, nil
+}
+
+// IsCourseSnapshot reports whether the course is the snapshot of a published
+// version of another course.
+func (r *courseService) IsCourseSnapshot(ctx context.Context, courseID int64) (bool, error) {
+	log := r.log.WithBaseFields(logger.Service, "IsCourseSnapshot")
+
+	isSnapshot, err := r.queries.IsCourseSnapshot(ctx, int32(courseID))
+	if err != nil {
+		log.WithError(err).Error("failed to check course snapshot")
+		return false, fmt.Errorf("failed to check course snapshot: %w", err)
+	}
+
+	return isSnapshot, nil
+}
+
+// IsUnitSnapshot reports whether the unit belongs to the snapshot of a
+// published version of a course.
+func (r *courseService) IsUnitSnapshot(ctx context.Context, unitID int64) (bool, error) {
+	log := r.log.WithBaseFields(logger.Service, "IsUnitSnapshot")
+
+	isSnapshot, err := r.queries.IsUnitSnapshot(ctx, int32(unitID))
+	if err != nil {
+		log.WithError(err).Error("failed to check unit snapshot")
+		return false, fmt.Errorf("failed to check unit snapshot: %w", err)
+	}
+
+	return isSnapshot, nil
+}
+
+// CanManageMediaFolder reports whether the user may upload to or delete from
+// the media folder of the resource. Users manage their own profile folder and
+// authors the folders of their courses, except those a published version
+// uses. Folders nothing uses yet are free, since content is created after its
+// media has been uploaded.
+func (r *courseService) CanManageMediaFolder(ctx context.Context, userID int32, resource string, folder uuid.UUID) (bool, error) {
+	log := r.log.WithBaseFields(logger.Service, "CanManageMediaFolder")
+
+	access, err := r.queries.GetMediaFolderAccess(ctx, gen.GetMediaFolderAccessParams{
+		Resource:        resource,
+		FolderObjectKey: folder,
+		UserID:          userID,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to check media folder access")
+		return false, fmt.Errorf("failed to check media folder access: %w", err)
+	}
+
+	return access.Allowed || !access.InUse, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/internal/models"
+	"algolearn/pkg/sandbox"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidExercise is returned when exercise content fails validation.
+	ErrInvalidExercise = errors.New("invalid exercise")
+	// ErrCodeRunnerUnavailable is returned when code is submitted but no code
+	// runner is configured.
+	ErrCodeRunnerUnavailable = errors.New("code runner is unavailable")
+)
+
+const (
+	exerciseStatusPassed      = "passed"
+	exerciseStatusWrongAnswer = "wrong_answer"
+)
+
+// runExercise runs code against each test case in turn and grades the
+// output. Hidden test cases only report whether they passed.
+func runExercise(ctx context.Context, runner sandbox.Runner, exercise gen.ExerciseSection, tests []gen.ExerciseTestCase, code string) (*models.ExerciseResult, error) {
+	result := &models.ExerciseResult{
+		TotalCount: len(tests),
+		Tests:      make([]models.ExerciseTestResult, 0, len(tests)),
+	}
+
+	for _, test := range tests {
+		run, err := runner.Run(ctx, sandbox.Request{
+			Language:      exercise.Language,
+			Code:          code,
+			Stdin:         test.Input,
+			TimeLimit:     time.Duration(exercise.TimeLimitMs) * time.Millisecond,
+			MemoryLimitKB: int(exercise.MemoryLimitKb),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to run test case %d: %w", test.Position, err)
+		}
+
+		testResult := models.ExerciseTestResult{
+			Position:   test.Position,
+			Hidden:     test.Hidden,
+			Status:     string(run.Status),
+			DurationMs: run.Duration.Milliseconds(),
+		}
+		if run.Status == sandbox.StatusOK {
+			if outputsMatch(test.ExpectedOutput, run.Stdout) {
+				testResult.Passed = true
+				testResult.Status = exerciseStatusPassed
+				result.PassedCount++
+			} else {
+				testResult.Status = exerciseStatusWrongAnswer
+			}
+		}
+		if !test.Hidden {
+			testResult.Input = test.Input
+			testResult.ExpectedOutput = test.ExpectedOutput
+			testResult.ActualOutput = run.Stdout
+			testResult.Stderr = run.Stderr
+		}
+
+		result.Tests = append(result.Tests, testResult)
+	}
+
+	result.Passed = len(tests) > 0 && result.PassedCount == len(tests)
+	return result, nil
+}
+
+// outputsMatch compares program output with the expected output, ignoring
+// line endings, trailing spaces and trailing blank lines.
+func outputsMatch(expected, actual string) bool {
+	return normalizeOutput(expected) == normalizeOutput(actual)
+}
+
+func normalizeOutput(output string) string {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	codes "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrIdentityInUse    = errors.New("identity is linked to another account")
+	ErrProviderLinked   = errors.New("another identity from this provider is already linked")
+	ErrLastSignInMethod = errors.New("cannot unlink the only way to sign in")
+	ErrEmailNotVerified = errors.New("email is already registered and not verified on both sides")
+)
+
+// IdentityService links OAuth identities to accounts. Sign-in resolves the
+// provider and subject first, so a user keeps one account however their
+// provider reports their email.
+type IdentityService interface {
+	ResolveOAuthLogin(ctx context.Context, identity models.OAuthIdentity) (int32, error)
+	LinkIdentity(ctx context.Context, userID int32, identity models.OAuthIdentity) error
+	UnlinkIdentity(ctx context.Context, userID int32, provider string) error
+	GetIdentities(ctx context.Context, userID int32) ([]models.UserIdentity, error)
+}
+
+type identityService struct {
+	queries *gen.Queries
+	db      *sql.DB
+	log     *logger.Logger
+}
+
+func NewIdentityService(db *sql.DB) IdentityService {
+	return &identityService{
+		queries: gen.New(db),
+		db:      db,
+		log:     logger.Get(),
+	}
+}
+
+// ResolveOAuthLogin returns the account to sign in for identity. Unknown
+// identities are linked to the account with the same email when both the
+// provider and the account have verified that address, or to a new account
+// when there is none. Otherwise the owner has to sign in and link the
+// identity.
+func (s *identityService) ResolveOAuthLogin(ctx context.Context, identity models.OAuthIdentity) (int32, error) {
+	log := s.log.WithBaseFields(logger.Service, "ResolveOAuthLogin")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	existing, err := qtx.GetUserIdentity(ctx, gen.GetUserIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil {
+		return existing.UserID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).Error("failed to get user identity")
+		return 0, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	var userID int32
+	user, err := qtx.GetUserByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		// An unverified provider email must not take over an existing
+		// account, and neither may a verified one take over an account whose
+		// current email was never verified, as whoever registered or changed
+		// it kept its password.
+		if !identity.EmailVerified || !user.IsEmailVerified || user.VerifiedEmail.String != identity.Email {
+			return 0, ErrEmailNotVerified
+		}
+		userID = user.ID
+	case errors.Is(err, sql.ErrNoRows):
+		userID, err = createOAuthUser(ctx, qtx, identity)
+		if err != nil {
+			log.WithError(err).Error("failed to create user")
+			return 0, err
+		}
+	default:
+		log.WithError(err).Error("failed to get user by email")
+		return 0, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	if err := linkIdentity(ctx, qtx, userID, identity); err != nil {
+		if !errors.Is(err, ErrProviderLinked) {
+			log.WithError(err).Error("failed to link identity")
+		}
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (s *identityService) LinkIdentity(ctx context.Context, userID int32, identity models.OAuthIdentity) error {
+	log := s.log.WithBaseFields(logger.Service, "LinkIdentity")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	existing, err := qtx.GetUserIdentity(ctx, gen.GetUserIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil {
+		if existing.UserID != userID {
+			return ErrIdentityInUse
+		}
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).Error("failed to get user identity")
+		return fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	if err := linkIdentity(ctx, qtx, userID, identity); err != nil {
+		if !errors.Is(err, ErrProviderLinked) {
+			log.WithError(err).Error("failed to link identity")
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UnlinkIdentity removes the user's identity from provider, as long as they
+// can still sign in with a password or another identity.
+func (s *identityService) UnlinkIdentity(ctx context.Context, userID int32, provider string) error {
+	log := s.log.WithBaseFields(logger.Service, "UnlinkIdentity")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	// lock the user so concurrent unlinks cannot remove every sign-in method
+	user, err := qtx.GetUserLoginForUpdate(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return codes.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get user")
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	count, err := qtx.CountUserIdentities(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to count user identities")
+		return fmt.Errorf("failed to count user identities: %w", err)
+	}
+
+	deleted, err := qtx.DeleteUserIdentity(ctx, gen.DeleteUserIdentityParams{
+		UserID:   userID,
+		Provider: provider,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to delete user identity")
+		return fmt.Errorf("failed to delete user identity: %w", err)
+	}
+
+	if deleted == 0 {
+		return codes.ErrNotFound
+	}
+
+	if user.PasswordHash == "" && count <= 1 {
+		return ErrLastSignInMethod
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *identityService) GetIdentities(ctx context.Context, userID int32) ([]models.UserIdentity, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetIdentities")
+
+	rows, err := s.queries.GetUserIdentities(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get user identities")
+		return nil, fmt.Errorf("failed to get user identities: %w", err)
+	}
+
+	identities := make([]models.UserIdentity, len(rows))
+	for i, row := range rows {
+		identities[i] = models.UserIdentity{
+			ID:        row.ID,
+			CreatedAt: row.CreatedAt,
+			Provider:  row.Provider,
+			Email:     row.Email.String,
+		}
+	}
+
+	return identities, nil
+}
+
+func linkIdentity(ctx context.Context, qtx *gen.Queries, userID int32, identity models.OAuthIdentity) error {
+	identities, err := qtx.GetUserIdentities(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user identities: %w", err)
+	}
+	for _, existing := range identities {
+		if existing.Provider == identity.Provider {
+			return ErrProviderLinked
+		}
+	}
+
+	if _, err := qtx.CreateUserIdentity(ctx, gen.CreateUserIdentityParams{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    sql.NullString{String: identity.Email, Valid: identity.Email != ""},
+	}); err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+
+	return nil
+}
+
+// createOAuthUser creates a student account without a password for identity.
+func createOAuthUser(ctx context.Context, qtx *gen.Queries, identity models.OAuthIdentity) (int32, error) {
+	username, err := oauthUsername(identity.Email)
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := qtx.CreateUser(ctx, gen.CreateUserParams{
+		Username: username,
+		Email:    identity.Email,
+		Role:     gen.UserRole(models.RoleStudent),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if _, err := qtx.InsertUserPreferences(ctx, gen.InsertUserPreferencesParams{
+		UserID:   user.ID,
+		Theme:    "dark",
+		Language: "en",
+		Timezone: "UTC",
+	}); err != nil {
+		return 0, fmt.Errorf("failed to create user preferences: %w", err)
+	}
+
+	if identity.EmailVerified {
+		if _, err := qtx.SetUserEmailVerified(ctx, gen.SetUserEmailVerifiedParams{
+			ID:    user.ID,
+			Email: user.Email,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to mark email as verified: %w", err)
+		}
+	}
+
+	return user.ID, nil
+}
+
+// oauthUsername derives a unique-enough username from the email's local part,
+// within the 5 to 20 characters allowed at signup.
+func oauthUsername(email string) (string, error) {
+	base, _, _ := strings.Cut(email, "@")
+	if len(base) > 13 {
+		base = base[:13]
+	}
+
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate username: %w", err)
+	}
+
+	return base + "_" + hex.EncodeToString(suffix), nil
+}
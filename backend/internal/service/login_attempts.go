@@ -0,0 +1,213 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	httperr "algolearn/internal/errors"
+	"algolearn/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	loginScopeAccount = "account"
+	loginScopeIP      = "ip"
+
+	// loginBackoffBase is the delay after the second consecutive failure; it
+	// doubles with every further failure until the lockout threshold.
+	loginBackoffBase = time.Second
+)
+
+// LoginAttemptService throttles password sign-ins per account and per client
+// IP. Failures below the threshold back off exponentially; reaching it locks
+// the account or IP for the configured lockout.
+type LoginAttemptService interface {
+	// CheckLockout returns how long the caller must wait before trying to sign
+	// in as email from ip, or zero when it may try now.
+	CheckLockout(ctx context.Context, email, ip string) (time.Duration, error)
+	RecordFailure(ctx context.Context, email, ip string) error
+	RecordSuccess(ctx context.Context, email string) error
+	UnlockAccount(ctx context.Context, userID int32) error
+	RunPurgeStaleLoginAttemptsJob(ctx context.Context, interval time.Duration)
+}
+
+type loginAttemptService struct {
+	queries       *gen.Queries
+	db            *sql.DB
+	maxFailures   int32
+	maxIPFailures int32
+	lockout       time.Duration
+	log           *logger.Logger
+}
+
+func NewLoginAttemptService(db *sql.DB, maxFailures, maxIPFailures int, lockout time.Duration) LoginAttemptService {
+	return &loginAttemptService{
+		queries:       gen.New(db),
+		db:            db,
+		maxFailures:   int32(maxFailures),
+		maxIPFailures: int32(maxIPFailures),
+		lockout:       lockout,
+		log:           logger.Get(),
+	}
+}
+
+func (s *loginAttemptService) CheckLockout(ctx context.Context, email, ip string) (time.Duration, error) {
+	log := s.log.WithBaseFields(logger.Service, "CheckLockout")
+
+	seconds, err := s.queries.GetLoginLockout(ctx, gen.GetLoginLockoutParams{
+		Email:     normalizeLoginEmail(email),
+		IpAddress: ip,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to get login lockout")
+		return 0, fmt.Errorf("failed to get login lockout: %w", err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// RecordFailure counts a failed sign-in against both the account and the IP.
+// Emails without an account are counted too, so lockouts do not reveal which
+// addresses are registered.
+func (s *loginAttemptService) RecordFailure(ctx context.Context, email, ip string) error {
+	log := s.log.WithBaseFields(logger.Service, "RecordFailure")
+
+	account := normalizeLoginEmail(email)
+
+	if err := s.recordFailure(ctx, loginScopeAccount, account, account, s.maxFailures); err != nil {
+		log.WithError(err).Error("failed to record account login failure")
+		return err
+	}
+
+	if err := s.recordFailure(ctx, loginScopeIP, ip, account, s.maxIPFailures); err != nil {
+		log.WithError(err).Error("failed to record IP login failure")
+		return err
+	}
+
+	return nil
+}
+
+func (s *loginAttemptService) recordFailure(ctx context.Context, scope, key, account string, maxFailures int32) error {
+	failures, err := s.queries.RecordLoginFailure(ctx, gen.RecordLoginFailureParams{
+		Scope:         scope,
+		Key:           key,
+		AccountKey:    account,
+		WindowSeconds: int32(s.lockout.Seconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	delay := s.backoff(failures, maxFailures)
+	if delay == 0 {
+		return nil
+	}
+
+	if err := s.queries.SetLoginLockout(ctx, gen.SetLoginLockoutParams{
+		LockoutSeconds: int32(delay.Seconds()),
+		Scope:          scope,
+		Key:            key,
+	}); err != nil {
+		return fmt.Errorf("failed to set login lockout: %w", err)
+	}
+
+	return nil
+}
+
+// backoff returns how long to block sign-ins after the given number of
+// consecutive failures: nothing after the first, then doubling from
+// loginBackoffBase, and the full lockout once maxFailures is reached.
+func (s *loginAttemptService) backoff(failures, maxFailures int32) time.Duration {
+	if failures >= maxFailures {
+		return s.lockout
+	}
+	if failures < 2 {
+		return 0
+	}
+
+	delay := loginBackoffBase << (failures - 2)
+	if delay <= 0 || delay > s.lockout {
+		return s.lockout
+	}
+	return delay
+}
+
+// RecordSuccess clears the account's failures. The IP's are kept so a valid
+// account cannot be used to reset an IP that is guessing other passwords.
+func (s *loginAttemptService) RecordSuccess(ctx context.Context, email string) error {
+	log := s.log.WithBaseFields(logger.Service, "RecordSuccess")
+
+	if _, err := s.queries.ClearLoginAttempts(ctx, gen.ClearLoginAttemptsParams{
+		Scope: loginScopeAccount,
+		Key:   normalizeLoginEmail(email),
+	}); err != nil {
+		log.WithError(err).Error("failed to clear login attempts")
+		return fmt.Errorf("failed to clear login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// UnlockAccount clears the account's failures and those of the IPs whose
+// latest failure was a sign-in to it.
+func (s *loginAttemptService) UnlockAccount(ctx context.Context, userID int32) error {
+	log := s.log.WithBaseFields(logger.Service, "UnlockAccount")
+
+	user, err := s.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get user")
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	account := normalizeLoginEmail(user.Email)
+
+	if _, err := s.queries.ClearLoginAttempts(ctx, gen.ClearLoginAttemptsParams{
+		Scope: loginScopeAccount,
+		Key:   account,
+	}); err != nil {
+		log.WithError(err).Error("failed to clear login attempts")
+		return fmt.Errorf("failed to clear login attempts: %w", err)
+	}
+
+	if _, err := s.queries.ClearAccountIPLoginAttempts(ctx, account); err != nil {
+		log.WithError(err).Error("failed to clear IP login attempts")
+		return fmt.Errorf("failed to clear IP login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// RunPurgeStaleLoginAttemptsJob deletes attempts that no longer count towards
+// a lockout on every tick of interval until ctx is cancelled.
+func (s *loginAttemptService) RunPurgeStaleLoginAttemptsJob(ctx context.Context, interval time.Duration) {
+	log := s.log.WithBaseFields(logger.Service, "RunPurgeStaleLoginAttemptsJob")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.queries.DeleteStaleLoginAttempts(ctx, int32(s.lockout.Seconds()))
+			if err != nil {
+				log.WithError(err).Error("failed to purge stale login attempts")
+				continue
+			}
+			if purged > 0 {
+				log.Infof("purged %d stale login attempts", purged)
+			}
+		}
+	}
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
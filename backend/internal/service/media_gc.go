@@ -0,0 +1,170 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Profile pictures are stored as users/<folder>/<object>.<ext> like course
+// media, where folder is the folder object key of the user.
+const userMediaResource = "users"
+
+// mediaGCResources are the storage prefixes whose objects the database keeps
+// track of. Objects under any other prefix are never collected.
+var mediaGCResources = []string{
+	userMediaResource,
+	courseMediaResource,
+	unitMediaResource,
+	moduleMediaResource,
+}
+
+// MediaGCService finds media objects in storage that the database no longer
+// refers to and deletes them.
+type MediaGCService interface {
+	CollectOrphanedMedia(ctx context.Context, grace time.Duration, dryRun bool) (*models.MediaGCResult, error)
+}
+
+type mediaGCService struct {
+	queries *gen.Queries
+	storage StorageService
+	log     *logger.Logger
+}
+
+func NewMediaGCService(db *sql.DB, storage StorageService) MediaGCService {
+	return &mediaGCService{
+		queries: gen.New(db),
+		storage: storage,
+		log:     logger.Get(),
+	}
+}
+
+// mediaRefs holds the folders and objects the database refers to, by
+// resource.
+type mediaRefs struct {
+	folders map[string]map[uuid.UUID]bool
+	objects map[string]map[uuid.UUID]map[uuid.UUID]bool
+}
+
+// referenced reports whether the object stored under key is still in use.
+// Folder placeholders are in use as long as their folder is. Keys that do not
+// look like <resource>/<folder>/<object>.<ext> were not written by the app
+// and are always treated as in use.
+func (r *mediaRefs) referenced(key string) bool {
+	parts := strings.Split(key, "/")
+	if len(parts) != 3 {
+		return true
+	}
+	resource, name := parts[0], parts[2]
+
+	folder, err := uuid.Parse(parts[1])
+	if err != nil {
+		return true
+	}
+	if !r.folders[resource][folder] {
+		return false
+	}
+	if name == ".folder" {
+		return true
+	}
+
+	object, err := uuid.Parse(strings.TrimSuffix(name, path.Ext(name)))
+	if err != nil {
+		return true
+	}
+	return r.objects[resource][folder][object]
+}
+
+// CollectOrphanedMedia lists the media objects of every tracked resource and
+// deletes those the database does not refer to, unless they were modified
+// within grace or dryRun is set. Content in the trash still refers to its
+// media until it is purged.
+func (s *mediaGCService) CollectOrphanedMedia(ctx context.Context, grace time.Duration, dryRun bool) (*models.MediaGCResult, error) {
+	log := s.log.WithBaseFields(logger.Service, "CollectOrphanedMedia")
+
+	// Objects are listed before the references are read, so an object
+	// uploaded and saved while this runs is never mistaken for an orphan.
+	var objects []ObjectInfo
+	for _, resource := range mediaGCResources {
+		listed, err := s.storage.ListObjects(ctx, resource+"/")
+		if err != nil {
+			log.WithError(err).Errorf("failed to list %s media", resource)
+			return nil, fmt.Errorf("failed to list %s media: %w", resource, err)
+		}
+		objects = append(objects, listed...)
+	}
+
+	rows, err := s.queries.GetMediaReferences(ctx)
+	if err != nil {
+		log.WithError(err).Error("failed to get media references")
+		return nil, fmt.Errorf("failed to get media references: %w", err)
+	}
+
+	refs := &mediaRefs{
+		folders: map[string]map[uuid.UUID]bool{},
+		objects: map[string]map[uuid.UUID]map[uuid.UUID]bool{},
+	}
+	for _, row := range rows {
+		if refs.folders[row.Resource] == nil {
+			refs.folders[row.Resource] = map[uuid.UUID]bool{}
+			refs.objects[row.Resource] = map[uuid.UUID]map[uuid.UUID]bool{}
+		}
+		folder := row.FolderObjectKey.UUID
+		refs.folders[row.Resource][folder] = true
+		if !row.ObjectKey.Valid {
+			continue
+		}
+		if refs.objects[row.Resource][folder] == nil {
+			refs.objects[row.Resource][folder] = map[uuid.UUID]bool{}
+		}
+		refs.objects[row.Resource][folder][row.ObjectKey.UUID] = true
+	}
+
+	result := &models.MediaGCResult{
+		DryRun:   dryRun,
+		Scanned:  len(objects),
+		Orphaned: []models.OrphanedMedia{},
+	}
+	cutoff := time.Now().Add(-grace)
+
+	var keys []string
+	for _, object := range objects {
+		if refs.referenced(object.Key) {
+			continue
+		}
+		if object.LastModified.After(cutoff) {
+			result.Recent++
+			continue
+		}
+
+		result.Orphaned = append(result.Orphaned, models.OrphanedMedia{
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: object.LastModified,
+		})
+		result.OrphanedBytes += object.Size
+		keys = append(keys, object.Key)
+	}
+
+	if dryRun || len(keys) == 0 {
+		return result, nil
+	}
+
+	if err := s.storage.DeleteObjects(ctx, keys); err != nil {
+		log.WithError(err).Error("failed to delete orphaned media")
+		return nil, fmt.Errorf("failed to delete orphaned media: %w", err)
+	}
+	result.Deleted = len(keys)
+
+	log.Infof("deleted %d orphaned media objects (%d bytes)", result.Deleted, result.OrphanedBytes)
+
+	return result, nil
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/security"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	mfaIssuer = "AlgoLearn"
+	// mfaChallengeExpiry is how long a user has to enter their code after
+	// the password step.
+	mfaChallengeExpiry = time.Minute * 5 // 5 minutes
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("multi-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("multi-factor authentication is not enabled")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+	ErrNoPendingTOTP     = errors.New("no TOTP enrolment in progress")
+)
+
+// MFAService manages TOTP second factors and their recovery codes. Enrolment
+// takes two steps: BeginTOTPEnrolment hands out a secret, and MFA is only
+// enabled once ConfirmTOTPEnrolment sees a code generated from it.
+type MFAService interface {
+	GetStatus(ctx context.Context, userID int32) (*models.MFAStatus, error)
+	IsEnabled(ctx context.Context, userID int32) (bool, error)
+	BeginTOTPEnrolment(ctx context.Context, userID int32) (*models.TOTPEnrolment, error)
+	ConfirmTOTPEnrolment(ctx context.Context, userID int32, code string) ([]string, error)
+	VerifyCode(ctx context.Context, userID int32, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int32, code string) ([]string, error)
+	Disable(ctx context.Context, userID int32, code string) error
+	IssueChallenge(userID int32) (string, error)
+	ValidateChallenge(token string) (int32, error)
+}
+
+type mfaService struct {
+	queries *gen.Queries
+	db      *sql.DB
+	log     *logger.Logger
+}
+
+func NewMFAService(db *sql.DB) MFAService {
+	return &mfaService{
+		queries: gen.New(db),
+		db:      db,
+		log:     logger.Get(),
+	}
+}
+
+func (s *mfaService) GetStatus(ctx context.Context, userID int32) (*models.MFAStatus, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetStatus")
+
+	mfa, err := s.queries.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.MFAStatus{}, nil
+		}
+		log.WithError(err).Error("failed to get MFA settings")
+		return nil, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+
+	if !mfa.EnabledAt.Valid {
+		return &models.MFAStatus{}, nil
+	}
+
+	remaining, err := s.queries.CountUnusedUserRecoveryCodes(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to count recovery codes")
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return &models.MFAStatus{
+		Enabled:                true,
+		EnabledAt:              &mfa.EnabledAt.Time,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+func (s *mfaService) IsEnabled(ctx context.Context, userID int32) (bool, error) {
+	log := s.log.WithBaseFields(logger.Service, "IsEnabled")
+
+	mfa, err := s.queries.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		log.WithError(err).Error("failed to get MFA settings")
+		return false, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+
+	return mfa.EnabledAt.Valid, nil
+}
+
+// BeginTOTPEnrolment generates a new secret for the user, replacing any
+// enrolment that was never confirmed.
+func (s *mfaService) BeginTOTPEnrolment(ctx context.Context, userID int32) (*models.TOTPEnrolment, error) {
+	log := s.log.WithBaseFields(logger.Service, "BeginTOTPEnrolment")
+
+	user, err := s.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get user")
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		log.WithError(err).Error("failed to generate TOTP secret")
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	stored, err := s.queries.SetPendingUserTOTPSecret(ctx, gen.SetPendingUserTOTPSecretParams{
+		UserID:     userID,
+		TotpSecret: secret,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to store TOTP secret")
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+	if stored == 0 {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	return &models.TOTPEnrolment{
+		Secret:     secret,
+		OTPAuthURI: security.TOTPURI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrolment enables MFA once code matches the pending secret and
+// returns the user's recovery codes. They are only ever shown this once.
+// Every session is revoked, so the user signs in again with the second
+// factor.
+func (s *mfaService) ConfirmTOTPEnrolment(ctx context.Context, userID int32, code string) ([]string, error) {
+	log := s.log.WithBaseFields(logger.Service, "ConfirmTOTPEnrolment")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	mfa, err := qtx.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoPendingTOTP
+		}
+		log.WithError(err).Error("failed to get MFA settings")
+		return nil, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	if mfa.EnabledAt.Valid {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err := useTOTPCode(ctx, qtx, mfa, code); err != nil {
+		if !errors.Is(err, ErrInvalidMFACode) {
+			log.WithError(err).Error("failed to verify TOTP code")
+		}
+		return nil, err
+	}
+
+	if _, err := qtx.EnableUserMFA(ctx, userID); err != nil {
+		log.WithError(err).Error("failed to enable MFA")
+		return nil, fmt.Errorf("failed to enable MFA: %w", err)
+	}
+
+	codes, err := replaceRecoveryCodes(ctx, qtx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to create recovery codes")
+		return nil, err
+	}
+
+	// Sessions signed in with the password alone must not outlive enrolment.
+	if _, err := qtx.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		log.WithError(err).Error("failed to revoke refresh tokens")
+		return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return codes, nil
+}
+
+// VerifyCode accepts either a current TOTP code or an unused recovery code,
+// which is then spent.
+func (s *mfaService) VerifyCode(ctx context.Context, userID int32, code string) error {
+	log := s.log.WithBaseFields(logger.Service, "VerifyCode")
+
+	if err := verifyMFACode(ctx, s.queries, userID, code); err != nil {
+		if !errors.Is(err, ErrInvalidMFACode) && !errors.Is(err, ErrMFANotEnabled) {
+			log.WithError(err).Error("failed to verify MFA code")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int32, code string) ([]string, error) {
+	log := s.log.WithBaseFields(logger.Service, "RegenerateRecoveryCodes")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	if err := verifyMFACode(ctx, qtx, userID, code); err != nil {
+		if !errors.Is(err, ErrInvalidMFACode) && !errors.Is(err, ErrMFANotEnabled) {
+			log.WithError(err).Error("failed to verify MFA code")
+		}
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(ctx, qtx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to create recovery codes")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Disable turns MFA off after checking a code, and drops the recovery codes.
+func (s *mfaService) Disable(ctx context.Context, userID int32, code string) error {
+	log := s.log.WithBaseFields(logger.Service, "Disable")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	if err := verifyMFACode(ctx, qtx, userID, code); err != nil {
+		if !errors.Is(err, ErrInvalidMFACode) && !errors.Is(err, ErrMFANotEnabled) {
+			log.WithError(err).Error("failed to verify MFA code")
+		}
+		return err
+	}
+
+	if err := qtx.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		log.WithError(err).Error("failed to delete recovery codes")
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := qtx.DeleteUserMFA(ctx, userID); err != nil {
+		log.WithError(err).Error("failed to delete MFA settings")
+		return fmt.Errorf("failed to delete MFA settings: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IssueChallenge returns the short-lived token that stands in for an access
+// token between the password and the second-factor steps of a sign-in.
+func (s *mfaService) IssueChallenge(userID int32) (string, error) {
+	token, err := security.GenerateActionToken(userID, security.PurposeMFAChallenge, uuid.NewString(), mfaChallengeExpiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign MFA challenge: %w", err)
+	}
+	return token, nil
+}
+
+// ValidateChallenge returns the user an MFA challenge was issued to. The
+// challenge is not single-use; each TOTP code and recovery code is.
+func (s *mfaService) ValidateChallenge(token string) (int32, error) {
+	claims, err := security.ValidateActionToken(token, security.PurposeMFAChallenge)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
+}
+
+func verifyMFACode(ctx context.Context, q *gen.Queries, userID int32, code string) error {
+	mfa, err := q.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMFANotEnabled
+		}
+		return fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	if !mfa.EnabledAt.Valid {
+		return ErrMFANotEnabled
+	}
+
+	if err := useTOTPCode(ctx, q, mfa, code); !errors.Is(err, ErrInvalidMFACode) {
+		return err
+	}
+
+	used, err := q.UseUserRecoveryCode(ctx, gen.UseUserRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: security.HashRecoveryCode(code),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if used == 0 {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// useTOTPCode checks code against the user's secret and records its time
+// step, so the same code cannot be replayed.
+func useTOTPCode(ctx context.Context, q *gen.Queries, mfa gen.UserMfa, code string) error {
+	step, ok := security.ValidateTOTP(mfa.TotpSecret, code, time.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	used, err := q.UseUserTOTPStep(ctx, gen.UseUserTOTPStepParams{
+		Step:   step,
+		UserID: mfa.UserID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+	if used == 0 {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, q *gen.Queries, userID int32) ([]string, error) {
+	codes, err := security.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, code := range codes {
+		if err := q.CreateUserRecoveryCode(ctx, gen.CreateUserRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: security.HashRecoveryCode(code),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+
+	return codes, nil
+}
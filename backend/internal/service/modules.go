@@ -5,11 +5,14 @@ import (
 	httperr "algolearn/internal/errors"
 	"algolearn/internal/models"
 	"algolearn/pkg/logger"
+	"algolearn/pkg/sandbox"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,24 +25,47 @@ type ModuleService interface {
 	GetModuleWithProgress(ctx context.Context, userID, courseID, unitID, moduleID int64) (*ModuleWithProgressResponse, error)
 	GetModulesWithProgress(ctx context.Context, userID, unitID int64, page, pageSize int) ([]models.Module, error)
 	GetModuleTotalCount(ctx context.Context, unitID int64) (int64, error)
+	GetModuleByNumber(ctx context.Context, unitID int64, moduleNumber int32) (*models.Module, error)
+	GetModuleSections(ctx context.Context, courseID, unitID, moduleID int64) ([]models.Section, error)
 	CreateModule(ctx context.Context, unitID int64, name, description string, moduleNumber int32, folderObjectKey uuid.NullUUID, imgKey uuid.NullUUID) (*models.Module, error)
 	CreateModuleWithContent(ctx context.Context, unitID int64, name, description string, moduleNumber int32, folderObjectKey uuid.NullUUID, imgKey uuid.NullUUID, sections []models.Section) (*models.Module, error)
 	UpdateModule(ctx context.Context, moduleID int64, name, description string) (*models.Module, error)
+	ReplaceModuleContent(ctx context.Context, moduleID int64, name, description string, sections []models.Section) (*models.Module, error)
+	PatchSections(ctx context.Context, moduleID int64, patch models.SectionPatch) ([]models.Section, error)
+	CloneModule(ctx context.Context, moduleID int64, name string) (*models.Module, error)
+	ReorderModules(ctx context.Context, courseID, unitID int64, moduleIDs []int64) ([]models.Module, error)
+	ReorderSections(ctx context.Context, moduleID int64, sectionIDs []int64) ([]models.Section, error)
 	DeleteModule(ctx context.Context, moduleID int64) error
-	SaveModuleProgress(ctx context.Context, userID, moduleID int64, sections []models.SectionProgress, questions []models.QuestionProgress) error
+	SaveModuleProgress(ctx context.Context, userID, moduleID int64, sections []models.SectionProgress, questions []models.QuestionProgress) (*models.ModuleProgressResult, error)
+	SubmitExercise(ctx context.Context, userID, moduleID, sectionID int64, code string) (*models.ExerciseResult, error)
 }
 
+// ErrInvalidSectionPatch is returned when a section patch does not account
+// for the sections of its module.
+var ErrInvalidSectionPatch = errors.New("invalid section patch")
+
+// ErrInvalidSectionContent is returned when section content does not have the
+// shape its section type expects.
+var ErrInvalidSectionContent = errors.New("invalid section content")
+
 type moduleService struct {
 	queries *gen.Queries
 	db      *sql.DB
 	log     *logger.Logger
+	runner  sandbox.Runner
+	storage StorageService
 }
 
-func NewModuleService(db *sql.DB) ModuleService {
+// NewModuleService returns a ModuleService that runs exercise submissions
+// with runner and copies the media of cloned modules within storage; a nil
+// runner turns submissions off.
+func NewModuleService(db *sql.DB, runner sandbox.Runner, storage StorageService) ModuleService {
 	return &moduleService{
 		queries: gen.New(db),
 		db:      db,
 		log:     logger.Get(),
+		runner:  runner,
+		storage: storage,
 	}
 }
 
@@ -289,6 +315,66 @@ func (s *moduleService) GetModuleTotalCount(ctx context.Context, unitID int64) (
 	return count, nil
 }
 
+// GetModuleByNumber returns the module of a unit with the given number, or
+// httperr.ErrNotFound.
+func (s *moduleService) GetModuleByNumber(ctx context.Context, unitID int64, moduleNumber int32) (*models.Module, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetModuleByNumber")
+
+	module, err := s.queries.GetModuleByNumber(ctx, gen.GetModuleByNumberParams{
+		UnitID:       int32(unitID),
+		ModuleNumber: moduleNumber,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get module by number")
+		return nil, fmt.Errorf("failed to get module by number: %w", err)
+	}
+
+	return &models.Module{
+		BaseModel: models.BaseModel{
+			ID:        int64(module.ID),
+			CreatedAt: module.CreatedAt,
+			UpdatedAt: module.UpdatedAt,
+		},
+		FolderObjectKey: uuid.NullUUID{UUID: module.FolderObjectKey.UUID, Valid: module.FolderObjectKey.Valid},
+		ImgKey:          uuid.NullUUID{UUID: module.ImgKey.UUID, Valid: module.ImgKey.Valid},
+		MediaExt:        module.MediaExt.String,
+		ModuleNumber:    int16(module.ModuleNumber),
+		Name:            module.Name,
+		Description:     module.Description,
+		Sections:        make([]models.SectionInterface, 0),
+	}, nil
+}
+
+// GetModuleSections returns the sections of a module in the shape they are
+// edited in, with the answer keys of questions and hidden test cases, for
+// the authors of its course.
+func (s *moduleService) GetModuleSections(ctx context.Context, courseID, unitID, moduleID int64) ([]models.Section, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetModuleSections")
+
+	ids, err := s.queries.GetCourseAndUnitIDs(ctx, int32(moduleID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get course and unit IDs")
+		return nil, fmt.Errorf("failed to get course and unit IDs: %w", err)
+	}
+	if int64(ids.CourseID) != courseID || int64(ids.UnitID) != unitID {
+		return nil, httperr.ErrNotFound
+	}
+
+	sections, err := moduleSections(ctx, s.queries, int32(moduleID))
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	return sections, nil
+}
+
 func (s *moduleService) CreateModule(ctx context.Context, unitID int64, name, description string, moduleNumber int32, folderObjectKey uuid.NullUUID, imgKey uuid.NullUUID) (*models.Module, error) {
 	log := s.log.WithBaseFields(logger.Service, "CreateModule")
 
@@ -366,19 +452,212 @@ func (s *moduleService) UpdateModule(ctx context.Context, moduleID int64, name,
 	}, nil
 }
 
+// CloneModule copies a module with its sections and media to the end of its
+// unit as a draft module named name, or "<module name> (copy)" if name is
+// empty. The copy gets new object keys and its media is copied in storage.
+func (s *moduleService) CloneModule(ctx context.Context, moduleID int64, name string) (*models.Module, error) {
+	log := s.log.WithBaseFields(logger.Service, "CloneModule")
+
+	module, err := s.queries.GetModuleByID(ctx, int32(moduleID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get module")
+		return nil, fmt.Errorf("failed to get module: %w", err)
+	}
+
+	unit, err := s.queries.GetUnitByID(ctx, module.UnitID)
+	if err != nil {
+		log.WithError(err).Error("failed to get unit")
+		return nil, fmt.Errorf("failed to get unit: %w", err)
+	}
+
+	clone := models.ArchiveModule{
+		ID:              int64(module.ID),
+		FolderObjectKey: module.FolderObjectKey,
+		ImgKey:          module.ImgKey,
+		MediaExt:        module.MediaExt.String,
+		Name:            cloneName(name, module.Name),
+		Description:     module.Description,
+	}
+	if err := archiveModuleSections(ctx, s.queries, &clone); err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	plan := newImportPlan(func(string) bool { return true })
+	moduleMedia(clone, plan.addMedia)
+
+	// Media copied for a clone that is rolled back is deleted again.
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := plan.discard(ctx, s.storage); err != nil {
+			log.WithError(err).Error("failed to delete media of failed clone")
+		}
+	}()
+
+	// The media is copied before the transaction so that the course is not
+	// locked while storage is slow.
+	missing, err := plan.copyMedia(ctx, s.storage)
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	for _, key := range missing {
+		log.WithField("key", key).Warn("media object missing from storage")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	// Modules are reordered under the course lock, so it also keeps the copy
+	// numbered after the last module of the unit.
+	if err := qtx.LockCourse(ctx, unit.CourseID); err != nil {
+		log.WithError(err).Error("failed to lock course")
+		return nil, fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	last, err := qtx.GetLastModuleNumber(ctx, module.UnitID)
+	if err != nil {
+		log.WithError(err).Error("failed to get last module number")
+		return nil, fmt.Errorf("failed to get last module number: %w", err)
+	}
+	clone.ModuleNumber = last + 1
+
+	cloneID, err := insertModuleTree(ctx, qtx, module.UnitID, clone, plan, newArchiveIDMap())
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	created, err := qtx.GetModuleByID(ctx, cloneID)
+	if err != nil {
+		log.WithError(err).Error("failed to get module")
+		return nil, fmt.Errorf("failed to get module: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	return &models.Module{
+		BaseModel: models.BaseModel{
+			ID:        int64(created.ID),
+			CreatedAt: created.CreatedAt,
+			UpdatedAt: created.UpdatedAt,
+		},
+		FolderObjectKey: created.FolderObjectKey,
+		ImgKey:          created.ImgKey,
+		MediaExt:        created.MediaExt.String,
+		ModuleNumber:    int16(created.ModuleNumber),
+		Name:            created.Name,
+		Description:     created.Description,
+		Sections:        make([]models.SectionInterface, 0),
+	}, nil
+}
+
+// DeleteModule moves the module to the trash.
 func (s *moduleService) DeleteModule(ctx context.Context, moduleID int64) error {
 	log := s.log.WithBaseFields(logger.Service, "DeleteModule")
 
-	err := s.queries.DeleteModule(ctx, int32(moduleID))
+	deleted, err := s.queries.SoftDeleteModule(ctx, int32(moduleID))
 	if err != nil {
 		log.WithError(err).Error("failed to delete module")
 		return fmt.Errorf("failed to delete module: %w", err)
 	}
+	if deleted == 0 {
+		return httperr.ErrNotFound
+	}
 	return nil
 }
 
-func (s *moduleService) SaveModuleProgress(ctx context.Context, userID, moduleID int64, sections []models.SectionProgress, questions []models.QuestionProgress) error {
-	log := s.log.WithBaseFields(logger.Service, "SaveModuleProgress")
+func (s *moduleService) SaveModuleProgress(ctx context.Context, userID, moduleID int64, sections []models.SectionProgress, questions []models.QuestionProgress) (*models.ModuleProgressResult, error) {
+	var results []models.QuestionResult
+	err := s.recordProgress(ctx, userID, moduleID, func(qtx *gen.Queries, progressID int64) error {
+		var err error
+		results, err = s.saveSectionAndQuestionProgress(ctx, qtx, userID, moduleID, progressID, sections, questions)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ModuleProgressResult{Questions: results}, nil
+}
+
+// SubmitExercise runs code against the exercise's test cases and records the
+// submission as module progress. The tests run before the transaction is
+// opened, so a slow submission does not hold it.
+func (s *moduleService) SubmitExercise(ctx context.Context, userID, moduleID, sectionID int64, code string) (*models.ExerciseResult, error) {
+	log := s.log.WithBaseFields(logger.Service, "SubmitExercise")
+
+	exercise, err := s.queries.GetModuleExerciseSection(ctx, gen.GetModuleExerciseSectionParams{
+		SectionID: int32(sectionID),
+		ModuleID:  int32(moduleID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get exercise")
+		return nil, fmt.Errorf("failed to get exercise: %w", err)
+	}
+
+	if s.runner == nil {
+		return nil, ErrCodeRunnerUnavailable
+	}
+
+	tests, err := s.queries.GetExerciseTestCases(ctx, exercise.SectionID)
+	if err != nil {
+		log.WithError(err).Error("failed to get exercise test cases")
+		return nil, fmt.Errorf("failed to get exercise test cases: %w", err)
+	}
+
+	result, err := runExercise(ctx, s.runner, exercise, tests, code)
+	if err != nil {
+		log.WithError(err).Error("failed to run exercise")
+		return nil, err
+	}
+
+	err = s.recordProgress(ctx, userID, moduleID, func(qtx *gen.Queries, _ int64) error {
+		submissionID, err := qtx.InsertExerciseSubmission(ctx, gen.InsertExerciseSubmissionParams{
+			UserID:      int32(userID),
+			SectionID:   exercise.SectionID,
+			Code:        code,
+			Passed:      result.Passed,
+			PassedCount: int32(result.PassedCount),
+			TotalCount:  int32(result.TotalCount),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert exercise submission: %w", err)
+		}
+		result.SubmissionID = int64(submissionID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// recordProgress saves the user's progress on a module in one transaction:
+// record writes what the user did, then the module and course progress are
+// recalculated, the streak is extended and achievements are awarded.
+func (s *moduleService) recordProgress(ctx context.Context, userID, moduleID int64, record func(qtx *gen.Queries, progressID int64) error) error {
+	log := s.log.WithBaseFields(logger.Service, "recordProgress")
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -414,8 +693,8 @@ func (s *moduleService) SaveModuleProgress(ctx context.Context, userID, moduleID
 
 	progressID := int64(progressIDInt32)
 
-	// Step 4: Save section and question progress
-	if err := s.saveSectionAndQuestionProgress(ctx, qtx, userID, moduleID, progressID, sections, questions); err != nil {
+	// Step 4: Record what the user did
+	if err := record(qtx, progressID); err != nil {
 		log.WithError(err).Error(err.Error())
 		return err
 	}
@@ -426,11 +705,31 @@ func (s *moduleService) SaveModuleProgress(ctx context.Context, userID, moduleID
 		return err
 	}
 
+	// Step 6: Extend the user's daily streak
+	if err := recordStreakActivity(ctx, qtx, int32(userID), time.Now()); err != nil {
+		log.WithError(err).Error(err.Error())
+		return err
+	}
+
+	// Step 7: Award any achievements earned by this progress
+	awarded, err := awardAchievements(ctx, qtx, int32(userID))
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		log.WithError(err).Error("failed to commit transaction")
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	for _, achievement := range awarded {
+		log.WithFields(logrus.Fields{
+			"userID":        userID,
+			"achievementID": achievement.ID,
+		}).Info("achievement awarded")
+	}
+
 	return nil
 }
 
@@ -562,8 +861,9 @@ func (s *moduleService) upsertNextModuleProgress(ctx context.Context, qtx *gen.Q
 	return nil
 }
 
-// saveSectionAndQuestionProgress saves the progress of sections and questions
-func (s *moduleService) saveSectionAndQuestionProgress(ctx context.Context, qtx *gen.Queries, userID, moduleID int64, progressID int64, sections []models.SectionProgress, questions []models.QuestionProgress) error {
+// saveSectionAndQuestionProgress saves the progress of sections and grades
+// and saves the question answers, returning their results
+func (s *moduleService) saveSectionAndQuestionProgress(ctx context.Context, qtx *gen.Queries, userID, moduleID int64, progressID int64, sections []models.SectionProgress, questions []models.QuestionProgress) ([]models.QuestionResult, error) {
 	// Save section progress
 	for _, section := range sections {
 		err := qtx.UpsertSectionProgress(ctx, gen.UpsertSectionProgressParams{
@@ -574,28 +874,34 @@ func (s *moduleService) saveSectionAndQuestionProgress(ctx context.Context, qtx
 			SeenAt:    sql.NullTime{Time: section.SeenAt, Valid: !section.SeenAt.IsZero()},
 		})
 		if err != nil {
-			return fmt.Errorf("failed to upsert section progress: %w", err)
+			return nil, fmt.Errorf("failed to upsert section progress: %w", err)
 		}
 	}
 
-	// Save question progress
+	// Grade and save question answers, queueing new wrong ones for review.
+	// Progress is saved in batches that resend earlier answers, which are
+	// neither attempts nor lapses.
+	results := make([]models.QuestionResult, 0, len(questions))
 	for _, question := range questions {
-		var optionID int32
-		if question.OptionID != nil {
-			optionID = int32(*question.OptionID)
-		}
-		err := qtx.UpsertQuestionAnswer(ctx, gen.UpsertQuestionAnswerParams{
-			UserModuleProgressID: int32(progressID),
-			QuestionID:           int32(question.QuestionID),
-			OptionID:             optionID,
-			IsCorrect:            question.IsCorrect != nil && *question.IsCorrect,
-		})
+		params, result, err := gradeQuestionAnswer(ctx, qtx, moduleID, question)
+		if err != nil {
+			return nil, err
+		}
+		params.UserModuleProgressID = int32(progressID)
+		saved, err := qtx.UpsertQuestionAnswer(ctx, params)
 		if err != nil {
-			return fmt.Errorf("failed to upsert question answer: %w", err)
+			return nil, fmt.Errorf("failed to upsert question answer: %w", err)
+		}
+		result.Attempts = saved.Attempts
+		if !result.IsCorrect && saved.Changed {
+			if err := enqueueReview(ctx, qtx, int32(userID), params.QuestionID, time.Now()); err != nil {
+				return nil, err
+			}
 		}
+		results = append(results, result)
 	}
 
-	return nil
+	return results, nil
 }
 
 // calculateAndUpdateProgress calculates and updates the progress for the module and course
@@ -689,177 +995,114 @@ func (s *moduleService) CreateModuleWithContent(ctx context.Context, unitID int6
 		return nil, fmt.Errorf("failed to insert module: %w", err)
 	}
 
-	for _, section := range sections {
-		createdSection, err := qtx.InsertSection(ctx, gen.InsertSectionParams{
-			ModuleID:    module.ID,
-			SectionType: gen.SectionType(section.Type),
-			Position:    int32(section.Position),
-		})
-		if err != nil {
-			log.WithError(err).Error("failed to insert section")
-			return nil, fmt.Errorf("failed to insert section: %w", err)
-		}
+	if _, err := insertSections(ctx, qtx, module.ID, sections); err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
 
-		switch section.Type {
-		case "markdown":
-			var content models.MarkdownContent
-			if err := json.Unmarshal(section.Content, &content); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal text content: %w", err)
-			}
-			err = qtx.InsertMarkdownSection(ctx, gen.InsertMarkdownSectionParams{
-				SectionID: createdSection.ID,
-				Markdown:  content.Markdown,
-				ObjectKey: uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
-			})
-			if err != nil {
-				log.WithError(err).Error("failed to insert text section")
-				return nil, fmt.Errorf("failed to insert text section: %w", err)
-			}
+	if err = tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-		case "code":
-			var content models.CodeContent
-			if err := json.Unmarshal(section.Content, &content); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal code content: %w", err)
-			}
+	return &models.Module{
+		BaseModel: models.BaseModel{
+			ID:        int64(module.ID),
+			CreatedAt: module.CreatedAt,
+			UpdatedAt: module.UpdatedAt,
+		},
+		FolderObjectKey: uuid.NullUUID{UUID: module.FolderObjectKey.UUID, Valid: module.FolderObjectKey.Valid},
+		ImgKey:          uuid.NullUUID{UUID: module.ImgKey.UUID, Valid: module.ImgKey.Valid},
+		ModuleNumber:    int16(module.ModuleNumber),
+		Name:            module.Name,
+		Description:     module.Description,
+		Sections:        make([]models.SectionInterface, 0),
+	}, nil
+}
 
-			sectionParams := gen.InsertCodeSectionParams{
-				SectionID: createdSection.ID,
-				Code:      content.Code,
-			}
+// ReplaceModuleContent updates the name and description of a module and
+// gives it the given sections. They are matched to the module's sections by
+// position, and where the types agree the section is kept and its content
+// updated in place, as PatchSections does, so learner progress through it
+// survives. The module's other sections are deleted and the rest inserted.
+func (s *moduleService) ReplaceModuleContent(ctx context.Context, moduleID int64, name, description string, sections []models.Section) (*models.Module, error) {
+	log := s.log.WithBaseFields(logger.Service, "ReplaceModuleContent")
 
-			if content.Language != "" {
-				sectionParams.Language = sql.NullString{String: content.Language, Valid: true}
-			}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-			err = qtx.InsertCodeSection(ctx, sectionParams)
-			if err != nil {
-				log.WithError(err).Error("failed to insert code section")
-				return nil, fmt.Errorf("failed to insert code section: %w", err)
-			}
+	qtx := s.queries.WithTx(tx)
 
-		case "question":
-			var content models.QuestionContent
-			if err := json.Unmarshal(section.Content, &content); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal question content: %w", err)
-			}
+	module, err := qtx.UpdateModule(ctx, gen.UpdateModuleParams{
+		ModuleID:    int32(moduleID),
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to update module")
+		return nil, fmt.Errorf("failed to update module: %w", err)
+	}
 
-			question, err := qtx.InsertQuestion(ctx, gen.InsertQuestionParams{
-				Type:            content.Type,
-				Question:        content.Question,
-				DifficultyLevel: gen.NullDifficultyLevel{DifficultyLevel: "beginner", Valid: true},
-			})
-			if err != nil {
-				log.WithError(err).Error("failed to insert question")
-				return nil, fmt.Errorf("failed to insert question: %w", err)
-			}
+	existing, err := qtx.GetModuleSections(ctx, module.ID)
+	if err != nil {
+		log.WithError(err).Error("failed to get module sections")
+		return nil, fmt.Errorf("failed to get module sections: %w", err)
+	}
 
-			err = qtx.InsertQuestionSection(ctx, gen.InsertQuestionSectionParams{
-				SectionID:  createdSection.ID,
-				QuestionID: question.ID,
-			})
-			if err != nil {
-				log.WithError(err).Error("failed to insert question section")
-				return nil, fmt.Errorf("failed to insert question section: %w", err)
-			}
+	for i, section := range existing {
+		if i < len(sections) && sections[i].Type == models.SectionType(section.Type) {
+			continue
+		}
+		// Questions are not owned by their sections, so they are deleted first.
+		if err := qtx.DeleteSectionQuestion(ctx, section.ID); err != nil {
+			log.WithError(err).Error("failed to delete section question")
+			return nil, fmt.Errorf("failed to delete section question: %w", err)
+		}
+		if err := qtx.DeleteSection(ctx, section.ID); err != nil {
+			log.WithError(err).Error("failed to delete section")
+			return nil, fmt.Errorf("failed to delete section: %w", err)
+		}
+	}
 
-			for _, opt := range content.Options {
-				err = qtx.InsertQuestionOption(ctx, gen.InsertQuestionOptionParams{
-					QuestionID: question.ID,
-					Content:    opt.Content,
-					IsCorrect:  opt.IsCorrect,
-				})
-				if err != nil {
-					log.WithError(err).Error("failed to insert question option")
-					return nil, fmt.Errorf("failed to insert question option: %w", err)
-				}
-			}
+	if err := qtx.ClearSectionPositions(ctx, module.ID); err != nil {
+		log.WithError(err).Error("failed to clear section positions")
+		return nil, fmt.Errorf("failed to clear section positions: %w", err)
+	}
 
-			for _, tagName := range content.Tags {
-				tagID, err := qtx.InsertTag(ctx, tagName)
-				if err != nil {
-					log.WithError(err).Error("failed to insert tag")
-					return nil, fmt.Errorf("failed to insert tag: %w", err)
-				}
+	for i, section := range sections {
+		position := int16(i + 1)
 
-				err = qtx.InsertQuestionTag(ctx, gen.InsertQuestionTagParams{
-					QuestionID: question.ID,
-					TagID:      tagID,
-				})
-				if err != nil {
-					log.WithError(err).Error("failed to insert question tag")
-					return nil, fmt.Errorf("failed to insert question tag: %w", err)
+		if i >= len(existing) || models.SectionType(existing[i].Type) != section.Type {
+			section.Position = position
+			if _, err := insertSections(ctx, qtx, module.ID, []models.Section{section}); err != nil {
+				if !isInvalidContent(err) {
+					log.WithError(err).Error(err.Error())
 				}
+				return nil, err
 			}
+			continue
+		}
 
-		case "video":
-			var content models.VideoContent
-			if err := json.Unmarshal(section.Content, &content); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal video content: %w", err)
-			}
-			err = qtx.InsertVideoSection(ctx, gen.InsertVideoSectionParams{
-				SectionID: createdSection.ID,
-				Url:       content.URL,
-			})
-			if err != nil {
-				log.WithError(err).Error("failed to insert video section")
-				return nil, fmt.Errorf("failed to insert video section: %w", err)
-			}
-
-		case "lottie":
-			var content models.LottieContent
-			if err := json.Unmarshal(section.Content, &content); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal lottie content: %w", err)
-			}
-
-			sectionParams := gen.InsertLottieSectionParams{
-				SectionID: createdSection.ID,
-			}
-			sectionParams.Caption = sql.NullString{String: content.Caption, Valid: content.Caption != ""}
-			sectionParams.Description = sql.NullString{String: content.Description, Valid: content.Description != ""}
-			sectionParams.Width = sql.NullInt32{Int32: int32(content.Width), Valid: content.Width != 0}
-			sectionParams.Height = sql.NullInt32{Int32: int32(content.Height), Valid: content.Height != 0}
-			sectionParams.ObjectKey = uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid}
-			sectionParams.AltText = sql.NullString{String: content.AltText, Valid: content.AltText != ""}
-			sectionParams.FallbackUrl = sql.NullString{String: content.FallbackURL, Valid: content.FallbackURL != ""}
-			sectionParams.Autoplay = content.Autoplay
-			sectionParams.Loop = content.Loop
-
-			if content.Speed != 0 {
-				sectionParams.Speed = float64(content.Speed)
-			} else {
-				sectionParams.Speed = 1.0
-			}
-
-			err = qtx.InsertLottieSection(ctx, sectionParams)
-			if err != nil {
-				log.WithError(err).Error("failed to insert lottie section")
-				return nil, fmt.Errorf("failed to insert lottie section: %w", err)
-			}
-
-		case "image":
-			var content models.ImageContent
-			if err := json.Unmarshal(section.Content, &content); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal image content: %w", err)
-			}
-			err = qtx.InsertImageSection(ctx, gen.InsertImageSectionParams{
-				SectionID: createdSection.ID,
-				ObjectKey: uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
-				MediaExt:  sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
-				Url:       sql.NullString{String: content.URL, Valid: content.URL != ""},
-				Headline:  sql.NullString{String: content.Headline, Valid: content.Headline != ""},
-				Caption:   sql.NullString{String: content.Caption, Valid: content.Caption != ""},
-				AltText:   sql.NullString{String: content.AltText, Valid: content.AltText != ""},
-				Width:     int32(content.Width),
-				Height:    int32(content.Height),
-			})
-			if err != nil {
-				log.WithError(err).Error("failed to insert image section")
-				return nil, fmt.Errorf("failed to insert image section: %w", err)
+		if err := qtx.SetSectionPosition(ctx, gen.SetSectionPositionParams{
+			Position:  int32(position),
+			SectionID: existing[i].ID,
+		}); err != nil {
+			log.WithError(err).Error("failed to set section position")
+			return nil, fmt.Errorf("failed to set section position: %w", err)
+		}
+		if err := updateSectionContent(ctx, qtx, existing[i], section.Content); err != nil {
+			if !isInvalidContent(err) {
+				log.WithError(err).Error(err.Error())
 			}
-		default:
-			return nil, fmt.Errorf("unsupported section type: %s", section.Type)
+			return nil, err
 		}
-
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -881,3 +1124,798 @@ func (s *moduleService) CreateModuleWithContent(ctx context.Context, unitID int6
 		Sections:        make([]models.SectionInterface, 0),
 	}, nil
 }
+
+// PatchSections applies a section patch to a module in one transaction and
+// returns the module's sections in their new order, numbered from 1. Kept
+// sections keep their IDs and the progress recorded against them. Edited
+// questions and exercises are updated in place, so reviews and submissions
+// survive, but answers naming the old options of a question are dropped.
+func (s *moduleService) PatchSections(ctx context.Context, moduleID int64, patch models.SectionPatch) ([]models.Section, error) {
+	log := s.log.WithBaseFields(logger.Service, "PatchSections")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	if _, err := qtx.LockModule(ctx, int32(moduleID)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to lock module")
+		return nil, fmt.Errorf("failed to lock module: %w", err)
+	}
+
+	existing, err := qtx.GetModuleSections(ctx, int32(moduleID))
+	if err != nil {
+		log.WithError(err).Error("failed to get module sections")
+		return nil, fmt.Errorf("failed to get module sections: %w", err)
+	}
+
+	sections, err := checkSectionPatch(existing, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range patch.Remove {
+		// Questions are not owned by their sections, so they are deleted first.
+		if err := qtx.DeleteSectionQuestion(ctx, int32(id)); err != nil {
+			log.WithError(err).Error("failed to delete section question")
+			return nil, fmt.Errorf("failed to delete section question: %w", err)
+		}
+		if err := qtx.DeleteSection(ctx, int32(id)); err != nil {
+			log.WithError(err).Error("failed to delete section")
+			return nil, fmt.Errorf("failed to delete section: %w", err)
+		}
+	}
+
+	// Positions are unique within a module, so the kept sections are moved
+	// out of the way before they are renumbered.
+	if err := qtx.ClearSectionPositions(ctx, int32(moduleID)); err != nil {
+		log.WithError(err).Error("failed to clear section positions")
+		return nil, fmt.Errorf("failed to clear section positions: %w", err)
+	}
+
+	for i, edit := range patch.Sections {
+		position := int16(i + 1)
+
+		if edit.ID == 0 {
+			section := models.Section{Type: edit.Type, Position: position, Content: edit.Content}
+			if _, err := insertSections(ctx, qtx, int32(moduleID), []models.Section{section}); err != nil {
+				if !isInvalidContent(err) {
+					log.WithError(err).Error(err.Error())
+				}
+				return nil, err
+			}
+			continue
+		}
+
+		if err := qtx.SetSectionPosition(ctx, gen.SetSectionPositionParams{
+			Position:  int32(position),
+			SectionID: int32(edit.ID),
+		}); err != nil {
+			log.WithError(err).Error("failed to set section position")
+			return nil, fmt.Errorf("failed to set section position: %w", err)
+		}
+
+		if hasContent(edit.Content) {
+			if err := updateSectionContent(ctx, qtx, sections[edit.ID], edit.Content); err != nil {
+				if !isInvalidContent(err) {
+					log.WithError(err).Error(err.Error())
+				}
+				return nil, fmt.Errorf("section %d: %w", edit.ID, err)
+			}
+		}
+	}
+
+	result, err := moduleSections(ctx, qtx, int32(moduleID))
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// ReorderModules numbers the modules of a unit from 1 in the order of
+// moduleIDs, which has to list every module of the unit. Modules of other
+// units of the course are moved into the unit, and the units they leave are
+// renumbered to close the gap.
+func (s *moduleService) ReorderModules(ctx context.Context, courseID, unitID int64, moduleIDs []int64) ([]models.Module, error) {
+	log := s.log.WithBaseFields(logger.Service, "ReorderModules")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	// Modules can move between the units of a course, so the whole course is
+	// locked rather than the unit.
+	if err := qtx.LockCourse(ctx, int32(courseID)); err != nil {
+		log.WithError(err).Error("failed to lock course")
+		return nil, fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	unit, err := qtx.GetUnitByID(ctx, int32(unitID))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).Error("failed to get unit")
+		return nil, fmt.Errorf("failed to get unit: %w", err)
+	}
+	if err != nil || int64(unit.CourseID) != courseID {
+		return nil, httperr.ErrNotFound
+	}
+
+	modules, err := qtx.GetCourseModules(ctx, int32(courseID))
+	if err != nil {
+		log.WithError(err).Error("failed to get course modules")
+		return nil, fmt.Errorf("failed to get course modules: %w", err)
+	}
+
+	units := map[int32]bool{unit.ID: true}
+	listed := make(map[int64]bool, len(moduleIDs))
+	var last int32
+	for _, id := range moduleIDs {
+		i := slices.IndexFunc(modules, func(m gen.GetCourseModulesRow) bool { return int64(m.ID) == id })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: module %d is not in the course", ErrInvalidOrder, id)
+		}
+		if listed[id] {
+			return nil, fmt.Errorf("%w: module %d is listed twice", ErrInvalidOrder, id)
+		}
+		listed[id] = true
+		units[modules[i].UnitID] = true
+	}
+	for _, module := range modules {
+		if module.UnitID == unit.ID && !listed[int64(module.ID)] {
+			return nil, fmt.Errorf("%w: module %d is missing", ErrInvalidOrder, module.ID)
+		}
+		last = max(last, module.ModuleNumber)
+	}
+
+	// Module numbers are unique within a unit and have to be positive, so the
+	// modules of every unit involved are first moved past both their old and
+	// their new numbers.
+	for id := range units {
+		if err := qtx.ShiftModuleNumbers(ctx, gen.ShiftModuleNumbersParams{
+			Offset: last + int32(len(modules)),
+			UnitID: id,
+		}); err != nil {
+			log.WithError(err).Error("failed to shift module numbers")
+			return nil, fmt.Errorf("failed to shift module numbers: %w", err)
+		}
+	}
+
+	for i, id := range moduleIDs {
+		if err := qtx.SetModuleNumber(ctx, gen.SetModuleNumberParams{
+			UnitID:       unit.ID,
+			ModuleNumber: int32(i + 1),
+			ModuleID:     int32(id),
+		}); err != nil {
+			log.WithError(err).Error("failed to set module number")
+			return nil, fmt.Errorf("failed to set module number: %w", err)
+		}
+	}
+
+	// The modules left behind keep their order.
+	numbers := map[int32]int32{}
+	for _, module := range modules {
+		if module.UnitID == unit.ID || listed[int64(module.ID)] || !units[module.UnitID] {
+			continue
+		}
+		numbers[module.UnitID]++
+		if err := qtx.SetModuleNumber(ctx, gen.SetModuleNumberParams{
+			UnitID:       module.UnitID,
+			ModuleNumber: numbers[module.UnitID],
+			ModuleID:     module.ID,
+		}); err != nil {
+			log.WithError(err).Error("failed to set module number")
+			return nil, fmt.Errorf("failed to set module number: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetModulesByUnitID(ctx, unitID)
+}
+
+// ReorderSections numbers the sections of a module from 1 in the order of
+// sectionIDs, which has to list every section of the module.
+func (s *moduleService) ReorderSections(ctx context.Context, moduleID int64, sectionIDs []int64) ([]models.Section, error) {
+	log := s.log.WithBaseFields(logger.Service, "ReorderSections")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	if _, err := qtx.LockModule(ctx, int32(moduleID)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to lock module")
+		return nil, fmt.Errorf("failed to lock module: %w", err)
+	}
+
+	existing, err := qtx.GetModuleSections(ctx, int32(moduleID))
+	if err != nil {
+		log.WithError(err).Error("failed to get module sections")
+		return nil, fmt.Errorf("failed to get module sections: %w", err)
+	}
+
+	current := make([]int32, len(existing))
+	for i, section := range existing {
+		current[i] = section.ID
+	}
+	if err := checkOrder("section", "module", current, sectionIDs); err != nil {
+		return nil, err
+	}
+
+	if err := qtx.ClearSectionPositions(ctx, int32(moduleID)); err != nil {
+		log.WithError(err).Error("failed to clear section positions")
+		return nil, fmt.Errorf("failed to clear section positions: %w", err)
+	}
+
+	for i, id := range sectionIDs {
+		if err := qtx.SetSectionPosition(ctx, gen.SetSectionPositionParams{
+			Position:  int32(i + 1),
+			SectionID: int32(id),
+		}); err != nil {
+			log.WithError(err).Error("failed to set section position")
+			return nil, fmt.Errorf("failed to set section position: %w", err)
+		}
+	}
+
+	result, err := moduleSections(ctx, qtx, int32(moduleID))
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// moduleSections returns the sections of a module with their content, in
+// order.
+func moduleSections(ctx context.Context, qtx *gen.Queries, moduleID int32) ([]models.Section, error) {
+	sections, err := qtx.GetModuleSections(ctx, moduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module sections: %w", err)
+	}
+
+	result := make([]models.Section, len(sections))
+	for i, section := range sections {
+		content, err := sectionContent(ctx, qtx, section, true)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = models.Section{
+			ID:        int64(section.ID),
+			CreatedAt: section.CreatedAt,
+			UpdatedAt: section.UpdatedAt,
+			Type:      models.SectionType(section.Type),
+			Position:  int16(section.Position),
+			Content:   content,
+		}
+	}
+
+	return result, nil
+}
+
+// checkSectionPatch checks that a patch keeps or removes each of the existing
+// sections exactly once and only adds sections of known types. It returns the
+// existing sections by ID.
+func checkSectionPatch(existing []gen.Section, patch models.SectionPatch) (map[int64]gen.Section, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidSectionPatch, fmt.Sprintf(format, args...))
+	}
+
+	sections := make(map[int64]gen.Section, len(existing))
+	for _, section := range existing {
+		sections[int64(section.ID)] = section
+	}
+
+	seen := map[int64]bool{}
+	for _, edit := range patch.Sections {
+		if edit.ID == 0 {
+			if !slices.Contains(sectionTypes, edit.Type) {
+				return nil, invalid("unknown section type %q", edit.Type)
+			}
+			if !hasContent(edit.Content) {
+				return nil, invalid("new %s section has no content", edit.Type)
+			}
+			continue
+		}
+
+		section, ok := sections[edit.ID]
+		if !ok {
+			return nil, invalid("section %d is not in the module", edit.ID)
+		}
+		if seen[edit.ID] {
+			return nil, invalid("section %d is listed twice", edit.ID)
+		}
+		if edit.Type != "" && edit.Type != models.SectionType(section.Type) {
+			return nil, invalid("section %d is a %s section and cannot become a %s section", edit.ID, section.Type, edit.Type)
+		}
+		seen[edit.ID] = true
+	}
+
+	for _, id := range patch.Remove {
+		if _, ok := sections[id]; !ok {
+			return nil, invalid("section %d is not in the module", id)
+		}
+		if seen[id] {
+			return nil, invalid("section %d is both kept and removed", id)
+		}
+		seen[id] = true
+	}
+
+	for _, section := range existing {
+		if !seen[int64(section.ID)] {
+			return nil, invalid("section %d is neither kept nor removed", section.ID)
+		}
+	}
+
+	return sections, nil
+}
+
+func hasContent(content json.RawMessage) bool {
+	return len(content) > 0 && string(content) != "null"
+}
+
+func isInvalidContent(err error) bool {
+	return errors.Is(err, ErrInvalidSectionContent) ||
+		errors.Is(err, ErrInvalidQuestion) ||
+		errors.Is(err, ErrInvalidExercise)
+}
+
+// updateSectionContent replaces the content of a section. Questions and
+// exercises are updated rather than recreated so that reviews, answers and
+// submissions stay attached to them.
+func updateSectionContent(ctx context.Context, qtx *gen.Queries, section gen.Section, raw json.RawMessage) error {
+	switch section.Type {
+	case gen.SectionTypeQuestion:
+		var content models.QuestionContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad question content: %v", ErrInvalidSectionContent, err)
+		}
+		if err := content.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidQuestion, err)
+		}
+
+		answerKey, err := json.Marshal(content.AnswerKey())
+		if err != nil {
+			return fmt.Errorf("failed to marshal question answer key: %w", err)
+		}
+
+		question, err := qtx.GetSectionQuestion(ctx, section.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get question section: %w", err)
+		}
+
+		if err := qtx.UpdateQuestion(ctx, gen.UpdateQuestionParams{
+			Type:         gen.QuestionType(content.Type),
+			Question:     content.Question,
+			AnswerSchema: answerKey,
+			Explanation:  content.Explanation,
+			QuestionID:   question.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update question: %w", err)
+		}
+
+		if err := qtx.UpdateQuestionSection(ctx, gen.UpdateQuestionSectionParams{
+			ObjectKey: uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
+			MediaExt:  sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
+			SectionID: section.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update question section: %w", err)
+		}
+
+		if err := updateQuestionOptions(ctx, qtx, question.ID, &content); err != nil {
+			return err
+		}
+		if err := qtx.DeleteQuestionTags(ctx, question.ID); err != nil {
+			return fmt.Errorf("failed to delete question tags: %w", err)
+		}
+
+		return insertQuestionTags(ctx, qtx, question.ID, content.Tags)
+
+	case gen.SectionTypeExercise:
+		var content models.ExerciseContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad exercise content: %v", ErrInvalidSectionContent, err)
+		}
+		content.ApplyDefaults()
+		if err := content.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidExercise, err)
+		}
+
+		if err := qtx.UpdateExerciseSection(ctx, gen.UpdateExerciseSectionParams{
+			Prompt:        content.Prompt,
+			Language:      content.Language,
+			StarterCode:   content.StarterCode,
+			TimeLimitMs:   content.TimeLimitMs,
+			MemoryLimitKb: content.MemoryLimitKb,
+			ObjectKey:     uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
+			MediaExt:      sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
+			SectionID:     section.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update exercise section: %w", err)
+		}
+
+		if err := qtx.DeleteExerciseTestCases(ctx, section.ID); err != nil {
+			return fmt.Errorf("failed to delete exercise test cases: %w", err)
+		}
+
+		return insertExerciseTestCases(ctx, qtx, section.ID, content.TestCases)
+
+	default:
+		if err := qtx.DeleteSectionContent(ctx, section.ID); err != nil {
+			return fmt.Errorf("failed to delete section content: %w", err)
+		}
+		return insertSectionContent(ctx, qtx, section.ID, models.SectionType(section.Type), raw)
+	}
+}
+
+// insertSections inserts the sections of a module along with their content
+// and returns their IDs in order. Content that cannot be read is rejected with
+// ErrInvalidSectionContent, and question and exercise content is validated
+// first and rejected with ErrInvalidQuestion or ErrInvalidExercise.
+func insertSections(ctx context.Context, qtx *gen.Queries, moduleID int32, sections []models.Section) ([]int32, error) {
+	ids := make([]int32, 0, len(sections))
+	for _, section := range sections {
+		createdSection, err := qtx.InsertSection(ctx, gen.InsertSectionParams{
+			ModuleID:    moduleID,
+			SectionType: gen.SectionType(section.Type),
+			Position:    int32(section.Position),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert section: %w", err)
+		}
+		ids = append(ids, createdSection.ID)
+
+		if err := insertSectionContent(ctx, qtx, createdSection.ID, section.Type, section.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+// insertSectionContent inserts the content of a section of type sectionType.
+func insertSectionContent(ctx context.Context, qtx *gen.Queries, sectionID int32, sectionType models.SectionType, raw json.RawMessage) error {
+	var err error
+
+	switch sectionType {
+	case "markdown":
+		var content models.MarkdownContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad text content: %v", ErrInvalidSectionContent, err)
+		}
+		err = qtx.InsertMarkdownSection(ctx, gen.InsertMarkdownSectionParams{
+			SectionID: sectionID,
+			Markdown:  content.Markdown,
+			ObjectKey: uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
+			MediaExt:  sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert text section: %w", err)
+		}
+
+	case "code":
+		var content models.CodeContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad code content: %v", ErrInvalidSectionContent, err)
+		}
+
+		sectionParams := gen.InsertCodeSectionParams{
+			SectionID: sectionID,
+			Code:      content.Code,
+			ObjectKey: uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
+			MediaExt:  sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
+		}
+
+		if content.Language != "" {
+			sectionParams.Language = sql.NullString{String: content.Language, Valid: true}
+		}
+
+		err = qtx.InsertCodeSection(ctx, sectionParams)
+		if err != nil {
+			return fmt.Errorf("failed to insert code section: %w", err)
+		}
+
+	case "question":
+		var content models.QuestionContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad question content: %v", ErrInvalidSectionContent, err)
+		}
+		if err := content.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidQuestion, err)
+		}
+
+		answerKey, err := json.Marshal(content.AnswerKey())
+		if err != nil {
+			return fmt.Errorf("failed to marshal question answer key: %w", err)
+		}
+
+		question, err := qtx.InsertQuestion(ctx, gen.InsertQuestionParams{
+			Type:            gen.QuestionType(content.Type),
+			Question:        content.Question,
+			DifficultyLevel: gen.NullDifficultyLevel{DifficultyLevel: "beginner", Valid: true},
+			AnswerSchema:    answerKey,
+			Explanation:     content.Explanation,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert question: %w", err)
+		}
+
+		err = qtx.InsertQuestionSection(ctx, gen.InsertQuestionSectionParams{
+			SectionID:  sectionID,
+			QuestionID: question.ID,
+			ObjectKey:  uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
+			MediaExt:   sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert question section: %w", err)
+		}
+
+		if err := insertQuestionOptions(ctx, qtx, question.ID, &content); err != nil {
+			return err
+		}
+
+	case "exercise":
+		var content models.ExerciseContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad exercise content: %v", ErrInvalidSectionContent, err)
+		}
+		content.ApplyDefaults()
+		if err := content.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidExercise, err)
+		}
+
+		err = qtx.InsertExerciseSection(ctx, gen.InsertExerciseSectionParams{
+			SectionID:     sectionID,
+			Prompt:        content.Prompt,
+			Language:      content.Language,
+			StarterCode:   content.StarterCode,
+			TimeLimitMs:   content.TimeLimitMs,
+			MemoryLimitKb: content.MemoryLimitKb,
+			ObjectKey:     uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
+			MediaExt:      sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert exercise section: %w", err)
+		}
+
+		if err := insertExerciseTestCases(ctx, qtx, sectionID, content.TestCases); err != nil {
+			return err
+		}
+
+	case "video":
+		var content models.VideoContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad video content: %v", ErrInvalidSectionContent, err)
+		}
+		err = qtx.InsertVideoSection(ctx, gen.InsertVideoSectionParams{
+			SectionID: sectionID,
+			Url:       content.URL,
+			ObjectKey: uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
+			MediaExt:  sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert video section: %w", err)
+		}
+
+	case "lottie":
+		var content models.LottieContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad lottie content: %v", ErrInvalidSectionContent, err)
+		}
+
+		sectionParams := gen.InsertLottieSectionParams{
+			SectionID: sectionID,
+		}
+		sectionParams.Caption = sql.NullString{String: content.Caption, Valid: content.Caption != ""}
+		sectionParams.Description = sql.NullString{String: content.Description, Valid: content.Description != ""}
+		sectionParams.Width = sql.NullInt32{Int32: int32(content.Width), Valid: content.Width != 0}
+		sectionParams.Height = sql.NullInt32{Int32: int32(content.Height), Valid: content.Height != 0}
+		sectionParams.ObjectKey = uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid}
+		sectionParams.AltText = sql.NullString{String: content.AltText, Valid: content.AltText != ""}
+		sectionParams.FallbackUrl = sql.NullString{String: content.FallbackURL, Valid: content.FallbackURL != ""}
+		sectionParams.Autoplay = content.Autoplay
+		sectionParams.Loop = content.Loop
+
+		if content.Speed != 0 {
+			sectionParams.Speed = float64(content.Speed)
+		} else {
+			sectionParams.Speed = 1.0
+		}
+
+		err = qtx.InsertLottieSection(ctx, sectionParams)
+		if err != nil {
+			return fmt.Errorf("failed to insert lottie section: %w", err)
+		}
+
+	case "image":
+		var content models.ImageContent
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("%w: bad image content: %v", ErrInvalidSectionContent, err)
+		}
+		err = qtx.InsertImageSection(ctx, gen.InsertImageSectionParams{
+			SectionID: sectionID,
+			ObjectKey: uuid.NullUUID{UUID: content.ObjectKey.UUID, Valid: content.ObjectKey.Valid},
+			MediaExt:  sql.NullString{String: content.MediaExt, Valid: content.MediaExt != ""},
+			Url:       sql.NullString{String: content.URL, Valid: content.URL != ""},
+			Headline:  sql.NullString{String: content.Headline, Valid: content.Headline != ""},
+			Caption:   sql.NullString{String: content.Caption, Valid: content.Caption != ""},
+			AltText:   sql.NullString{String: content.AltText, Valid: content.AltText != ""},
+			Width:     int32(content.Width),
+			Height:    int32(content.Height),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert image section: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported section type: %s", sectionType)
+	}
+
+	return nil
+}
+
+// insertQuestionOptions inserts the options and tags of a question.
+func insertQuestionOptions(ctx context.Context, qtx *gen.Queries, questionID int32, content *models.QuestionContent) error {
+	positions := make([]int, len(content.Options))
+	for i := range positions {
+		positions[i] = i
+	}
+	if err := insertOptions(ctx, qtx, questionID, content, positions); err != nil {
+		return err
+	}
+
+	return insertQuestionTags(ctx, qtx, questionID, content.Tags)
+}
+
+// updateQuestionOptions updates the options of a question to those of
+// content in place, so answers that chose an option stay attached to it.
+// Options are matched by ID, or by their content when they come without one
+// as they do from markdown. Options that are not matched are deleted along
+// with the answers that chose them.
+func updateQuestionOptions(ctx context.Context, qtx *gen.Queries, questionID int32, content *models.QuestionContent) error {
+	existing, err := qtx.GetQuestionOptions(ctx, questionID)
+	if err != nil {
+		return fmt.Errorf("failed to get question options: %w", err)
+	}
+
+	matched := make(map[int32]bool, len(existing))
+	var added []int
+	for position, opt := range content.Options {
+		match := -1
+		for i, old := range existing {
+			if matched[old.ID] {
+				continue
+			}
+			if (opt.ID != 0 && int64(old.ID) == opt.ID) || (opt.ID == 0 && old.Content == opt.Content) {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			added = append(added, position)
+			continue
+		}
+
+		matched[existing[match].ID] = true
+		if err := qtx.UpdateQuestionOption(ctx, gen.UpdateQuestionOptionParams{
+			Content:   opt.Content,
+			IsCorrect: opt.IsCorrect,
+			Feedback:  opt.Feedback,
+			Position:  int16(position),
+			OptionID:  existing[match].ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update question option: %w", err)
+		}
+	}
+
+	for _, old := range existing {
+		if matched[old.ID] {
+			continue
+		}
+		if err := qtx.DeleteQuestionOption(ctx, old.ID); err != nil {
+			return fmt.Errorf("failed to delete question option: %w", err)
+		}
+	}
+
+	return insertOptions(ctx, qtx, questionID, content, added)
+}
+
+// insertOptions inserts the options of content at positions.
+func insertOptions(ctx context.Context, qtx *gen.Queries, questionID int32, content *models.QuestionContent, positions []int) error {
+	// Option IDs are visible to learners, so the options of an ordering
+	// question are inserted shuffled to keep their IDs from giving away
+	// the order. Position keeps the authored order.
+	insertOrder := positions
+	if content.Type == models.QuestionTypeOrdering {
+		insertOrder = make([]int, len(positions))
+		for i, j := range rand.Perm(len(positions)) {
+			insertOrder[i] = positions[j]
+		}
+	}
+
+	for _, position := range insertOrder {
+		opt := content.Options[position]
+		err := qtx.InsertQuestionOption(ctx, gen.InsertQuestionOptionParams{
+			QuestionID: questionID,
+			Content:    opt.Content,
+			IsCorrect:  opt.IsCorrect,
+			Feedback:   opt.Feedback,
+			Position:   int16(position),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert question option: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// insertQuestionTags adds tags to a question, creating the ones that do not
+// exist yet.
+func insertQuestionTags(ctx context.Context, qtx *gen.Queries, questionID int32, tags []string) error {
+	for _, tagName := range tags {
+		tagID, err := qtx.InsertTag(ctx, tagName)
+		if err != nil {
+			return fmt.Errorf("failed to insert tag: %w", err)
+		}
+
+		err = qtx.InsertQuestionTag(ctx, gen.InsertQuestionTagParams{
+			QuestionID: questionID,
+			TagID:      tagID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert question tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func insertExerciseTestCases(ctx context.Context, qtx *gen.Queries, sectionID int32, tests []models.TestCase) error {
+	for i, test := range tests {
+		err := qtx.InsertExerciseTestCase(ctx, gen.InsertExerciseTestCaseParams{
+			SectionID:      sectionID,
+			Position:       int16(i),
+			Input:          test.Input,
+			ExpectedOutput: test.ExpectedOutput,
+			Hidden:         test.Hidden,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert exercise test case: %w", err)
+		}
+	}
+
+	return nil
+}
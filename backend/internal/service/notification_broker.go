@@ -0,0 +1,156 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// notificationsChannel is the Postgres channel the notification_created
+// trigger publishes to.
+const notificationsChannel = "notifications"
+
+const subscriberBufferSize = 16
+
+// NotificationBroker fans notifications created on any server instance out
+// to the stream subscribers connected to this one.
+type NotificationBroker interface {
+	// Listen blocks, relaying Postgres notifications until ctx is cancelled.
+	Listen(ctx context.Context, connStr string) error
+	// Subscribe registers a subscriber for the user's new notifications. The
+	// returned function must be called to unsubscribe. The channel is closed
+	// if the subscriber falls too far behind, so it can reconnect and replay
+	// what it missed instead of silently losing notifications.
+	Subscribe(userID int32) (<-chan models.Notification, func())
+}
+
+type notificationBroker struct {
+	queries     *gen.Queries
+	log         *logger.Logger
+	mu          sync.RWMutex
+	subscribers map[int32]map[chan models.Notification]struct{}
+}
+
+type notificationEvent struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func NewNotificationBroker(db *sql.DB) NotificationBroker {
+	return &notificationBroker{
+		queries:     gen.New(db),
+		log:         logger.Get(),
+		subscribers: make(map[int32]map[chan models.Notification]struct{}),
+	}
+}
+
+func (b *notificationBroker) Listen(ctx context.Context, connStr string) error {
+	log := b.log.WithBaseFields(logger.Service, "Listen")
+
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.WithError(err).Warn("notification listener connection event")
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(notificationsChannel); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", notificationsChannel, err)
+	}
+
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// nil is sent after a reconnect; anything published meanwhile is
+			// recovered by clients through Last-Event-ID replay
+			if n == nil {
+				continue
+			}
+			b.dispatch(ctx, n.Extra)
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
+
+func (b *notificationBroker) Subscribe(userID int32) (<-chan models.Notification, func()) {
+	ch := make(chan models.Notification, subscriberBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan models.Notification]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[userID], ch)
+			if len(b.subscribers[userID]) == 0 {
+				delete(b.subscribers, userID)
+			}
+			b.mu.Unlock()
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *notificationBroker) dispatch(ctx context.Context, payload string) {
+	log := b.log.WithBaseFields(logger.Service, "dispatch")
+
+	var event notificationEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		log.WithError(err).Error("failed to decode notification event")
+		return
+	}
+
+	b.mu.RLock()
+	subscribed := len(b.subscribers[event.UserID]) > 0
+	b.mu.RUnlock()
+	if !subscribed {
+		return
+	}
+
+	notification, err := b.queries.GetNotificationByID(ctx, event.ID)
+	if err != nil {
+		log.WithError(err).Error("failed to get notification")
+		return
+	}
+
+	result := toNotificationModel(notification)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[event.UserID] {
+		select {
+		case ch <- result:
+		default:
+			log.WithFields(logrus.Fields{
+				"userID":         event.UserID,
+				"notificationID": event.ID,
+			}).Warn("disconnecting slow subscriber")
+			delete(b.subscribers[event.UserID], ch)
+			close(ch)
+		}
+	}
+	if len(b.subscribers[event.UserID]) == 0 {
+		delete(b.subscribers, event.UserID)
+	}
+}
@@ -1,42 +1,202 @@
 package service
 
 import (
-	"algolearn/internal/database"
+	gen "algolearn/internal/database/generated"
+	httperr "algolearn/internal/errors"
 	"algolearn/internal/models"
+	"algolearn/pkg/logger"
 	"context"
 	"database/sql"
 	"fmt"
 )
 
 type NotificationsService interface {
-	GetAllNotifications() ([]models.Notification, error)
+	GetNotifications(ctx context.Context, userID, cursor, limit int32, unreadOnly bool) (*models.NotificationPage, error)
+	GetNotificationsAfter(ctx context.Context, userID, afterID, limit int32) ([]models.Notification, error)
+	GetUnreadCount(ctx context.Context, userID int32) (int64, error)
+	CreateNotification(ctx context.Context, userID int32, content string) (*models.Notification, error)
+	MarkAsRead(ctx context.Context, userID, notificationID int32) error
+	MarkAllAsRead(ctx context.Context, userID int32) (int64, error)
+	DeleteNotification(ctx context.Context, userID, notificationID int32) error
 }
 
 type notificationsService struct {
-	db *database.Database
+	queries *gen.Queries
+	db      *sql.DB
+	log     *logger.Logger
 }
 
 func NewNotificationsService(db *sql.DB) NotificationsService {
-	return &notificationsService{db: database.New(db)}
+	return &notificationsService{
+		queries: gen.New(db),
+		db:      db,
+		log:     logger.Get(),
+	}
+}
+
+// GetNotifications returns a page of the user's notifications, newest first.
+// Pass the previous page's NextCursor as cursor to continue; zero starts from
+// the newest notification.
+func (s *notificationsService) GetNotifications(ctx context.Context, userID, cursor, limit int32, unreadOnly bool) (*models.NotificationPage, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetNotifications")
+
+	// fetch one extra row to know whether another page exists
+	notifications, err := s.queries.GetUserNotifications(ctx, gen.GetUserNotificationsParams{
+		UserID:     userID,
+		Cursor:     cursor,
+		UnreadOnly: unreadOnly,
+		PageLimit:  limit + 1,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to get notifications")
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+
+	unread, err := s.queries.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to count unread notifications")
+		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	page := &models.NotificationPage{
+		Items:       []models.Notification{},
+		UnreadCount: unread,
+	}
+
+	if len(notifications) > int(limit) {
+		notifications = notifications[:limit]
+		next := notifications[len(notifications)-1].ID
+		page.NextCursor = &next
+	}
+
+	for _, n := range notifications {
+		page.Items = append(page.Items, toNotificationModel(n))
+	}
+
+	return page, nil
 }
 
-func (r *notificationsService) GetAllNotifications() ([]models.Notification, error) {
-	ctx := context.Background()
-	notifications, err := r.db.GetAllNotifications(ctx)
+// GetNotificationsAfter returns the user's notifications newer than afterID,
+// oldest first. It is used to replay events a stream client missed.
+func (s *notificationsService) GetNotificationsAfter(ctx context.Context, userID, afterID, limit int32) ([]models.Notification, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetNotificationsAfter")
+
+	notifications, err := s.queries.GetNotificationsAfter(ctx, gen.GetNotificationsAfterParams{
+		UserID:    userID,
+		AfterID:   afterID,
+		PageLimit: limit,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get notifications: %v", err)
+		log.WithError(err).Error("failed to get notifications")
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
 	}
 
 	result := make([]models.Notification, len(notifications))
 	for i, n := range notifications {
-		result[i] = models.Notification{
-			ID:        n.ID,
-			UserID:    int64(n.UserID),
-			Content:   n.Content,
-			Read:      n.Read,
-			CreatedAt: n.CreatedAt,
-		}
+		result[i] = toNotificationModel(n)
 	}
 
 	return result, nil
 }
+
+func (s *notificationsService) GetUnreadCount(ctx context.Context, userID int32) (int64, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetUnreadCount")
+
+	count, err := s.queries.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to count unread notifications")
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreateNotification adds a notification to the user's inbox. Services that
+// need the notification to commit together with their own changes should use
+// notifyUser with their transaction instead.
+func (s *notificationsService) CreateNotification(ctx context.Context, userID int32, content string) (*models.Notification, error) {
+	log := s.log.WithBaseFields(logger.Service, "CreateNotification")
+
+	notification, err := notifyUser(ctx, s.queries, userID, content)
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	result := toNotificationModel(notification)
+	return &result, nil
+}
+
+func (s *notificationsService) MarkAsRead(ctx context.Context, userID, notificationID int32) error {
+	log := s.log.WithBaseFields(logger.Service, "MarkAsRead")
+
+	updated, err := s.queries.MarkNotificationRead(ctx, gen.MarkNotificationReadParams{
+		ID:     notificationID,
+		UserID: userID,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to mark notification as read")
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+
+	if updated == 0 {
+		return httperr.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *notificationsService) MarkAllAsRead(ctx context.Context, userID int32) (int64, error) {
+	log := s.log.WithBaseFields(logger.Service, "MarkAllAsRead")
+
+	updated, err := s.queries.MarkAllNotificationsRead(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to mark all notifications as read")
+		return 0, fmt.Errorf("failed to mark all notifications as read: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (s *notificationsService) DeleteNotification(ctx context.Context, userID, notificationID int32) error {
+	log := s.log.WithBaseFields(logger.Service, "DeleteNotification")
+
+	deleted, err := s.queries.DeleteNotification(ctx, gen.DeleteNotificationParams{
+		ID:     notificationID,
+		UserID: userID,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to delete notification")
+		return fmt.Errorf("failed to delete notification: %w", err)
+	}
+
+	if deleted == 0 {
+		return httperr.ErrNotFound
+	}
+
+	return nil
+}
+
+// notifyUser inserts a notification using the given queries, which may be
+// bound to the caller's transaction.
+func notifyUser(ctx context.Context, qtx *gen.Queries, userID int32, content string) (gen.Notification, error) {
+	notification, err := qtx.CreateNotification(ctx, gen.CreateNotificationParams{
+		UserID:  userID,
+		Content: content,
+	})
+	if err != nil {
+		return gen.Notification{}, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return notification, nil
+}
+
+func toNotificationModel(n gen.Notification) models.Notification {
+	return models.Notification{
+		ID:        n.ID,
+		UserID:    int64(n.UserID),
+		Content:   n.Content,
+		Read:      n.Read,
+		CreatedAt: n.CreatedAt,
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/security"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+const oauthStateExpiry = time.Minute * 10 // 10 minutes
+
+var ErrInvalidOAuthState = errors.New("invalid or expired OAuth state")
+
+// OAuthStateService issues the state and PKCE verifier for an OAuth login.
+// The state sent to the provider is a signed token naming a server-side
+// record, so a callback is accepted only once and only for logins we started.
+type OAuthStateService interface {
+	CreateState(ctx context.Context, provider, clientState string, linkUserID int32) (state, verifier string, err error)
+	ConsumeState(ctx context.Context, provider, state string) (*PendingOAuthLogin, error)
+}
+
+// PendingOAuthLogin is what was recorded when an OAuth flow started.
+type PendingOAuthLogin struct {
+	ClientState string
+	Verifier    string
+	// LinkUserID is set when the flow links an identity to this user instead
+	// of signing in.
+	LinkUserID int32
+}
+
+type oauthStateService struct {
+	queries *gen.Queries
+	log     *logger.Logger
+}
+
+func NewOAuthStateService(db *sql.DB) OAuthStateService {
+	return &oauthStateService{
+		queries: gen.New(db),
+		log:     logger.Get(),
+	}
+}
+
+// CreateState starts an OAuth flow. linkUserID is 0 for sign-in flows.
+func (s *oauthStateService) CreateState(ctx context.Context, provider, clientState string, linkUserID int32) (string, string, error) {
+	log := s.log.WithBaseFields(logger.Service, "CreateState")
+
+	// abandoned logins are cleaned up as new ones start
+	if err := s.queries.DeleteExpiredOAuthStates(ctx); err != nil {
+		log.WithError(err).Warn("failed to delete expired OAuth states")
+	}
+
+	id := uuid.New()
+	verifier := oauth2.GenerateVerifier()
+
+	if err := s.queries.CreateOAuthState(ctx, gen.CreateOAuthStateParams{
+		ID:           id,
+		Provider:     provider,
+		CodeVerifier: verifier,
+		ClientState:  clientState,
+		ExpiresAt:    time.Now().Add(oauthStateExpiry),
+		LinkUserID:   sql.NullInt32{Int32: linkUserID, Valid: linkUserID != 0},
+	}); err != nil {
+		log.WithError(err).Error("failed to store OAuth state")
+		return "", "", fmt.Errorf("failed to store OAuth state: %w", err)
+	}
+
+	state, err := security.GenerateActionToken(0, security.PurposeOAuthState, id.String(), oauthStateExpiry)
+	if err != nil {
+		log.WithError(err).Error("failed to sign OAuth state")
+		return "", "", fmt.Errorf("failed to sign OAuth state: %w", err)
+	}
+
+	return state, verifier, nil
+}
+
+// ConsumeState checks that state was issued by CreateState for provider and
+// has not been used, and returns what was recorded for the flow.
+func (s *oauthStateService) ConsumeState(ctx context.Context, provider, state string) (*PendingOAuthLogin, error) {
+	log := s.log.WithBaseFields(logger.Service, "ConsumeState")
+
+	claims, err := security.ValidateActionToken(state, security.PurposeOAuthState)
+	if err != nil {
+		return nil, ErrInvalidOAuthState
+	}
+
+	id, err := uuid.Parse(claims.Id)
+	if err != nil {
+		return nil, ErrInvalidOAuthState
+	}
+
+	row, err := s.queries.ConsumeOAuthState(ctx, gen.ConsumeOAuthStateParams{
+		ID:       id,
+		Provider: provider,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidOAuthState
+		}
+		log.WithError(err).Error("failed to consume OAuth state")
+		return nil, fmt.Errorf("failed to consume OAuth state: %w", err)
+	}
+
+	return &PendingOAuthLogin{
+		ClientState: row.ClientState,
+		Verifier:    row.CodeVerifier,
+		LinkUserID:  row.LinkUserID.Int32,
+	}, nil
+}
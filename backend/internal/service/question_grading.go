@@ -0,0 +1,231 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidQuestion is returned when question content fails validation
+	// for its type.
+	ErrInvalidQuestion = errors.New("invalid question")
+	// ErrInvalidAnswer is returned when an answer does not have the shape its
+	// question type expects, or refers to options of another question.
+	ErrInvalidAnswer = errors.New("invalid answer")
+	// ErrQuestionNotInModule is returned when progress is saved for a question
+	// that is not part of the module.
+	ErrQuestionNotInModule = errors.New("question is not part of the module")
+)
+
+// numericEpsilon absorbs floating point error when comparing a numeric answer
+// against the edge of its tolerance.
+const numericEpsilon = 1e-9
+
+// gradeQuestionAnswer grades a learner's answer against the stored question
+// and returns the row to save for it together with the result to show the
+// learner, minus the attempt count.
+func gradeQuestionAnswer(ctx context.Context, qtx *gen.Queries, moduleID int64, progress models.QuestionProgress) (gen.UpsertQuestionAnswerParams, models.QuestionResult, error) {
+	var params gen.UpsertQuestionAnswerParams
+	var result models.QuestionResult
+
+	answer := progress.Answer
+	if answer == nil {
+		if progress.OptionID == nil {
+			return params, result, fmt.Errorf("%w: question %d has no answer", ErrInvalidAnswer, progress.QuestionID)
+		}
+		answer = &models.QuestionAnswer{OptionID: progress.OptionID}
+	}
+
+	question, err := qtx.GetModuleQuestion(ctx, gen.GetModuleQuestionParams{
+		QuestionID: int32(progress.QuestionID),
+		ModuleID:   int32(moduleID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return params, result, fmt.Errorf("%w: question %d", ErrQuestionNotInModule, progress.QuestionID)
+		}
+		return params, result, fmt.Errorf("failed to get question: %w", err)
+	}
+
+	var key models.QuestionAnswerKey
+	if err := json.Unmarshal(question.AnswerSchema, &key); err != nil {
+		return params, result, fmt.Errorf("failed to unmarshal question answer key: %w", err)
+	}
+
+	options, err := qtx.GetQuestionOptions(ctx, question.ID)
+	if err != nil {
+		return params, result, fmt.Errorf("failed to get question options: %w", err)
+	}
+
+	correct, err := gradeAnswer(models.QuestionType(question.Type), key, options, *answer)
+	if err != nil {
+		return params, result, fmt.Errorf("question %d: %w", progress.QuestionID, err)
+	}
+
+	answerJSON, err := json.Marshal(answer)
+	if err != nil {
+		return params, result, fmt.Errorf("failed to marshal answer: %w", err)
+	}
+
+	params = gen.UpsertQuestionAnswerParams{
+		QuestionID: question.ID,
+		Answer:     answerJSON,
+		IsCorrect:  correct,
+	}
+	if answer.OptionID != nil {
+		params.OptionID = sql.NullInt32{Int32: int32(*answer.OptionID), Valid: true}
+	}
+
+	result = models.QuestionResult{
+		QuestionID:  int64(question.ID),
+		IsCorrect:   correct,
+		Explanation: question.Explanation,
+		Feedback:    optionFeedback(options, *answer),
+	}
+	return params, result, nil
+}
+
+// optionFeedback returns the feedback of the options chosen in answer, for
+// those that have any.
+func optionFeedback(options []gen.QuestionOption, answer models.QuestionAnswer) []models.OptionFeedback {
+	chosen := answer.OptionIDs
+	if answer.OptionID != nil {
+		chosen = append([]int64{*answer.OptionID}, chosen...)
+	}
+
+	var feedback []models.OptionFeedback
+	for _, id := range chosen {
+		for _, opt := range options {
+			if int64(opt.ID) == id && opt.Feedback != "" {
+				feedback = append(feedback, models.OptionFeedback{OptionID: id, Feedback: opt.Feedback})
+			}
+		}
+	}
+	return feedback
+}
+
+// gradeAnswer reports whether answer is correct for a question of the given
+// type, options (sorted by position) and answer key.
+func gradeAnswer(questionType models.QuestionType, key models.QuestionAnswerKey, options []gen.QuestionOption, answer models.QuestionAnswer) (bool, error) {
+	switch questionType {
+	case models.QuestionTypeMultipleChoice, models.QuestionTypeTrueFalse:
+		if answer.OptionID == nil {
+			return false, fmt.Errorf("%w: an option is required", ErrInvalidAnswer)
+		}
+		for _, opt := range options {
+			if int64(opt.ID) == *answer.OptionID {
+				return opt.IsCorrect, nil
+			}
+		}
+		return false, fmt.Errorf("%w: unknown option %d", ErrInvalidAnswer, *answer.OptionID)
+
+	case models.QuestionTypeMultiSelect:
+		chosen, err := chosenOptions(options, answer.OptionIDs)
+		if err != nil {
+			return false, err
+		}
+		for _, opt := range options {
+			if opt.IsCorrect != chosen[int64(opt.ID)] {
+				return false, nil
+			}
+		}
+		return true, nil
+
+	case models.QuestionTypeOrdering:
+		if _, err := chosenOptions(options, answer.OptionIDs); err != nil {
+			return false, err
+		}
+		if len(answer.OptionIDs) != len(options) {
+			return false, fmt.Errorf("%w: every option must be ordered", ErrInvalidAnswer)
+		}
+		for i, opt := range options {
+			if int64(opt.ID) != answer.OptionIDs[i] {
+				return false, nil
+			}
+		}
+		return true, nil
+
+	case models.QuestionTypeFillBlank:
+		if len(answer.Blanks) != len(key.Blanks) {
+			return false, fmt.Errorf("%w: expected %d blanks, got %d", ErrInvalidAnswer, len(key.Blanks), len(answer.Blanks))
+		}
+		for i, blank := range key.Blanks {
+			if !matchesBlank(blank, answer.Blanks[i]) {
+				return false, nil
+			}
+		}
+		return true, nil
+
+	case models.QuestionTypeNumeric:
+		if answer.Value == nil {
+			return false, fmt.Errorf("%w: a value is required", ErrInvalidAnswer)
+		}
+		if key.Numeric == nil {
+			return false, fmt.Errorf("numeric question has no answer key")
+		}
+		return math.Abs(*answer.Value-key.Numeric.Value) <= key.Numeric.Tolerance+numericEpsilon, nil
+
+	default:
+		return false, fmt.Errorf("unknown question type %q", questionType)
+	}
+}
+
+// chosenOptions returns the set of option IDs in ids, checking that each one
+// belongs to the question and is given only once.
+func chosenOptions(options []gen.QuestionOption, ids []int64) (map[int64]bool, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: at least one option is required", ErrInvalidAnswer)
+	}
+
+	known := make(map[int64]bool, len(options))
+	for _, opt := range options {
+		known[int64(opt.ID)] = true
+	}
+
+	chosen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !known[id] {
+			return nil, fmt.Errorf("%w: unknown option %d", ErrInvalidAnswer, id)
+		}
+		if chosen[id] {
+			return nil, fmt.Errorf("%w: option %d given twice", ErrInvalidAnswer, id)
+		}
+		chosen[id] = true
+	}
+	return chosen, nil
+}
+
+// matchesBlank reports whether response fills blank correctly.
+func matchesBlank(blank models.Blank, response string) bool {
+	response = strings.Join(strings.Fields(response), " ")
+
+	for _, accepted := range blank.AcceptedAnswers {
+		accepted = strings.Join(strings.Fields(accepted), " ")
+		if response == accepted || (!blank.CaseSensitive && strings.EqualFold(response, accepted)) {
+			return true
+		}
+	}
+
+	for _, pattern := range blank.Patterns {
+		expr := `^(?:` + pattern + `)$`
+		if !blank.CaseSensitive {
+			expr = `(?i)` + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			continue
+		}
+		if re.MatchString(response) {
+			return true
+		}
+	}
+	return false
+}
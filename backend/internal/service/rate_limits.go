@@ -0,0 +1,70 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/middleware"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RateLimitService is a middleware.RateLimitStore that keeps its buckets in
+// Postgres, so every node enforces the same limits.
+type RateLimitService interface {
+	middleware.RateLimitStore
+	RunPurgeFullRateLimitBucketsJob(ctx context.Context, interval time.Duration)
+}
+
+type rateLimitService struct {
+	queries *gen.Queries
+	db      *sql.DB
+	log     *logger.Logger
+}
+
+func NewRateLimitService(db *sql.DB) RateLimitService {
+	return &rateLimitService{
+		queries: gen.New(db),
+		db:      db,
+		log:     logger.Get(),
+	}
+}
+
+func (s *rateLimitService) Take(ctx context.Context, key string, policy middleware.RateLimitPolicy) (middleware.RateLimitResult, error) {
+	row, err := s.queries.TakeRateLimitToken(ctx, gen.TakeRateLimitTokenParams{
+		Key:        key,
+		Capacity:   float64(policy.Limit),
+		RefillRate: float64(policy.Limit) / policy.Window.Seconds(),
+	})
+	if err != nil {
+		return middleware.RateLimitResult{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	return middleware.RateLimitResult{Allowed: row.Allowed, Tokens: row.Tokens}, nil
+}
+
+// RunPurgeFullRateLimitBucketsJob deletes buckets that have refilled
+// completely on every tick of interval until ctx is cancelled.
+func (s *rateLimitService) RunPurgeFullRateLimitBucketsJob(ctx context.Context, interval time.Duration) {
+	log := s.log.WithBaseFields(logger.Service, "RunPurgeFullRateLimitBucketsJob")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.queries.DeleteFullRateLimitBuckets(ctx)
+			if err != nil {
+				log.WithError(err).Error("failed to purge full rate limit buckets")
+				continue
+			}
+			if purged > 0 {
+				log.Infof("purged %d full rate limit buckets", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// defaultEaseFactor and minEaseFactor are the SM-2 starting and lowest
+	// ease factors.
+	defaultEaseFactor = 2.5
+	minEaseFactor     = 1.3
+	// maxReviewIntervalDays keeps well known questions coming back at least
+	// once a year.
+	maxReviewIntervalDays = 365
+	// failedRecallQuality is the SM-2 quality given to a wrong answer.
+	failedRecallQuality = 1
+)
+
+// ErrReviewNotDue is returned when a question is reviewed before it is due.
+var ErrReviewNotDue = errors.New("question is not due for review yet")
+
+type ReviewService interface {
+	GetDueReviews(ctx context.Context, userID int32, limit int32) (*models.DueReviews, error)
+	ReviewQuestion(ctx context.Context, userID int32, questionID int64, req models.ReviewRequest) (*models.ReviewResult, error)
+}
+
+type reviewService struct {
+	queries *gen.Queries
+	db      *sql.DB
+	log     *logger.Logger
+}
+
+func NewReviewService(db *sql.DB) ReviewService {
+	return &reviewService{
+		queries: gen.New(db),
+		db:      db,
+		log:     logger.Get(),
+	}
+}
+
+// GetDueReviews returns up to limit of the user's questions that are due,
+// most overdue first, together with how many are due in total.
+func (s *reviewService) GetDueReviews(ctx context.Context, userID int32, limit int32) (*models.DueReviews, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetDueReviews")
+
+	rows, err := s.queries.GetDueQuestionReviews(ctx, gen.GetDueQuestionReviewsParams{
+		UserID:   userID,
+		RowLimit: limit,
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to get due reviews")
+		return nil, fmt.Errorf("failed to get due reviews: %w", err)
+	}
+
+	total, err := s.queries.CountDueQuestionReviews(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to count due reviews")
+		return nil, fmt.Errorf("failed to count due reviews: %w", err)
+	}
+
+	reviews := make([]models.DueReview, len(rows))
+	for i, row := range rows {
+		var options []models.Option
+		if err := json.Unmarshal(row.QuestionOptions, &options); err != nil {
+			log.WithError(err).Error("failed to unmarshal question options")
+			return nil, fmt.Errorf("failed to unmarshal question options: %w", err)
+		}
+
+		reviews[i] = models.DueReview{
+			Schedule: models.ReviewSchedule{
+				QuestionID:   int64(row.QuestionID),
+				EaseFactor:   row.EaseFactor,
+				IntervalDays: row.IntervalDays,
+				Repetitions:  row.Repetitions,
+				Lapses:       row.Lapses,
+				DueAt:        row.DueAt,
+			},
+			Question: models.QuestionContent{
+				ID:         int64(row.QuestionID),
+				Question:   row.Question,
+				Type:       models.QuestionType(row.Type),
+				Options:    options,
+				BlankCount: int(row.BlankCount),
+			},
+		}
+	}
+
+	return &models.DueReviews{Reviews: reviews, Total: total}, nil
+}
+
+// ReviewQuestion grades an answer to a question in the user's review queue
+// and schedules its next review. Questions that are not in the queue or are
+// only in trashed content are reported as not found, and questions that are
+// not due yet are refused.
+func (s *reviewService) ReviewQuestion(ctx context.Context, userID int32, questionID int64, req models.ReviewRequest) (*models.ReviewResult, error) {
+	log := s.log.WithBaseFields(logger.Service, "ReviewQuestion")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	review, err := qtx.GetQuestionReviewForUpdate(ctx, gen.GetQuestionReviewForUpdateParams{
+		UserID:     userID,
+		QuestionID: int32(questionID),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get question review")
+		return nil, fmt.Errorf("failed to get question review: %w", err)
+	}
+
+	// Reviewing early would let a card be pushed out of the queue by
+	// answering it again and again.
+	now := time.Now()
+	if review.DueAt.After(now) {
+		return nil, ErrReviewNotDue
+	}
+
+	question, err := qtx.GetQuestionAnswerKey(ctx, int32(questionID))
+	if err != nil {
+		log.WithError(err).Error("failed to get question")
+		return nil, fmt.Errorf("failed to get question: %w", err)
+	}
+
+	var key models.QuestionAnswerKey
+	if err := json.Unmarshal(question.AnswerSchema, &key); err != nil {
+		log.WithError(err).Error("failed to unmarshal question answer key")
+		return nil, fmt.Errorf("failed to unmarshal question answer key: %w", err)
+	}
+
+	options, err := qtx.GetQuestionOptions(ctx, question.ID)
+	if err != nil {
+		log.WithError(err).Error("failed to get question options")
+		return nil, fmt.Errorf("failed to get question options: %w", err)
+	}
+
+	correct, err := gradeAnswer(models.QuestionType(question.Type), key, options, req.Answer)
+	if err != nil {
+		return nil, err
+	}
+
+	quality, err := recallQuality(correct, req.Recall)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err = saveReview(ctx, qtx, scheduleReview(review, quality, now))
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &models.ReviewResult{
+		QuestionID:  questionID,
+		IsCorrect:   correct,
+		Explanation: question.Explanation,
+		Feedback:    optionFeedback(options, req.Answer),
+		Schedule:    reviewSchedule(review),
+	}, nil
+}
+
+// enqueueReview schedules a question the user just answered wrong, adding it
+// to their review queue if it is not there yet. It is meant to run inside the
+// transaction that recorded the answer.
+func enqueueReview(ctx context.Context, qtx *gen.Queries, userID, questionID int32, now time.Time) error {
+	review, err := qtx.GetQuestionReviewForUpdate(ctx, gen.GetQuestionReviewForUpdateParams{
+		UserID:     userID,
+		QuestionID: questionID,
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get question review: %w", err)
+		}
+		review = gen.QuestionReview{
+			UserID:     userID,
+			QuestionID: questionID,
+			EaseFactor: defaultEaseFactor,
+		}
+	}
+
+	_, err = saveReview(ctx, qtx, scheduleReview(review, failedRecallQuality, now))
+	return err
+}
+
+func saveReview(ctx context.Context, qtx *gen.Queries, review gen.QuestionReview) (gen.QuestionReview, error) {
+	saved, err := qtx.UpsertQuestionReview(ctx, gen.UpsertQuestionReviewParams{
+		UserID:       review.UserID,
+		QuestionID:   review.QuestionID,
+		EaseFactor:   review.EaseFactor,
+		IntervalDays: review.IntervalDays,
+		Repetitions:  review.Repetitions,
+		Lapses:       review.Lapses,
+		DueAt:        review.DueAt,
+		ReviewedAt:   review.ReviewedAt,
+	})
+	if err != nil {
+		return saved, fmt.Errorf("failed to save question review: %w", err)
+	}
+	return saved, nil
+}
+
+// recallQuality turns a graded review into an SM-2 quality from 0 to 5.
+func recallQuality(correct bool, recall models.RecallGrade) (int, error) {
+	if !correct {
+		return failedRecallQuality, nil
+	}
+	switch recall {
+	case models.RecallHard:
+		return 3, nil
+	case "", models.RecallGood:
+		return 4, nil
+	case models.RecallEasy:
+		return 5, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown recall grade %q", ErrInvalidAnswer, recall)
+	}
+}
+
+// scheduleReview applies the SM-2 algorithm to a review of the given quality.
+// Recalled questions come back after one day, then six, then the previous
+// interval times the ease factor; forgotten ones start over the next day. The
+// ease factor drops for hard or failed recalls and grows for easy ones.
+func scheduleReview(review gen.QuestionReview, quality int, now time.Time) gen.QuestionReview {
+	if quality < 3 {
+		review.Repetitions = 0
+		review.IntervalDays = 1
+		review.Lapses++
+	} else {
+		switch review.Repetitions {
+		case 0:
+			review.IntervalDays = 1
+		case 1:
+			review.IntervalDays = 6
+		default:
+			review.IntervalDays = int32(math.Round(float64(review.IntervalDays) * review.EaseFactor))
+		}
+		review.IntervalDays = min(review.IntervalDays, maxReviewIntervalDays)
+		review.Repetitions++
+	}
+
+	miss := float64(5 - quality)
+	review.EaseFactor = max(minEaseFactor, review.EaseFactor+0.1-miss*(0.08+miss*0.02))
+	review.DueAt = now.AddDate(0, 0, int(review.IntervalDays))
+	review.ReviewedAt = now
+	return review
+}
+
+func reviewSchedule(review gen.QuestionReview) models.ReviewSchedule {
+	return models.ReviewSchedule{
+		QuestionID:   int64(review.QuestionID),
+		EaseFactor:   review.EaseFactor,
+		IntervalDays: review.IntervalDays,
+		Repetitions:  review.Repetitions,
+		Lapses:       review.Lapses,
+		DueAt:        review.DueAt,
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"algolearn/pkg/security"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+// SessionService manages refresh tokens. Each sign-in starts a token family;
+// refreshing rotates the token within its family, and presenting a token that
+// was already rotated revokes the whole family.
+type SessionService interface {
+	IssueRefreshToken(ctx context.Context, userID int32, mfaVerified bool, device models.DeviceInfo) (string, error)
+	RotateRefreshToken(ctx context.Context, token string, device models.DeviceInfo) (*RotatedRefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, userID int32, token string) error
+	RevokeAllRefreshTokens(ctx context.Context, userID int32) (int64, error)
+	RunPurgeExpiredRefreshTokensJob(ctx context.Context, interval time.Duration)
+}
+
+// RotatedRefreshToken is the replacement token handed out by
+// RotateRefreshToken, along with the session it belongs to.
+type RotatedRefreshToken struct {
+	UserID int32
+	// MFAVerified is carried over from the sign-in that started the family
+	MFAVerified bool
+	Token       string
+}
+
+type sessionService struct {
+	queries *gen.Queries
+	db      *sql.DB
+	log     *logger.Logger
+}
+
+func NewSessionService(db *sql.DB) SessionService {
+	return &sessionService{
+		queries: gen.New(db),
+		db:      db,
+		log:     logger.Get(),
+	}
+}
+
+func (s *sessionService) IssueRefreshToken(ctx context.Context, userID int32, mfaVerified bool, device models.DeviceInfo) (string, error) {
+	log := s.log.WithBaseFields(logger.Service, "IssueRefreshToken")
+
+	token, err := createRefreshToken(ctx, s.queries, userID, uuid.New(), mfaVerified, device)
+	if err != nil {
+		log.WithError(err).Error("failed to issue refresh token")
+		return "", err
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same
+// family. Reusing a token that was already rotated
+// or revoked revokes its family and returns ErrRefreshTokenReused.
+func (s *sessionService) RotateRefreshToken(ctx context.Context, token string, device models.DeviceInfo) (*RotatedRefreshToken, error) {
+	log := s.log.WithBaseFields(logger.Service, "RotateRefreshToken")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	current, err := qtx.GetRefreshTokenByHashForUpdate(ctx, security.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidRefreshToken
+		}
+		log.WithError(err).Error("failed to get refresh token")
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if current.UsedAt.Valid || current.RevokedAt.Valid {
+		revoked, err := qtx.RevokeRefreshTokenFamily(ctx, gen.RevokeRefreshTokenFamilyParams{
+			FamilyID: current.FamilyID,
+			UserID:   current.UserID,
+		})
+		if err != nil {
+			log.WithError(err).Error("failed to revoke refresh token family")
+			return nil, fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			log.WithError(err).Error("failed to commit transaction")
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		log.Warnf("refresh token reuse detected for user %d, revoked %d tokens in family %s",
+			current.UserID, revoked, current.FamilyID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	if current.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err := qtx.MarkRefreshTokenUsed(ctx, current.ID); err != nil {
+		log.WithError(err).Error("failed to mark refresh token as used")
+		return nil, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+
+	newToken, err := createRefreshToken(ctx, qtx, current.UserID, current.FamilyID, current.MfaVerified, device)
+	if err != nil {
+		log.WithError(err).Error("failed to rotate refresh token")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &RotatedRefreshToken{
+		UserID:      current.UserID,
+		MFAVerified: current.MfaVerified,
+		Token:       newToken,
+	}, nil
+}
+
+// RevokeRefreshToken signs the user out of the session the token belongs to.
+func (s *sessionService) RevokeRefreshToken(ctx context.Context, userID int32, token string) error {
+	log := s.log.WithBaseFields(logger.Service, "RevokeRefreshToken")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	current, err := qtx.GetRefreshTokenByHashForUpdate(ctx, security.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidRefreshToken
+		}
+		log.WithError(err).Error("failed to get refresh token")
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if current.UserID != userID {
+		return ErrInvalidRefreshToken
+	}
+
+	if _, err := qtx.RevokeRefreshTokenFamily(ctx, gen.RevokeRefreshTokenFamilyParams{
+		FamilyID: current.FamilyID,
+		UserID:   userID,
+	}); err != nil {
+		log.WithError(err).Error("failed to revoke refresh token family")
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllRefreshTokens signs the user out on every device.
+func (s *sessionService) RevokeAllRefreshTokens(ctx context.Context, userID int32) (int64, error) {
+	log := s.log.WithBaseFields(logger.Service, "RevokeAllRefreshTokens")
+
+	revoked, err := s.queries.RevokeUserRefreshTokens(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to revoke refresh tokens")
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// RunPurgeExpiredRefreshTokensJob deletes expired refresh tokens on every
+// tick of interval until ctx is cancelled.
+func (s *sessionService) RunPurgeExpiredRefreshTokensJob(ctx context.Context, interval time.Duration) {
+	log := s.log.WithBaseFields(logger.Service, "RunPurgeExpiredRefreshTokensJob")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.queries.DeleteExpiredRefreshTokens(ctx)
+			if err != nil {
+				log.WithError(err).Error("failed to purge expired refresh tokens")
+				continue
+			}
+			if purged > 0 {
+				log.Infof("purged %d expired refresh tokens", purged)
+			}
+		}
+	}
+}
+
+func createRefreshToken(ctx context.Context, q *gen.Queries, userID int32, familyID uuid.UUID, mfaVerified bool, device models.DeviceInfo) (string, error) {
+	token, err := security.GenerateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	if _, err := q.CreateRefreshToken(ctx, gen.CreateRefreshTokenParams{
+		UserID:      userID,
+		FamilyID:    familyID,
+		TokenHash:   security.HashToken(token),
+		ExpiresAt:   time.Now().Add(security.RefreshTokenExpiry),
+		DeviceName:  sql.NullString{String: device.DeviceName, Valid: device.DeviceName != ""},
+		UserAgent:   sql.NullString{String: device.UserAgent, Valid: device.UserAgent != ""},
+		IpAddress:   sql.NullString{String: device.IPAddress, Valid: device.IPAddress != ""},
+		MfaVerified: mfaVerified,
+	}); err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return token, nil
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -15,6 +17,30 @@ type StorageService interface {
 	GeneratePresignedPutURL(key string, contentType string, expiry time.Duration) (string, error)
 	CountObjectsInFolder(ctx context.Context, folderName, subFolder string) (int, error)
 	DeleteFromS3(ctx context.Context, FolderName, SubFolder, ObjectKey string) error
+	GetObject(ctx context.Context, key string) (*StorageObject, error)
+	PutObject(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	CopyObject(ctx context.Context, srcKey, dstKey string) error
+	DeleteFolder(ctx context.Context, folderName, subFolder string) error
+	ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	DeleteObjects(ctx context.Context, keys []string) error
+}
+
+// ErrObjectNotFound is returned by GetObject and CopyObject when nothing is
+// stored under the key.
+var ErrObjectNotFound = errors.New("object not found")
+
+// StorageObject is an object being read from storage. It must be closed.
+type StorageObject struct {
+	io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+// ObjectInfo describes an object listed from storage.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
 }
 
 type storageService struct {
@@ -133,3 +159,117 @@ func (s *storageService) DeleteFromS3(
 
 	return nil
 }
+
+func (s *storageService) GetObject(ctx context.Context, key string) (*StorageObject, error) {
+	obj, err := s.s3Client.GetObject(ctx, s.bucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %v", err)
+	}
+
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to stat object: %v", err)
+	}
+
+	return &StorageObject{ReadCloser: obj, ContentType: info.ContentType, Size: info.Size}, nil
+}
+
+func (s *storageService) PutObject(
+	ctx context.Context,
+	key string,
+	r io.Reader,
+	size int64,
+	contentType string,
+) error {
+	_, err := s.s3Client.PutObject(ctx, s.bucketName, key, r, size,
+		minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to put object: %v", err)
+	}
+
+	return nil
+}
+
+// CopyObject copies an object to another key within the bucket without
+// downloading it.
+func (s *storageService) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	_, err := s.s3Client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: s.bucketName, Object: dstKey},
+		minio.CopySrcOptions{Bucket: s.bucketName, Object: srcKey})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return ErrObjectNotFound
+		}
+		return fmt.Errorf("failed to copy object: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteFolder removes every object under folderName/subFolder/, including
+// the folder placeholder.
+func (s *storageService) DeleteFolder(ctx context.Context, folderName, subFolder string) error {
+	prefix := fmt.Sprintf("%s/%s/", folderName, subFolder)
+	objectCh := s.s3Client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	for result := range s.s3Client.RemoveObjects(ctx, s.bucketName, objectCh, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil {
+			return fmt.Errorf("failed to delete %s: %v", result.ObjectName, result.Err)
+		}
+	}
+
+	return nil
+}
+
+// ListObjects lists every object whose key starts with prefix, placeholders
+// included.
+func (s *storageService) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	objectCh := s.s3Client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	var objects []ObjectInfo
+	for object := range objectCh {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %v", object.Err)
+		}
+		objects = append(objects, ObjectInfo{
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: object.LastModified,
+		})
+	}
+
+	return objects, nil
+}
+
+// DeleteObjects removes the objects under keys in bulk requests.
+func (s *storageService) DeleteObjects(ctx context.Context, keys []string) error {
+	objectCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectCh)
+		for _, key := range keys {
+			select {
+			case objectCh <- minio.ObjectInfo{Key: key}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for result := range s.s3Client.RemoveObjects(ctx, s.bucketName, objectCh, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil {
+			return fmt.Errorf("failed to delete %s: %v", result.ObjectName, result.Err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type StreakService interface {
+	GetUserStreak(ctx context.Context, userID int32) (*models.Streak, error)
+	GetLeaderboard(ctx context.Context, limit int32) ([]models.StreakLeaderboardEntry, error)
+	CloseBrokenStreaks(ctx context.Context) (int64, error)
+	RunCloseBrokenStreaksJob(ctx context.Context, interval time.Duration)
+}
+
+type streakService struct {
+	queries *gen.Queries
+	db      *sql.DB
+	log     *logger.Logger
+}
+
+func NewStreakService(db *sql.DB) StreakService {
+	return &streakService{
+		queries: gen.New(db),
+		db:      db,
+		log:     logger.Get(),
+	}
+}
+
+func (s *streakService) GetUserStreak(ctx context.Context, userID int32) (*models.Streak, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetUserStreak")
+
+	longest, err := s.queries.GetLongestStreak(ctx, userID)
+	if err != nil {
+		log.WithError(err).Error("failed to get longest streak")
+		return nil, fmt.Errorf("failed to get longest streak: %w", err)
+	}
+
+	streak, err := s.queries.GetLatestStreak(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.Streak{UserID: int64(userID), LongestStreak: int(longest)}, nil
+		}
+		log.WithError(err).Error("failed to get latest streak")
+		return nil, fmt.Errorf("failed to get latest streak: %w", err)
+	}
+
+	today, err := userLocalDate(ctx, s.queries, userID, time.Now())
+	if err != nil {
+		log.WithError(err).Error(err.Error())
+		return nil, err
+	}
+
+	result := &models.Streak{
+		ID:            streak.ID,
+		UserID:        int64(streak.UserID),
+		StartDate:     streak.StartDate,
+		CurrentStreak: int(streak.CurrentStreak),
+		LongestStreak: int(longest),
+		CreatedAt:     streak.CreatedAt,
+		UpdatedAt:     streak.UpdatedAt,
+	}
+
+	// A streak the scheduled job has not closed yet is still broken if the
+	// user skipped a day.
+	if streak.EndDate.Valid || lastStreakDay(streak).Before(today.AddDate(0, 0, -1)) {
+		result.CurrentStreak = 0
+		result.EndDate = lastStreakDay(streak)
+	}
+
+	return result, nil
+}
+
+func (s *streakService) GetLeaderboard(ctx context.Context, limit int32) ([]models.StreakLeaderboardEntry, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetLeaderboard")
+
+	users, err := s.queries.GetTopUsersByStreak(ctx, limit)
+	if err != nil {
+		log.WithError(err).Error("failed to get top users by streak")
+		return nil, fmt.Errorf("failed to get top users by streak: %w", err)
+	}
+
+	entries := make([]models.StreakLeaderboardEntry, len(users))
+	for i, u := range users {
+		entries[i] = models.StreakLeaderboardEntry{
+			Rank:              i + 1,
+			UserID:            u.ID,
+			Username:          u.Username,
+			ProfilePictureURL: u.ProfilePictureUrl.String,
+			Streak:            u.Streak,
+			CPUs:              u.Cpus,
+		}
+	}
+
+	return entries, nil
+}
+
+// CloseBrokenStreaks ends every open streak whose owner missed a full day in
+// their own timezone and resets the streak shown on their profile.
+func (s *streakService) CloseBrokenStreaks(ctx context.Context) (int64, error) {
+	log := s.log.WithBaseFields(logger.Service, "CloseBrokenStreaks")
+
+	closed, err := s.queries.CloseBrokenStreaks(ctx)
+	if err != nil {
+		log.WithError(err).Error("failed to close broken streaks")
+		return 0, fmt.Errorf("failed to close broken streaks: %w", err)
+	}
+
+	return closed, nil
+}
+
+// RunCloseBrokenStreaksJob calls CloseBrokenStreaks on every tick of interval
+// until ctx is cancelled.
+func (s *streakService) RunCloseBrokenStreaksJob(ctx context.Context, interval time.Duration) {
+	log := s.log.WithBaseFields(logger.Service, "RunCloseBrokenStreaksJob")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			closed, err := s.CloseBrokenStreaks(ctx)
+			if err != nil {
+				continue
+			}
+			if closed > 0 {
+				log.Infof("closed %d broken streaks", closed)
+			}
+		}
+	}
+}
+
+// recordStreakActivity extends the user's streak when they are active on a new
+// calendar day in their timezone, or starts a new one if the previous streak
+// was broken. It is meant to run inside the transaction that recorded the
+// activity.
+func recordStreakActivity(ctx context.Context, qtx *gen.Queries, userID int32, now time.Time) error {
+	today, err := userLocalDate(ctx, qtx, userID, now)
+	if err != nil {
+		return err
+	}
+
+	streak, err := qtx.GetOpenStreakForUpdate(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get open streak: %w", err)
+	}
+
+	hasOpenStreak := err == nil
+	if hasOpenStreak {
+		lastDay := lastStreakDay(streak)
+		switch {
+		case !lastDay.Before(today):
+			// already counted today
+			return nil
+		case lastDay.Equal(today.AddDate(0, 0, -1)):
+			streak, err = qtx.ExtendStreak(ctx, streak.ID)
+			if err != nil {
+				return fmt.Errorf("failed to extend streak: %w", err)
+			}
+		default:
+			if err := qtx.CloseStreak(ctx, gen.CloseStreakParams{EndDate: lastDay, ID: streak.ID}); err != nil {
+				return fmt.Errorf("failed to close streak: %w", err)
+			}
+			hasOpenStreak = false
+		}
+	}
+
+	if !hasOpenStreak {
+		longest, err := qtx.GetLongestStreak(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get longest streak: %w", err)
+		}
+
+		streak, err = qtx.CreateStreak(ctx, gen.CreateStreakParams{
+			UserID:        userID,
+			StartDate:     today,
+			LongestStreak: max(longest, 1),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create streak: %w", err)
+		}
+	}
+
+	if _, err := qtx.UpdateUserStreak(ctx, gen.UpdateUserStreakParams{
+		Streak:         streak.CurrentStreak,
+		LastStreakDate: now,
+		ID:             userID,
+	}); err != nil {
+		return fmt.Errorf("failed to update user streak: %w", err)
+	}
+
+	return nil
+}
+
+// userLocalDate returns the calendar date of now in the user's preferred
+// timezone, as midnight UTC so it compares cleanly with DATE columns.
+func userLocalDate(ctx context.Context, qtx *gen.Queries, userID int32, now time.Time) (time.Time, error) {
+	loc := time.UTC
+
+	timezone, err := qtx.GetUserTimezone(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	if err == nil {
+		if l, err := time.LoadLocation(timezone); err == nil {
+			loc = l
+		}
+	}
+
+	y, m, d := now.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+}
+
+// lastStreakDay returns the last calendar day counted by the streak.
+func lastStreakDay(streak gen.Streak) time.Time {
+	y, m, d := streak.StartDate.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(streak.CurrentStreak)-1)
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	gen "algolearn/internal/database/generated"
+	httperr "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrParentDeleted is returned when restoring a unit or module whose course
+// or unit is still in the trash.
+var ErrParentDeleted = errors.New("parent is in the trash")
+
+// TrashService lists and restores deleted courses, units and modules, and
+// purges them once they have been in the trash for longer than the
+// retention period.
+type TrashService interface {
+	GetTrash(ctx context.Context) ([]models.TrashItem, error)
+	RestoreCourse(ctx context.Context, courseID int64) error
+	RestoreUnit(ctx context.Context, unitID int64) error
+	RestoreModule(ctx context.Context, moduleID int64) error
+	RunPurgeTrashJob(ctx context.Context, interval time.Duration)
+}
+
+type trashService struct {
+	queries   *gen.Queries
+	db        *sql.DB
+	storage   StorageService
+	retention time.Duration
+	log       *logger.Logger
+}
+
+func NewTrashService(db *sql.DB, storage StorageService, retention time.Duration) TrashService {
+	return &trashService{
+		queries:   gen.New(db),
+		db:        db,
+		storage:   storage,
+		retention: retention,
+		log:       logger.Get(),
+	}
+}
+
+func (s *trashService) GetTrash(ctx context.Context) ([]models.TrashItem, error) {
+	log := s.log.WithBaseFields(logger.Service, "GetTrash")
+
+	rows, err := s.queries.GetTrash(ctx)
+	if err != nil {
+		log.WithError(err).Error("failed to get trash")
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	items := make([]models.TrashItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, models.TrashItem{
+			Type:      row.Kind,
+			ID:        int64(row.ID),
+			Name:      row.Name,
+			CourseID:  int64(row.CourseID),
+			UnitID:    int64(row.UnitID),
+			DeletedAt: row.DeletedAt,
+			PurgeAt:   row.DeletedAt.Add(s.retention),
+		})
+	}
+
+	return items, nil
+}
+
+// RestoreCourse takes the course out of the trash together with its
+// published versions and the units and modules deleted along with it.
+func (s *trashService) RestoreCourse(ctx context.Context, courseID int64) error {
+	log := s.log.WithBaseFields(logger.Service, "RestoreCourse")
+
+	course, err := s.queries.GetTrashedCourse(ctx, int32(courseID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get trashed course")
+		return fmt.Errorf("failed to get trashed course: %w", err)
+	}
+
+	if err := s.queries.RestoreCourse(ctx, gen.RestoreCourseParams{
+		DeletedAt: course.DeletedAt,
+		CourseID:  course.ID,
+	}); err != nil {
+		log.WithError(err).Error("failed to restore course")
+		return fmt.Errorf("failed to restore course: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreUnit takes the unit out of the trash with the modules deleted along
+// with it. It keeps its number unless another unit has taken it, in which
+// case it is added at the end of the course.
+func (s *trashService) RestoreUnit(ctx context.Context, unitID int64) error {
+	log := s.log.WithBaseFields(logger.Service, "RestoreUnit")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	unit, err := qtx.GetTrashedUnit(ctx, int32(unitID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get trashed unit")
+		return fmt.Errorf("failed to get trashed unit: %w", err)
+	}
+	if unit.CourseDeleted {
+		return fmt.Errorf("%w: restore course %d first", ErrParentDeleted, unit.CourseID)
+	}
+
+	if err := qtx.LockCourse(ctx, unit.CourseID); err != nil {
+		log.WithError(err).Error("failed to lock course")
+		return fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	if err := qtx.RestoreUnit(ctx, gen.RestoreUnitParams{
+		UnitID:    unit.ID,
+		DeletedAt: unit.DeletedAt,
+	}); err != nil {
+		log.WithError(err).Error("failed to restore unit")
+		return fmt.Errorf("failed to restore unit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreModule takes the module out of the trash. It keeps its number
+// unless another module has taken it, in which case it is added at the end
+// of the unit.
+func (s *trashService) RestoreModule(ctx context.Context, moduleID int64) error {
+	log := s.log.WithBaseFields(logger.Service, "RestoreModule")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("failed to begin transaction")
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	module, err := qtx.GetTrashedModule(ctx, int32(moduleID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return httperr.ErrNotFound
+		}
+		log.WithError(err).Error("failed to get trashed module")
+		return fmt.Errorf("failed to get trashed module: %w", err)
+	}
+	if module.UnitDeleted {
+		return fmt.Errorf("%w: restore unit %d first", ErrParentDeleted, module.UnitID)
+	}
+
+	if err := qtx.LockCourse(ctx, module.CourseID); err != nil {
+		log.WithError(err).Error("failed to lock course")
+		return fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	if err := qtx.RestoreModule(ctx, gen.RestoreModuleParams{
+		ModuleID:  module.ID,
+		DeletedAt: module.DeletedAt,
+	}); err != nil {
+		log.WithError(err).Error("failed to restore module")
+		return fmt.Errorf("failed to restore module: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("failed to commit transaction")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RunPurgeTrashJob purges what has been in the trash for longer than the
+// retention period on every tick of interval until ctx is cancelled.
+func (s *trashService) RunPurgeTrashJob(ctx context.Context, interval time.Duration) {
+	log := s.log.WithBaseFields(logger.Service, "RunPurgeTrashJob")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.purgeTrash(ctx); err != nil {
+				log.WithError(err).Error("failed to purge trash")
+			}
+		}
+	}
+}
+
+// purgeTrash deletes the courses, units and modules that were deleted before
+// the retention period, then removes their media folders from storage unless
+// a published version still uses them.
+func (s *trashService) purgeTrash(ctx context.Context) error {
+	log := s.log.WithBaseFields(logger.Service, "purgeTrash")
+
+	before := time.Now().Add(-s.retention)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	folders, err := qtx.GetExpiredTrashFolders(ctx, before)
+	if err != nil {
+		return fmt.Errorf("failed to get expired media folders: %w", err)
+	}
+
+	modules, err := qtx.PurgeTrashedModules(ctx, before)
+	if err != nil {
+		return fmt.Errorf("failed to purge modules: %w", err)
+	}
+	units, err := qtx.PurgeTrashedUnits(ctx, before)
+	if err != nil {
+		return fmt.Errorf("failed to purge units: %w", err)
+	}
+	courses, err := qtx.PurgeTrashedCourses(ctx, before)
+	if err != nil {
+		return fmt.Errorf("failed to purge courses: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if courses+units+modules > 0 {
+		log.Infof("purged %d courses, %d units and %d modules from the trash", courses, units, modules)
+	}
+
+	for _, folder := range folders {
+		inUse, err := s.queries.IsMediaFolderInUse(ctx, folder.FolderObjectKey.UUID)
+		if err != nil {
+			return fmt.Errorf("failed to check media folder: %w", err)
+		}
+		if inUse {
+			continue
+		}
+
+		if err := s.storage.DeleteFolder(ctx, folder.Resource, folder.FolderObjectKey.UUID.String()); err != nil {
+			log.WithError(err).Warnf("failed to delete media folder %s/%s", folder.Resource, folder.FolderObjectKey.UUID)
+		}
+	}
+
+	return nil
+}
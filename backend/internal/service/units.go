@@ -2,11 +2,14 @@ package service
 
 import (
 	gen "algolearn/internal/database/generated"
+	httperr "algolearn/internal/errors"
 	"algolearn/internal/models"
 	"algolearn/pkg/logger"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
 )
@@ -18,21 +21,34 @@ type UnitService interface {
 		name, description string,
 		folderObjectKey, imgKey uuid.NullUUID) (*models.Unit, error)
 	GetUnitByID(ctx context.Context, unitID int64) (*models.Unit, error)
+	GetCourseUnit(ctx context.Context, courseID, unitID int64) (*models.Unit, error)
 	GetUnitsByCourseID(ctx context.Context, courseID int64) ([]*models.Unit, error)
 	GetUnitsCount(ctx context.Context) (int64, error)
 	UpdateUnit(ctx context.Context, unitID int64, name, description string) (*models.Unit, error)
 	UpdateUnitNumber(ctx context.Context, unitID int64, unitNumber int16) (*models.Unit, error)
+	ReorderUnits(ctx context.Context, courseID int64, unitIDs []int64) ([]*models.Unit, error)
+	CloneUnit(ctx context.Context, unitID int64, name string) (*models.Unit, error)
 	DeleteUnit(ctx context.Context, unitID int64) error
 }
 
+// ErrInvalidOrder is returned when a reorder does not list each unit, module
+// or section being ordered exactly once.
+var ErrInvalidOrder = errors.New("invalid order")
+
 type unitService struct {
 	queries *gen.Queries
+	db      *sql.DB
+	storage StorageService
 	log     *logger.Logger
 }
 
-func NewUnitService(db *sql.DB) UnitService {
+// NewUnitService returns a UnitService that copies the media of cloned units
+// within storage.
+func NewUnitService(db *sql.DB, storage StorageService) UnitService {
 	return &unitService{
 		queries: gen.New(db),
+		db:      db,
+		storage: storage,
 		log:     logger.Get(),
 	}
 }
@@ -43,6 +59,24 @@ func (s *unitService) GetUnitByID(ctx context.Context, unitID int64) (*models.Un
 		return nil, err
 	}
 
+	return unitFromGen(unit), nil
+}
+
+// GetCourseUnit returns the unit if it belongs to courseID, and
+// sql.ErrNoRows otherwise.
+func (s *unitService) GetCourseUnit(ctx context.Context, courseID, unitID int64) (*models.Unit, error) {
+	unit, err := s.queries.GetCourseUnit(ctx, gen.GetCourseUnitParams{
+		UnitID:   int32(unitID),
+		CourseID: int32(courseID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return unitFromGen(unit), nil
+}
+
+func unitFromGen(unit gen.Unit) *models.Unit {
 	return &models.Unit{
 		BaseModel: models.BaseModel{
 			ID:        int64(unit.ID),
@@ -52,7 +86,7 @@ func (s *unitService) GetUnitByID(ctx context.Context, unitID int64) (*models.Un
 		UnitNumber:  int16(unit.UnitNumber),
 		Name:        unit.Name,
 		Description: unit.Description,
-	}, nil
+	}
 }
 
 func (s *unitService) CreateUnit(
@@ -92,16 +126,7 @@ func (s *unitService) GetUnitsByCourseID(ctx context.Context, courseID int64) ([
 
 	var unitsModels []*models.Unit
 	for _, unit := range units {
-		unitsModels = append(unitsModels, &models.Unit{
-			BaseModel: models.BaseModel{
-				ID:        int64(unit.ID),
-				CreatedAt: unit.CreatedAt,
-				UpdatedAt: unit.UpdatedAt,
-			},
-			UnitNumber:  int16(unit.UnitNumber),
-			Name:        unit.Name,
-			Description: unit.Description,
-		})
+		unitsModels = append(unitsModels, unitFromGen(unit))
 	}
 
 	return unitsModels, nil
@@ -138,6 +163,191 @@ func (s *unitService) UpdateUnitNumber(ctx context.Context, unitID int64, unitNu
 	return s.GetUnitByID(ctx, unitID)
 }
 
+// ReorderUnits numbers the units of a course from 1 in the order of unitIDs,
+// which has to list every unit of the course.
+func (s *unitService) ReorderUnits(ctx context.Context, courseID int64, unitIDs []int64) ([]*models.Unit, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	if err := qtx.LockCourse(ctx, int32(courseID)); err != nil {
+		return nil, fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	units, err := qtx.GetUnitsByCourseID(ctx, int32(courseID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get units: %w", err)
+	}
+
+	byID := make(map[int64]gen.Unit, len(units))
+	current := make([]int32, len(units))
+	var last int32
+	for i, unit := range units {
+		byID[int64(unit.ID)] = unit
+		current[i] = unit.ID
+		last = max(last, unit.UnitNumber)
+	}
+
+	if err := checkOrder("unit", "course", current, unitIDs); err != nil {
+		return nil, err
+	}
+
+	// Unit numbers are unique within a course and have to be positive, so the
+	// units are first moved past both their old and their new numbers.
+	if err := qtx.ShiftUnitNumbers(ctx, gen.ShiftUnitNumbersParams{
+		Offset:   last + int32(len(units)),
+		CourseID: int32(courseID),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to shift unit numbers: %w", err)
+	}
+
+	result := make([]*models.Unit, len(unitIDs))
+	for i, id := range unitIDs {
+		if err := qtx.UpdateUnitNumber(ctx, gen.UpdateUnitNumberParams{
+			UnitNumber: int32(i + 1),
+			UnitID:     int32(id),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update unit number: %w", err)
+		}
+
+		unit := byID[id]
+		result[i] = &models.Unit{
+			BaseModel: models.BaseModel{
+				ID:        int64(unit.ID),
+				CreatedAt: unit.CreatedAt,
+				UpdatedAt: unit.UpdatedAt,
+			},
+			UnitNumber:  int16(i + 1),
+			Name:        unit.Name,
+			Description: unit.Description,
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// CloneUnit copies a unit with its modules, sections and media to the end of
+// its course as a draft unit named name, or "<unit name> (copy)" if name is
+// empty. The copy gets new object keys and its media is copied in storage.
+func (s *unitService) CloneUnit(ctx context.Context, unitID int64, name string) (*models.Unit, error) {
+	log := s.log.WithBaseFields(logger.Service, "CloneUnit")
+
+	unit, err := s.queries.GetUnitByID(ctx, int32(unitID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, httperr.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get unit: %w", err)
+	}
+
+	clone := models.ArchiveUnit{
+		ID:              int64(unit.ID),
+		FolderObjectKey: unit.FolderObjectKey,
+		ImgKey:          unit.ImgKey,
+		MediaExt:        unit.MediaExt.String,
+		Name:            cloneName(name, unit.Name),
+		Description:     unit.Description,
+	}
+	if err := archiveUnitModules(ctx, s.queries, &clone); err != nil {
+		return nil, err
+	}
+
+	plan := newImportPlan(func(string) bool { return true })
+	unitMedia(clone, plan.addMedia)
+
+	// Media copied for a clone that is rolled back is deleted again.
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := plan.discard(ctx, s.storage); err != nil {
+			log.WithError(err).Error("failed to delete media of failed clone")
+		}
+	}()
+
+	// The media is copied before the transaction so that the course is not
+	// locked while storage is slow.
+	missing, err := plan.copyMedia(ctx, s.storage)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range missing {
+		log.WithField("key", key).Warn("media object missing from storage")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	// The copy is numbered after the last unit, which has to stay the last
+	// until it is inserted.
+	if err := qtx.LockCourse(ctx, unit.CourseID); err != nil {
+		return nil, fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	last, err := qtx.GetLastUnitNumber(ctx, unit.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last unit number: %w", err)
+	}
+	clone.UnitNumber = last + 1
+
+	cloneID, err := insertUnitTree(ctx, qtx, unit.CourseID, clone, plan, newArchiveIDMap())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	return s.GetUnitByID(ctx, int64(cloneID))
+}
+
+// checkOrder checks that ids lists each of the current children of a parent
+// exactly once.
+func checkOrder(child, parent string, current []int32, ids []int64) error {
+	listed := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(current, int32(id)) {
+			return fmt.Errorf("%w: %s %d is not in the %s", ErrInvalidOrder, child, id, parent)
+		}
+		if listed[id] {
+			return fmt.Errorf("%w: %s %d is listed twice", ErrInvalidOrder, child, id)
+		}
+		listed[id] = true
+	}
+
+	for _, id := range current {
+		if !listed[int64(id)] {
+			return fmt.Errorf("%w: %s %d is missing", ErrInvalidOrder, child, id)
+		}
+	}
+
+	return nil
+}
+
+// DeleteUnit moves the unit and its modules to the trash.
 func (s *unitService) DeleteUnit(ctx context.Context, unitID int64) error {
-	return s.queries.DeleteUnit(ctx, int32(unitID))
+	deleted, err := s.queries.SoftDeleteUnit(ctx, int32(unitID))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return httperr.ErrNotFound
+	}
+	return nil
 }
@@ -6,6 +6,7 @@ import (
 	codes "algolearn/internal/errors"
 	"algolearn/internal/models"
 	"algolearn/pkg/logger"
+	"algolearn/pkg/security"
 	"context"
 	"database/sql"
 	"errors"
@@ -20,7 +21,7 @@ type UserService interface {
 	GetUsersCount(ctx context.Context) (int64, error)
 	GetReceivedAchievementsCount(ctx context.Context) (int64, error)
 	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
-	UpdateUser(ctx context.Context, user *models.User) error
+	UpdateUser(ctx context.Context, user *models.User) (emailChanged bool, err error)
 	GetUserByID(ctx context.Context, id int32) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
@@ -328,9 +329,21 @@ func (r *userService) CheckEmailExists(ctx context.Context, email string) (bool,
 	return true, nil
 }
 
-func (r *userService) UpdateUser(ctx context.Context, user *models.User) error {
+// UpdateUser saves the profile fields set on user. Changing the email clears
+// its verification and revokes the verification links sent for the old one;
+// the returned flag tells the caller to send a new link.
+func (r *userService) UpdateUser(ctx context.Context, user *models.User) (bool, error) {
 	log := r.log.WithBaseFields(logger.Service, "UpdateUser")
 
+	current, err := r.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("user not found")
+		}
+		log.WithError(err).Error("failed to get user")
+		return false, fmt.Errorf("could not get user: %v", err)
+	}
+
 	params := gen.UpdateUserParams{
 		ID:              user.ID,
 		Username:        user.Username,
@@ -352,15 +365,27 @@ func (r *userService) UpdateUser(ctx context.Context, user *models.User) error {
 		params.ImgKey = user.ImgKey
 	}
 
-	_, err := r.db.UpdateUser(ctx, params)
+	updated, err := r.db.UpdateUser(ctx, params)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("user not found")
+			return false, fmt.Errorf("user not found")
 		}
 		log.WithError(err).Error("failed to update user")
-		return fmt.Errorf("could not update user: %v", err)
+		return false, fmt.Errorf("could not update user: %v", err)
 	}
-	return nil
+
+	if updated.Email == current.Email {
+		return false, nil
+	}
+
+	if err := r.db.InvalidateUserActionTokens(ctx, gen.InvalidateUserActionTokensParams{
+		UserID:  user.ID,
+		Purpose: security.PurposeVerifyEmail,
+	}); err != nil {
+		log.WithError(err).Error("failed to revoke email verification tokens")
+		return true, fmt.Errorf("could not revoke email verification tokens: %v", err)
+	}
+	return true, nil
 }
 
 func (r *userService) DeleteUser(ctx context.Context, id int32) error {
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Declarative award criteria evaluated whenever learning progress is saved
+ALTER TABLE achievements
+ADD COLUMN criteria VARCHAR(50),
+ADD COLUMN threshold INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE achievements
+ADD CONSTRAINT check_achievement_criteria CHECK (
+    criteria IS NULL
+    OR criteria IN (
+        'modules_completed',
+        'courses_completed',
+        'streak_days',
+        'perfect_quiz'
+    )
+);
+
+ALTER TABLE achievements
+ADD CONSTRAINT check_achievement_threshold CHECK (threshold > 0);
+
+CREATE INDEX idx_user_achievements_user_id ON user_achievements (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_achievements_user_id;
+
+ALTER TABLE achievements DROP CONSTRAINT IF EXISTS check_achievement_threshold;
+
+ALTER TABLE achievements DROP CONSTRAINT IF EXISTS check_achievement_criteria;
+
+ALTER TABLE achievements DROP COLUMN IF EXISTS threshold;
+
+ALTER TABLE achievements DROP COLUMN IF EXISTS criteria;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Inbox listing pages backwards through a user's notifications by id
+CREATE INDEX idx_notifications_user_id_id ON notifications (user_id, id DESC);
+
+CREATE INDEX idx_notifications_user_id_unread ON notifications (user_id)
+WHERE read = FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_notifications_user_id_unread;
+
+DROP INDEX IF EXISTS idx_notifications_user_id_id;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Publishes new notifications so every server instance can push them to
+-- connected clients. Only ids are sent to stay well under the NOTIFY payload
+-- limit; listeners load the row themselves.
+CREATE OR REPLACE FUNCTION notify_notification_created()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify(
+        'notifications',
+        json_build_object('id', NEW.id, 'user_id', NEW.user_id)::text
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notification_created
+AFTER INSERT ON notifications
+FOR EACH ROW
+EXECUTE FUNCTION notify_notification_created();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS notification_created ON notifications;
+
+DROP FUNCTION IF EXISTS notify_notification_created();
+-- +goose StatementEnd
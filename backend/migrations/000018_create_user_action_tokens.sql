@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tracks the signed tokens mailed for email verification and password resets
+-- so each can be redeemed only once.
+CREATE TABLE user_action_tokens (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    user_id INTEGER NOT NULL,
+    purpose VARCHAR(50) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_action_tokens_user_purpose ON user_action_tokens (user_id, purpose);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_action_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pending OAuth logins. The id is the jti of the signed state sent to the
+-- provider, and the PKCE verifier never leaves the server.
+CREATE TABLE oauth_states (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    provider VARCHAR(20) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    client_state TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_oauth_states_expires_at ON oauth_states (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oauth_states;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- External OAuth identities linked to an account. A user has at most one
+-- identity per provider, and an identity belongs to a single user.
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    user_id INTEGER NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT uniq_identity_provider_subject UNIQUE (provider, subject),
+    CONSTRAINT uniq_identity_user_provider UNIQUE (user_id, provider)
+);
+
+-- Set when an OAuth flow was started to link an identity to a signed-in
+-- user rather than to sign in.
+ALTER TABLE oauth_states
+ADD COLUMN link_user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_states DROP COLUMN IF EXISTS link_user_id;
+
+DROP TABLE IF EXISTS user_identities;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Failed sign-in attempts, tracked per account (scope 'account', keyed by
+-- email so unknown emails are throttled too) and per client IP (scope 'ip').
+CREATE TABLE login_attempts (
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('account', 'ip')),
+    key VARCHAR(255) NOT NULL,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_login_attempts_last_failed_at ON login_attempts (last_failed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE question_type AS ENUM (
+    'multiple_choice',
+    'multi_select',
+    'true_false',
+    'fill_blank',
+    'ordering',
+    'numeric'
+);
+
+-- Anything that is not a known type was graded as a single option before.
+ALTER TABLE questions
+ALTER COLUMN type TYPE question_type USING (
+    CASE
+        WHEN type IN ('multiple_choice', 'multi_select', 'true_false', 'fill_blank', 'ordering', 'numeric') THEN type
+        ELSE 'multiple_choice'
+    END
+)::question_type;
+
+-- Grading data that does not fit in question_options: the accepted answers
+-- of fill_blank questions and the value and tolerance of numeric ones.
+ALTER TABLE questions ADD COLUMN answer_schema JSONB NOT NULL DEFAULT '{}';
+
+-- The learner's response in the shape its question type expects. option_id
+-- is kept for single option answers only.
+ALTER TABLE user_question_answers
+ALTER COLUMN option_id DROP NOT NULL,
+ADD COLUMN answer JSONB NOT NULL DEFAULT '{}';
+
+UPDATE user_question_answers SET answer = jsonb_build_object('optionId', option_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM user_question_answers WHERE option_id IS NULL;
+
+ALTER TABLE user_question_answers
+DROP COLUMN IF EXISTS answer,
+ALTER COLUMN option_id SET NOT NULL;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS answer_schema;
+
+ALTER TABLE questions ALTER COLUMN type TYPE VARCHAR(50) USING type::text;
+
+DROP TYPE IF EXISTS question_type;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Shown to learners only once they have answered the question.
+ALTER TABLE questions ADD COLUMN explanation TEXT NOT NULL DEFAULT '';
+ALTER TABLE question_options ADD COLUMN feedback TEXT NOT NULL DEFAULT '';
+
+-- The correct place of an option in an ordering question. Options used to be
+-- graded in id order, which learners can see, so ordering options are now
+-- inserted shuffled and graded by position instead.
+ALTER TABLE question_options ADD COLUMN position SMALLINT NOT NULL DEFAULT 0;
+
+UPDATE question_options qo
+SET position = ranked.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY question_id ORDER BY id) - 1 AS position
+    FROM question_options
+) ranked
+WHERE ranked.id = qo.id;
+
+ALTER TABLE user_question_answers ADD COLUMN attempts INTEGER NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_question_answers DROP COLUMN IF EXISTS attempts;
+ALTER TABLE question_options DROP COLUMN IF EXISTS position;
+ALTER TABLE question_options DROP COLUMN IF EXISTS feedback;
+ALTER TABLE questions DROP COLUMN IF EXISTS explanation;
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE section_type ADD VALUE IF NOT EXISTS 'exercise';
+
+-- A coding exercise: learners start from starter_code and their submissions
+-- are run against the test cases within the time and memory limits.
+CREATE TABLE exercise_sections (
+    section_id INTEGER PRIMARY KEY REFERENCES sections (id) ON DELETE CASCADE,
+    prompt TEXT NOT NULL,
+    language VARCHAR(50) NOT NULL,
+    starter_code TEXT NOT NULL DEFAULT '',
+    time_limit_ms INTEGER NOT NULL DEFAULT 2000,
+    memory_limit_kb INTEGER NOT NULL DEFAULT 262144,
+    object_key UUID,
+    media_ext VARCHAR(10)
+);
+
+-- Test cases feed input on stdin and compare stdout. Hidden ones are never
+-- shown to learners, not even in submission results.
+CREATE TABLE exercise_test_cases (
+    id SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL REFERENCES exercise_sections (section_id) ON DELETE CASCADE,
+    position SMALLINT NOT NULL,
+    input TEXT NOT NULL DEFAULT '',
+    expected_output TEXT NOT NULL,
+    hidden BOOLEAN NOT NULL DEFAULT TRUE,
+    UNIQUE (section_id, position)
+);
+
+CREATE TABLE exercise_submissions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    section_id INTEGER NOT NULL REFERENCES exercise_sections (section_id) ON DELETE CASCADE,
+    code TEXT NOT NULL,
+    passed BOOLEAN NOT NULL,
+    passed_count INTEGER NOT NULL,
+    total_count INTEGER NOT NULL
+);
+
+CREATE INDEX idx_exercise_submissions_user_section ON exercise_submissions (user_id, section_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS exercise_submissions;
+DROP TABLE IF EXISTS exercise_test_cases;
+DROP TABLE IF EXISTS exercise_sections;
+
+-- Enum values cannot be dropped, so 'exercise' stays in section_type; only
+-- the sections using it are removed.
+DELETE FROM sections WHERE type = 'exercise';
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A user's spaced repetition card for a question they got wrong, scheduled
+-- with SM-2. interval_days is the gap before the next review and due_at when
+-- it falls; lapses counts the times the question was answered wrong.
+CREATE TABLE question_reviews (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    lapses INTEGER NOT NULL DEFAULT 0,
+    due_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, question_id)
+);
+
+CREATE INDEX idx_question_reviews_user_due ON question_reviews(user_id, due_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS question_reviews;
+-- +goose StatementEnd
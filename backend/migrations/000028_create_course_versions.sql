@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A published version of a course. Publishing copies the course's units,
+-- modules and sections into a new course row, snapshot_course_id, which is
+-- never edited; course_id stays the working copy authors edit. Learner
+-- progress is recorded against the snapshot they are enrolled in.
+CREATE TABLE course_versions (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    snapshot_course_id INTEGER NOT NULL UNIQUE REFERENCES courses(id) ON DELETE CASCADE,
+    published_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (course_id, version)
+);
+
+-- Rows copied into a snapshot point back at the working copy rows they were
+-- copied from, which is how the same unit, module or section is matched
+-- across versions. They are not foreign keys since the working copy rows can
+-- be deleted after publishing.
+ALTER TABLE units ADD COLUMN source_unit_id INTEGER;
+ALTER TABLE modules ADD COLUMN source_module_id INTEGER;
+ALTER TABLE sections ADD COLUMN source_section_id INTEGER;
+
+-- Snapshots published before their media was copied share it with the
+-- working copy.
+ALTER TABLE courses DROP CONSTRAINT IF EXISTS courses_folder_object_key_key;
+ALTER TABLE courses DROP CONSTRAINT IF EXISTS courses_img_key_key;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM courses WHERE id IN (SELECT snapshot_course_id FROM course_versions);
+
+ALTER TABLE courses ADD CONSTRAINT courses_img_key_key UNIQUE (img_key);
+ALTER TABLE courses ADD CONSTRAINT courses_folder_object_key_key UNIQUE (folder_object_key);
+
+ALTER TABLE sections DROP COLUMN IF EXISTS source_section_id;
+ALTER TABLE modules DROP COLUMN IF EXISTS source_module_id;
+ALTER TABLE units DROP COLUMN IF EXISTS source_unit_id;
+
+DROP TABLE IF EXISTS course_versions;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Deleted courses, units and modules stay in the trash until they are
+-- purged, so learner progress through them can be restored with them.
+-- Deleting a course or unit stamps everything under it with the same
+-- deleted_at, which is how restoring it tells what went with it from what
+-- was deleted before.
+ALTER TABLE courses ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE units ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE modules ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Numbers only have to be unique among the items that are not deleted.
+ALTER TABLE units DROP CONSTRAINT unique_unit_number_per_course;
+CREATE UNIQUE INDEX unique_unit_number_per_course ON units (course_id, unit_number)
+WHERE deleted_at IS NULL;
+
+ALTER TABLE modules DROP CONSTRAINT unique_module_number_per_unit;
+CREATE UNIQUE INDEX unique_module_number_per_unit ON modules (unit_id, module_number)
+WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_courses_deleted_at ON courses (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_units_deleted_at ON units (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_modules_deleted_at ON modules (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM modules WHERE deleted_at IS NOT NULL;
+DELETE FROM units WHERE deleted_at IS NOT NULL;
+DELETE FROM courses WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_modules_deleted_at;
+DROP INDEX IF EXISTS idx_units_deleted_at;
+DROP INDEX IF EXISTS idx_courses_deleted_at;
+
+DROP INDEX IF EXISTS unique_module_number_per_unit;
+ALTER TABLE modules ADD CONSTRAINT unique_module_number_per_unit UNIQUE (unit_id, module_number);
+
+DROP INDEX IF EXISTS unique_unit_number_per_course;
+ALTER TABLE units ADD CONSTRAINT unique_unit_number_per_course UNIQUE (course_id, unit_number);
+
+ALTER TABLE modules DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE units DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE courses DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Close all but the latest open streak of each user, so concurrent first
+-- activities can no longer open two.
+UPDATE streaks s
+SET
+    end_date = s.start_date + (GREATEST(s.current_streak, 1) - 1),
+    updated_at = NOW()
+WHERE s.end_date IS NULL
+    AND EXISTS (
+        SELECT 1
+        FROM streaks o
+        WHERE o.user_id = s.user_id
+            AND o.end_date IS NULL
+            AND (o.start_date, o.id) > (s.start_date, s.id)
+    );
+
+CREATE UNIQUE INDEX uniq_open_streak_per_user ON streaks (user_id) WHERE end_date IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS uniq_open_streak_per_user;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Accounts created by OAuth sign-in before identities were linked only kept
+-- the provider's subject in users.oauth_id. Google subjects are numeric and
+-- Apple ones are not, which tells the providers apart.
+INSERT INTO user_identities (user_id, provider, subject, email)
+SELECT
+    id,
+    CASE WHEN oauth_id ~ '^[0-9]+$' THEN 'google' ELSE 'apple' END,
+    oauth_id,
+    email
+FROM users
+WHERE oauth_id IS NOT NULL
+    AND oauth_id <> ''
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- Backfilled identities cannot be told from linked ones, so they are kept.
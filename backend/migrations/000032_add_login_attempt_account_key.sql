@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The account the latest failure counted against an IP was for, so unlocking
+-- an account also lifts the lockout its owner ran into from their own IP.
+ALTER TABLE login_attempts ADD COLUMN account_key VARCHAR(255);
+
+CREATE INDEX idx_login_attempts_account_key ON login_attempts (account_key)
+WHERE scope = 'ip';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_login_attempts_account_key;
+
+ALTER TABLE login_attempts DROP COLUMN IF EXISTS account_key;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Email verification links are issued for an address and only verify the
+-- account while it still has that address. Links mailed before they were
+-- bound to one would verify whatever address the account has when they are
+-- opened, so they are invalidated.
+ALTER TABLE user_action_tokens ADD COLUMN email VARCHAR(255);
+
+UPDATE user_action_tokens
+SET used_at = NOW()
+WHERE purpose = 'verify_email'
+    AND used_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_action_tokens DROP COLUMN IF EXISTS email;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The address is_email_verified was recorded for. Verifications from before
+-- it existed may have been for an earlier address, so it starts out empty.
+ALTER TABLE users ADD COLUMN verified_email VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS verified_email;
+-- +goose StatementEnd
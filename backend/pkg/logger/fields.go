@@ -8,4 +8,5 @@ const (
 	Handler    LogPackage = "handler"
 	Middleware LogPackage = "middleware"
 	Security   LogPackage = "security"
+	Mailer     LogPackage = "mailer"
 )
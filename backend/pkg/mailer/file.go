@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"algolearn/pkg/logger"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fileMailer struct {
+	dir  string
+	from string
+	log  *logger.Logger
+}
+
+// NewFileMailer is meant for local development. It writes each message to an
+// .eml file in dir, or only logs it when dir is empty.
+func NewFileMailer(dir, from string) Mailer {
+	return &fileMailer{dir: dir, from: from, log: logger.Get()}
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	log := m.log.WithBaseFields(logger.Mailer, "Send")
+
+	if m.dir == "" {
+		log.Infof("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create outbox %s: %w", m.dir, err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, formatMessage(m.from, msg), 0o600); err != nil {
+		return fmt.Errorf("failed to write mail to %s: %w", path, err)
+	}
+
+	log.Infof("wrote mail to %s to %s", msg.To, path)
+	return nil
+}
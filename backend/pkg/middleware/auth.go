@@ -15,6 +15,11 @@ import (
 const (
 	// UserIDKey is used to store the user ID in the context
 	UserIDKey = "userID"
+	// RoleKey is used to store the user role in the context
+	RoleKey = "userRole"
+	// MFAKey is used to store whether the session signed in with a second
+	// factor in the context
+	MFAKey = "userMFA"
 	// BearerSchema is the prefix for the Authorization header
 	BearerSchema = "Bearer "
 )
@@ -81,6 +86,8 @@ func Auth() gin.HandlerFunc {
 		}
 
 		c.Set(UserIDKey, claims.UserID)
+		c.Set(RoleKey, claims.Role)
+		c.Set(MFAKey, claims.MFA)
 		c.Next()
 	}
 }
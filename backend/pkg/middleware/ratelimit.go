@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	codes "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy is a token bucket that holds up to Limit requests and
+// refills completely over Window. Name keeps the buckets of different
+// policies apart. FailClosed rejects requests while the store is failing
+// instead of letting them through.
+type RateLimitPolicy struct {
+	Name       string
+	Limit      int
+	Window     time.Duration
+	FailClosed bool
+}
+
+var (
+	// AuthRateLimit guards the unauthenticated account endpoints, per IP.
+	AuthRateLimit = RateLimitPolicy{Name: "auth", Limit: 20, Window: time.Minute, FailClosed: true}
+	// SearchRateLimit guards the search endpoints.
+	SearchRateLimit = RateLimitPolicy{Name: "search", Limit: 30, Window: time.Minute}
+	// UploadRateLimit guards the storage endpoints.
+	UploadRateLimit = RateLimitPolicy{Name: "upload", Limit: 20, Window: time.Minute}
+	// ExerciseRateLimit guards exercise submissions, which run code.
+	ExerciseRateLimit = RateLimitPolicy{Name: "exercise", Limit: 10, Window: time.Minute, FailClosed: true}
+)
+
+// refillRate is how many tokens the policy's bucket regains per second.
+func (p RateLimitPolicy) refillRate() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket. Tokens is
+// what is left in the bucket afterwards.
+type RateLimitResult struct {
+	Allowed bool
+	Tokens  float64
+}
+
+// RateLimitStore keeps token buckets. Take refills the bucket named key for
+// the time since it was last used and takes one token if a whole one is left.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+// RateLimiter limits requests with the token buckets kept in its store.
+type RateLimiter struct {
+	store RateLimitStore
+}
+
+func NewRateLimiter(store RateLimitStore) *RateLimiter {
+	return &RateLimiter{store: store}
+}
+
+// RateLimit limits requests per user when it runs after Auth, and per client
+// IP otherwise. Every response carries the RateLimit-* headers; rejected ones
+// also get Retry-After. If the store fails, requests are rejected when the
+// policy fails closed and let through otherwise.
+func (l *RateLimiter) RateLimit(policy RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := logger.Get().WithBaseFields(logger.Middleware, "RateLimit")
+
+		key := policy.Name + ":ip:" + c.ClientIP()
+		if userID, ok := c.Get(UserIDKey); ok {
+			key = fmt.Sprintf("%s:user:%v", policy.Name, userID)
+		}
+
+		result, err := l.store.Take(c.Request.Context(), key, policy)
+		if err != nil {
+			log.WithError(err).Errorf("failed to take rate limit token for %q", policy.Name)
+			if policy.FailClosed {
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.Response{
+					Success:   false,
+					ErrorCode: codes.ServiceUnavailable,
+					Message:   "service temporarily unavailable, try again later",
+				})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		rate := policy.refillRate()
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(int(result.Tokens)))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil((float64(policy.Limit)-result.Tokens)/rate))))
+
+		if !result.Allowed {
+			log.Debugf("rate limit %q exceeded by %s", policy.Name, key)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil((1-result.Tokens)/rate))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, models.Response{
+				Success:   false,
+				ErrorCode: codes.RateLimited,
+				Message:   "too many requests, try again later",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
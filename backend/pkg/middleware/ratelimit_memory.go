@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// memoryBucketSweepInterval is how often full buckets are dropped from a
+// MemoryRateLimitStore; a missing bucket behaves exactly like a full one.
+const memoryBucketSweepInterval = time.Minute
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// MemoryRateLimitStore keeps token buckets in process memory. Limits are
+// per instance, so it only suits a single node.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	nextSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*memoryBucket),
+		nextSweep: time.Now().Add(memoryBucketSweepInterval),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.After(s.nextSweep) {
+		for k, b := range s.buckets {
+			if now.After(b.fullAt) {
+				delete(s.buckets, k)
+			}
+		}
+		s.nextSweep = now.Add(memoryBucketSweepInterval)
+	}
+
+	rate := policy.refillRate()
+	limit := float64(policy.Limit)
+
+	tokens := limit
+	if b, ok := s.buckets[key]; ok {
+		tokens = math.Min(limit, b.tokens+now.Sub(b.updatedAt).Seconds()*rate)
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	s.buckets[key] = &memoryBucket{
+		tokens:    tokens,
+		updatedAt: now,
+		fullAt:    now.Add(time.Duration((limit - tokens) / rate * float64(time.Second))),
+	}
+
+	return RateLimitResult{Allowed: allowed, Tokens: tokens}, nil
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	codes "algolearn/internal/errors"
+	"algolearn/internal/models"
+	"algolearn/pkg/logger"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets through users whose token carries one of the given
+// roles. It must run after Auth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := logger.Get().WithBaseFields(logger.Middleware, "RequireRole")
+
+		role := c.GetString(RoleKey)
+		if !slices.Contains(roles, role) {
+			log.Warnf("Role %q is not allowed to access %s %s", role, c.Request.Method, c.FullPath())
+			c.Abort()
+			c.JSON(http.StatusForbidden, models.Response{
+				Success:   false,
+				ErrorCode: codes.Forbidden,
+				Message:   "insufficient permissions",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireMFA only lets through sessions that signed in with a second factor.
+// It guards the admin APIs, so a stolen admin password alone is not enough
+// to use them. It must run after Auth.
+func RequireMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := logger.Get().WithBaseFields(logger.Middleware, "RequireMFA")
+
+		if !c.GetBool(MFAKey) {
+			log.Warnf("Session without MFA tried to access %s %s", c.Request.Method, c.FullPath())
+			c.Abort()
+			c.JSON(http.StatusForbidden, models.Response{
+				Success:   false,
+				ErrorCode: codes.Forbidden,
+				Message:   "sign in with multi-factor authentication to use this",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -8,8 +8,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func Timeout(timeout time.Duration) gin.HandlerFunc {
+// Timeout aborts requests that run longer than timeout, except on the
+// exempt routes, which are matched against the route pattern (c.FullPath())
+// so clients cannot opt out of it.
+func Timeout(timeout time.Duration, exemptRoutes ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(exemptRoutes))
+	for _, route := range exemptRoutes {
+		exempt[route] = true
+	}
+
 	return func(c *gin.Context) {
+		if exempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 
@@ -0,0 +1,205 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxOutputBytes is how much of each output stream is kept.
+const maxOutputBytes = 64 << 10
+
+// maxProcesses caps the processes and threads a program may start. Linux
+// counts them across the user the server runs as, the server's own threads
+// included, so it is only a guard against fork bombs.
+const maxProcesses = 256
+
+// localLanguage says how the local runner runs a language: the file the code
+// is written to and the shell command that runs it under a memory limit.
+type localLanguage struct {
+	file    string
+	command func(memoryLimitKB int) string
+}
+
+var localLanguages = map[string]localLanguage{
+	"python": {
+		file: "main.py",
+		command: func(memoryLimitKB int) string {
+			return fmt.Sprintf("ulimit -v %d; exec python3 main.py", memoryLimitKB)
+		},
+	},
+	// V8 reserves far more address space than it uses, so node is limited
+	// by heap size instead of ulimit.
+	"javascript": {
+		file: "main.js",
+		command: func(memoryLimitKB int) string {
+			return fmt.Sprintf("exec node --max-old-space-size=%d main.js", max(memoryLimitKB/1024, 16))
+		},
+	},
+}
+
+// LocalRunner runs code as a child process of the server, in a temporary
+// directory, with an empty environment and in its own process group, which
+// is killed once the program is done. CPU time and process count are
+// limited, but the file system and network are not isolated and the code
+// runs as the server's user, so it is meant for development only.
+type LocalRunner struct{}
+
+func NewLocalRunner() *LocalRunner {
+	return &LocalRunner{}
+}
+
+func (r *LocalRunner) Run(ctx context.Context, req Request) (Result, error) {
+	lang, ok := localLanguages[req.Language]
+	if !ok {
+		return Result{}, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, req.Language)
+	}
+
+	dir, err := os.MkdirTemp("", "algolearn-run-*")
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, lang.file), []byte(req.Code), 0o600); err != nil {
+		return Result{}, fmt.Errorf("failed to write code: %w", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, req.TimeLimit)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxOutputBytes}
+	stderr := &limitedBuffer{limit: maxOutputBytes}
+
+	// The shell waits for a line on fd 3 before running the program, so its
+	// limits are in place before any of the learner's code runs.
+	gate, release, err := os.Pipe()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create start pipe: %w", err)
+	}
+	defer gate.Close()
+	defer release.Close()
+
+	cmd := exec.CommandContext(runCtx, "sh", "-c", "read start <&3; exec 3<&-; "+lang.command(req.MemoryLimitKB))
+	cmd.ExtraFiles = []*os.File{gate}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	// Kill the whole group so children the program started die with it.
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.Dir = dir
+	// The server's environment holds credentials, so none of it is passed on.
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "HOME=" + dir}
+	cmd.Stdin = strings.NewReader(req.Stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Stop waiting for output if the program left children holding its pipes.
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return Result{}, fmt.Errorf("failed to start code: %w", err)
+	}
+	gate.Close()
+
+	if err := limitProcess(cmd.Process.Pid, req.TimeLimit); err != nil {
+		cmd.Cancel()
+		cmd.Wait()
+		return Result{}, err
+	}
+	if _, err := release.Write([]byte("\n")); err != nil {
+		cmd.Cancel()
+		cmd.Wait()
+		return Result{}, fmt.Errorf("failed to start code: %w", err)
+	}
+	release.Close()
+
+	err = cmd.Wait()
+	// Background processes the program left behind are killed as well. If
+	// they held its output open, Wait gave up on them after WaitDelay and
+	// the program's own exit status still stands.
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	if errors.Is(err, exec.ErrWaitDelay) {
+		err = nil
+	}
+
+	result := Result{
+		Status:   StatusOK,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+	}
+
+	if ctx.Err() != nil {
+		return Result{}, ctx.Err()
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		result.Status = StatusTimeLimitExceeded
+		return result, nil
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitedOnSignal(exitErr, syscall.SIGXCPU):
+		result.Status = StatusTimeLimitExceeded
+	case errors.As(err, &exitErr):
+		result.Status = StatusRuntimeError
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		return Result{}, fmt.Errorf("failed to run code: %w", err)
+	}
+
+	return result, nil
+}
+
+// limitProcess caps the CPU time and the number of processes of pid, and of
+// every process it starts. The CPU limit is the time limit rounded up to
+// whole seconds.
+func limitProcess(pid int, timeLimit time.Duration) error {
+	cpuSeconds := max(uint64((timeLimit+time.Second-1)/time.Second), 1)
+	cpu := unix.Rlimit{Cur: cpuSeconds, Max: cpuSeconds + 1}
+	if err := unix.Prlimit(pid, unix.RLIMIT_CPU, &cpu, nil); err != nil {
+		return fmt.Errorf("failed to limit CPU time: %w", err)
+	}
+	nproc := unix.Rlimit{Cur: maxProcesses, Max: maxProcesses}
+	if err := unix.Prlimit(pid, unix.RLIMIT_NPROC, &nproc, nil); err != nil {
+		return fmt.Errorf("failed to limit processes: %w", err)
+	}
+	return nil
+}
+
+// exitedOnSignal reports whether the process was killed by sig.
+func exitedOnSignal(exitErr *exec.ExitError, sig syscall.Signal) bool {
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == sig
+}
+
+// limitedBuffer keeps the first limit bytes written to it and drops the rest
+// without failing the writer.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
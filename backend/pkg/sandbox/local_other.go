@@ -0,0 +1,20 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+)
+
+// LocalRunner needs Linux to limit and kill the processes it starts, so
+// elsewhere it refuses to run anything.
+type LocalRunner struct{}
+
+func NewLocalRunner() *LocalRunner {
+	return &LocalRunner{}
+}
+
+func (r *LocalRunner) Run(ctx context.Context, req Request) (Result, error) {
+	return Result{}, errors.New("the local code runner is only supported on linux")
+}
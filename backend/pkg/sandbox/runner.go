@@ -0,0 +1,50 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnsupportedLanguage is returned by a Runner asked to run a language it
+// has no toolchain for.
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// Request is a single run of a program against one input.
+type Request struct {
+	Language string
+	Code     string
+	Stdin    string
+	// TimeLimit is the wall clock time the program may run for.
+	TimeLimit time.Duration
+	// MemoryLimitKB caps the memory the program may use.
+	MemoryLimitKB int
+}
+
+type Status string
+
+const (
+	// StatusOK means the program exited with status 0.
+	StatusOK Status = "ok"
+	// StatusRuntimeError means the program failed to compile, crashed or
+	// exited with a non-zero status, which includes running out of memory.
+	StatusRuntimeError Status = "runtime_error"
+	// StatusTimeLimitExceeded means the program was killed at its time limit.
+	StatusTimeLimitExceeded Status = "time_limit_exceeded"
+)
+
+// Result is what a program did. Stdout and Stderr are truncated to a few
+// tens of kilobytes.
+type Result struct {
+	Status   Status
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// Runner runs untrusted code. Run returns an error only when the runner
+// itself fails; what the program does is reported in the Result.
+type Runner interface {
+	Run(ctx context.Context, req Request) (Result, error)
+}
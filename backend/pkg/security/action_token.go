@@ -0,0 +1,59 @@
+package security
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Action token purposes. They are used as the token audience so one kind
+// cannot be redeemed as another.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+	PurposeOAuthState    = "oauth_state"
+	PurposeMFAChallenge  = "mfa_challenge"
+)
+
+var ErrInvalidActionToken = errors.New("invalid or expired token")
+
+// GenerateActionToken signs a token that lets its holder perform purpose on
+// behalf of userID until expiry. jti identifies the token so the caller can
+// make it single-use.
+func GenerateActionToken(userID int32, purpose, jti string, expiry time.Duration) (string, error) {
+	if keySet == nil {
+		return "", errKeySetNotLoaded
+	}
+
+	now := time.Now()
+	return keySet.sign(&Claims{
+		UserID: userID,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  purpose,
+			Id:        jti,
+			ExpiresAt: now.Add(expiry).Unix(),
+			IssuedAt:  now.Unix(),
+		},
+	})
+}
+
+// ValidateActionToken checks the signature, expiry and purpose of an action
+// token. Single use is enforced by the caller through the returned jti.
+func ValidateActionToken(tokenString, purpose string) (*Claims, error) {
+	if keySet == nil {
+		return nil, errKeySetNotLoaded
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keySet.keyFunc)
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidActionToken
+	}
+
+	if claims.Audience != purpose || claims.Id == "" {
+		return nil, ErrInvalidActionToken
+	}
+
+	return claims, nil
+}
@@ -1,74 +1,59 @@
 package security
 
 import (
-	"algolearn/internal/config"
 	"algolearn/pkg/logger"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 )
 
-const (
-	accessTokenExpiry  = time.Hour * 1      // 1 hour
-	refreshTokenExpiry = time.Hour * 24 * 7 // 7 days
-)
+const accessTokenExpiry = time.Hour * 1 // 1 hour
 
 var ErrTokenExpired = errors.New("token is expired")
 
-func GetJWTKey() []byte {
-	cfg, err := config.Load()
-	if err != nil {
-		return nil
-	}
-	return []byte(cfg.Auth.JWTSecretKey)
-}
-
 type Claims struct {
-	UserID int32 `json:"user_id"`
+	UserID int32  `json:"user_id"`
+	Role   string `json:"role,omitempty"`
+	// MFA is set when the session was signed in with a second factor
+	MFA bool `json:"mfa,omitempty"`
 	jwt.StandardClaims
 }
 
-func GenerateJWT(userID int32) (string, error) {
-	return generateToken(userID, accessTokenExpiry)
-}
-
-func GenerateRefreshToken(userID int32) (string, error) {
-	return generateToken(userID, refreshTokenExpiry)
+func GenerateJWT(userID int32, role string, mfa bool) (string, error) {
+	return generateToken(userID, role, mfa, accessTokenExpiry)
 }
 
-func generateToken(userID int32, expiry time.Duration) (string, error) {
+func generateToken(userID int32, role string, mfa bool, expiry time.Duration) (string, error) {
 	claims := &Claims{
 		UserID: userID,
+		Role:   role,
+		MFA:    mfa,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(expiry).Unix(),
 			IssuedAt:  time.Now().Unix(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(GetJWTKey()))
+	if keySet == nil {
+		return "", errKeySetNotLoaded
+	}
+	return keySet.sign(claims)
 }
 
 func ValidateJWT(tokenString string) (*Claims, error) {
 	return validateToken(tokenString, accessTokenExpiry)
 }
 
-func ValidateRefreshToken(tokenString string) (*Claims, error) {
-	return validateToken(tokenString, refreshTokenExpiry)
-}
-
 func validateToken(tokenString string, maxExpiry time.Duration) (*Claims, error) {
 	log := logger.Get().WithBaseFields(logger.Security, "ValidateJWT")
 
+	if keySet == nil {
+		return nil, errKeySetNotLoaded
+	}
+
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(GetJWTKey()), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, keySet.keyFunc)
 
 	if err != nil {
 		if ve, ok := err.(*jwt.ValidationError); ok {
@@ -86,6 +71,12 @@ func validateToken(tokenString string, maxExpiry time.Duration) (*Claims, error)
 		return nil, errors.New("invalid token")
 	}
 
+	// action tokens carry an audience and must not authenticate requests
+	if claims.Audience != "" {
+		log.Error("Token is not an access token")
+		return nil, errors.New("invalid token")
+	}
+
 	if time.Unix(claims.IssuedAt, 0).Add(maxExpiry).Before(time.Now()) {
 		log.Error("Token has exceeded maximum lifetime")
 		return nil, errors.New("token has exceeded maximum lifetime")
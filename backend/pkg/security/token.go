@@ -0,0 +1,28 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+const RefreshTokenExpiry = time.Hour * 24 * 7 // 7 days
+
+// GenerateOpaqueToken returns a random URL-safe token carrying 256 bits of
+// entropy. Only its HashToken digest should be stored.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex-encoded SHA-256 digest of an opaque token. Tokens
+// are high-entropy, so a fast unsalted hash is enough to look them up.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}